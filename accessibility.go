@@ -0,0 +1,124 @@
+package chrome
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/accessibility"
+	"log"
+	"time"
+)
+
+// AXNode is a node of the accessibility tree as exposed to assistive technologies
+type AXNode struct {
+	Role        string
+	Name        string
+	Description string
+	Value       string
+	// Properties hold the states and other properties of the node, e.g. focusable, checked, expanded, level
+	Properties map[string]interface{}
+	Ignored    bool
+	Children   []*AXNode
+}
+
+func (t *tab) AccessibilitySnapshot(opts AccessibilitySnapshotOpts, timeout time.Duration) (*AXNode, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return nil, err
+	}
+
+	var tree *accessibility.GetFullAXTreeReply
+	err := t.call(ctx, func(client *cdp.Client) (err error) {
+		tree, err = client.Accessibility.GetFullAXTree(ctx, accessibility.NewGetFullAXTreeArgs())
+		return err
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to get accessibility tree", err.Error())
+		return nil, err
+	}
+
+	if len(tree.Nodes) == 0 {
+		return nil, nil
+	}
+
+	nodes := make(map[accessibility.AXNodeID]accessibility.AXNode, len(tree.Nodes))
+	for _, node := range tree.Nodes {
+		nodes[node.NodeID] = node
+	}
+
+	// the first node is the root of the tree, which is never ignored
+	root := newAXNode(tree.Nodes[0])
+	root.Children = axChildren(nodes, tree.Nodes[0], opts)
+
+	return root, nil
+}
+
+// axChildren converts the children of the node, replacing ignored children with their own children unless ignored
+// nodes are included
+func axChildren(nodes map[accessibility.AXNodeID]accessibility.AXNode, node accessibility.AXNode, opts AccessibilitySnapshotOpts) []*AXNode {
+	var children []*AXNode
+	for _, id := range node.ChildIDs {
+		child, ok := nodes[id]
+		if !ok {
+			continue
+		}
+
+		if child.Ignored && !opts.IncludeIgnored {
+			children = append(children, axChildren(nodes, child, opts)...)
+			continue
+		}
+
+		converted := newAXNode(child)
+		converted.Children = axChildren(nodes, child, opts)
+		children = append(children, converted)
+	}
+
+	return children
+}
+
+func newAXNode(node accessibility.AXNode) *AXNode {
+	converted := &AXNode{
+		Role:        axValueString(node.Role),
+		Name:        axValueString(node.Name),
+		Description: axValueString(node.Description),
+		Value:       axValueString(node.Value),
+		Ignored:     node.Ignored,
+	}
+
+	if len(node.Properties) > 0 {
+		converted.Properties = make(map[string]interface{}, len(node.Properties))
+		for _, property := range node.Properties {
+			converted.Properties[string(property.Name)] = axValueInterface(&property.Value)
+		}
+	}
+
+	return converted
+}
+
+func axValueInterface(value *accessibility.AXValue) interface{} {
+	if value == nil || len(value.Value) == 0 {
+		return nil
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(value.Value, &v); err != nil {
+		return nil
+	}
+
+	return v
+}
+
+func axValueString(value *accessibility.AXValue) string {
+	switch v := axValueInterface(value).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,276 @@
+package chrome
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mafredri/cdp/protocol/dom"
+	"github.com/mafredri/cdp/protocol/input"
+	"github.com/mafredri/cdp/protocol/page"
+	"io"
+	"log"
+	"time"
+)
+
+// Actions is a sequence of steps run one after another on a tab, built with Tab.Actions, e.g.
+//
+//	tab.Actions().Navigate(url).WaitFor("#login").Type("#user", user).Click("#submit").WaitForNav().Run(ctx)
+//
+// Steps use the deadline of the context passed to Run, or the default timeout of the tab when it has none. Run stops at
+// the first failing step and returns an *ActionError, running it again resumes from the failed step
+type Actions struct {
+	tab *tab
+	// tab passed to the steps added with Do, the tab handed out by the browser
+	owner Tab
+	// saves the failure artifacts of a failed run, set when the browser was launched with SetFailureArtifacts
+	capture func(method string, err error) error
+	steps   []action
+	// index of the step Run starts from
+	next int
+	// stream of the navigation awaited by a WaitForNav step, opened before the step preceding it
+	navigation page.DOMContentEventFiredClient
+}
+
+type action struct {
+	name string
+	run  func(ctx context.Context) error
+	// the step awaits the navigation triggered by the previous step
+	waitsForNav bool
+}
+
+// ActionError is returned by Actions.Run when a step fails
+type ActionError struct {
+	// Step is the index of the failed step
+	Step int
+	// Action describes the failed step, e.g. `click "#submit"`
+	Action string
+	Err    error
+}
+
+func (e *ActionError) Error() string {
+	return fmt.Sprintf("go-chrome-framework: step %v (%v) failed: %v", e.Step+1, e.Action, e.Err)
+}
+
+func (e *ActionError) Unwrap() error {
+	return e.Err
+}
+
+// Actions returns an empty sequence of steps to run on the tab
+func (t *tab) Actions() *Actions {
+	return &Actions{tab: t, owner: t}
+}
+
+func (a *Actions) add(name string, run func(ctx context.Context) error) *Actions {
+	a.steps = append(a.steps, action{name: name, run: run})
+	return a
+}
+
+// Navigate navigates to the url, see Tab.Navigate
+func (a *Actions) Navigate(url string) *Actions {
+	return a.add(fmt.Sprintf("navigate %q", url), func(ctx context.Context) error {
+		_, err := a.tab.Navigate(url, contextTimeout(ctx))
+		return err
+	})
+}
+
+// WaitFor waits until an element matches the selector
+func (a *Actions) WaitFor(selector string) *Actions {
+	return a.add(fmt.Sprintf("wait for %q", selector), func(ctx context.Context) error {
+		for {
+			var found bool
+			if err := a.tab.evaluate(ctx, fmt.Sprintf("document.querySelector(%v) !== null", jsString(selector)), &found); err != nil {
+				return err
+			}
+			if found {
+				return nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%w: %v", ErrNodeNotFound, selector)
+			case <-time.After(xpathPollInterval):
+			}
+		}
+	})
+}
+
+// Click scrolls the element matching the selector into view and clicks its center with the mouse
+func (a *Actions) Click(selector string) *Actions {
+	return a.add(fmt.Sprintf("click %q", selector), func(ctx context.Context) error {
+		nodeID, err := a.tab.querySelector(ctx, selector)
+		if err != nil {
+			return err
+		}
+
+		x, y, err := a.tab.nodeCenter(ctx, nodeID)
+		if err != nil {
+			return err
+		}
+
+		for _, eventType := range []string{"mouseMoved", "mousePressed", "mouseReleased"} {
+			if err = a.tab.dispatchMouse(ctx, eventType, x, y, eventType != "mouseMoved"); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Type focuses the element matching the selector and types the text into it, dispatching a key press per character
+func (a *Actions) Type(selector, text string) *Actions {
+	return a.add(fmt.Sprintf("type into %q", selector), func(ctx context.Context) error {
+		nodeID, err := a.tab.querySelector(ctx, selector)
+		if err != nil {
+			return err
+		}
+
+		client, err := a.tab.currentClient()
+		if err != nil {
+			return err
+		}
+
+		if err = client.DOM.Focus(ctx, dom.NewFocusArgs().SetNodeID(nodeID)); err != nil {
+			return err
+		}
+
+		for _, char := range text {
+			keyDown := input.NewDispatchKeyEventArgs("keyDown").SetText(string(char)).SetKey(string(char))
+			if err = client.Input.DispatchKeyEvent(ctx, keyDown); err != nil {
+				return err
+			}
+
+			keyUp := input.NewDispatchKeyEventArgs("keyUp").SetKey(string(char))
+			if err = client.Input.DispatchKeyEvent(ctx, keyUp); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Fill sets the values of form fields, see Tab.FillForm
+func (a *Actions) Fill(fields map[string]string) *Actions {
+	return a.add("fill form", func(ctx context.Context) error {
+		return a.tab.FillForm(fields, contextTimeout(ctx))
+	})
+}
+
+// WaitForNav waits until the navigation triggered by the previous step, e.g. clicking a link or submitting a form, has
+// loaded its document
+func (a *Actions) WaitForNav() *Actions {
+	a.add("wait for navigation", func(ctx context.Context) error {
+		if a.navigation == nil {
+			return errors.New("go-chrome-framework: no step to wait for the navigation of")
+		}
+		defer func() {
+			closeRes(a.navigation)
+			a.navigation = nil
+		}()
+
+		_, err := a.navigation.Recv()
+		return err
+	})
+	a.steps[len(a.steps)-1].waitsForNav = true
+
+	return a
+}
+
+// Evaluate evaluates the javascript expression and stores its value in result, see Tab.Evaluate
+func (a *Actions) Evaluate(expression string, result interface{}) *Actions {
+	return a.add("evaluate", func(ctx context.Context) error {
+		return a.tab.evaluate(ctx, expression, result)
+	})
+}
+
+// Screenshot captures a screenshot of the page to w, see Tab.CaptureScreenshotTo
+func (a *Actions) Screenshot(w io.Writer, opts ScreenshotOpts) *Actions {
+	return a.add("screenshot", func(ctx context.Context) error {
+		return a.tab.CaptureScreenshotTo(w, opts, contextTimeout(ctx))
+	})
+}
+
+// Sleep pauses for the duration, e.g. to let animations settle
+func (a *Actions) Sleep(duration time.Duration) *Actions {
+	return a.add(fmt.Sprintf("sleep %v", duration), func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(duration):
+			return nil
+		}
+	})
+}
+
+// Do runs a custom step, the tab is passed to it for convenience
+func (a *Actions) Do(name string, step func(ctx context.Context, tab Tab) error) *Actions {
+	return a.add(name, func(ctx context.Context) error {
+		return step(ctx, a.owner)
+	})
+}
+
+// Run runs the steps which have not succeeded yet in order, stopping at the first failing step
+func (a *Actions) Run(ctx context.Context) error {
+	err := a.run(ctx)
+	if err != nil && a.capture != nil {
+		return a.capture("Actions.Run", err)
+	}
+
+	return err
+}
+
+func (a *Actions) run(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.tab.resolveTimeout(0))
+		defer cancel()
+	}
+
+	if err := a.tab.ensureConnected(contextTimeout(ctx)); err != nil {
+		return err
+	}
+
+	for ; a.next < len(a.steps); a.next++ {
+		step := a.steps[a.next]
+
+		if a.next+1 < len(a.steps) && a.steps[a.next+1].waitsForNav {
+			if err := a.awaitNavigation(ctx); err != nil {
+				return &ActionError{Step: a.next, Action: step.name, Err: err}
+			}
+		}
+
+		if err := step.run(ctx); err != nil {
+			if a.navigation != nil {
+				closeRes(a.navigation)
+				a.navigation = nil
+			}
+
+			log.Println("go-chrome-framework error: unable to run action", err.Error())
+			return &ActionError{Step: a.next, Action: step.name, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// awaitNavigation opens the stream of the navigation awaited by the next step before the step triggering it runs
+func (a *Actions) awaitNavigation(ctx context.Context) error {
+	client, err := a.tab.currentClient()
+	if err != nil {
+		return err
+	}
+
+	navigation, err := client.Page.DOMContentEventFired(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = client.Page.Enable(ctx); err != nil {
+		closeRes(navigation)
+		return err
+	}
+
+	a.navigation = navigation
+	return nil
+}
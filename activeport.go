@@ -0,0 +1,164 @@
+package chrome
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// activePortPollInterval is how often the DevToolsActivePort file is checked for while the browser starts up
+const activePortPollInterval = 10 * time.Millisecond
+
+// devToolsListening matches the line chrome prints to stderr once the devtools protocol is exposed on a port
+var devToolsListening = regexp.MustCompile(`DevTools listening on (ws://\S+)`)
+
+// errBrowserExited is returned when the browser exits before it is ready
+var errBrowserExited = errors.New("go-chrome-framework: browser exited before it was ready")
+
+// activePortWatcher learns the websocket url of the browser from whichever comes first, the DevToolsActivePort file
+// chrome writes to its user data dir or the line it prints to stderr, instead of polling the http endpoint
+type activePortWatcher struct {
+	// guards line and url
+	mu sync.Mutex
+	// output of the current line, until it is complete
+	line []byte
+	url  string
+	// closed once the url is known
+	ready chan struct{}
+}
+
+func newActivePortWatcher() *activePortWatcher {
+	return &activePortWatcher{ready: make(chan struct{})}
+}
+
+// Write scans the output of the browser for the line announcing the websocket url
+func (a *activePortWatcher) Write(b []byte) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.url != "" {
+		return len(b), nil
+	}
+
+	a.line = append(a.line, b...)
+	for {
+		i := bytes.IndexByte(a.line, '\n')
+		if i < 0 {
+			break
+		}
+
+		if match := devToolsListening.FindSubmatch(a.line[:i]); match != nil {
+			a.found(string(match[1]))
+			break
+		}
+		a.line = a.line[i+1:]
+	}
+
+	return len(b), nil
+}
+
+// found records the url, the caller holds mu
+func (a *activePortWatcher) found(url string) {
+	if a.url != "" {
+		return
+	}
+
+	a.url = url
+	a.line = nil
+	close(a.ready)
+}
+
+// watchFile polls for the DevToolsActivePort file until it is written, ctx is done or the browser exits
+func (a *activePortWatcher) watchFile(ctx context.Context, path string, exited <-chan struct{}) {
+	ticker := time.NewTicker(activePortPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-a.ready:
+			return
+		case <-exited:
+			return
+		case <-ctx.Done():
+			return
+		}
+
+		url, err := readActivePort(path)
+		if err != nil {
+			continue
+		}
+
+		a.mu.Lock()
+		a.found(url)
+		a.mu.Unlock()
+
+		return
+	}
+}
+
+// wait returns the websocket url once it is known
+func (a *activePortWatcher) wait(ctx context.Context, exited <-chan struct{}) (string, error) {
+	select {
+	case <-a.ready:
+	case <-exited:
+		return "", errBrowserExited
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.url, nil
+}
+
+// readActivePort reads the websocket url from a complete DevToolsActivePort file, which holds the port on the first
+// line and the path of the browser target on the second
+func readActivePort(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) < 2 {
+		return "", fmt.Errorf("go-chrome-framework: incomplete devtools active port file %v", path)
+	}
+
+	port, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("ws://127.0.0.1:%v%v", port, strings.TrimSpace(lines[1])), nil
+}
+
+// activePortPath returns the path of the DevToolsActivePort file in the user data dir the browser is launched with, if
+// the arguments set one
+func activePortPath(arguments []string) string {
+	dir := userDataDir(arguments)
+	if dir == "" {
+		return ""
+	}
+
+	return filepath.Join(dir, "DevToolsActivePort")
+}
+
+// removeStaleActivePort removes the DevToolsActivePort file left behind by a previous browser using the same user data
+// dir, so that it is not mistaken for the file of the browser being launched
+func removeStaleActivePort(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
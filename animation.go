@@ -0,0 +1,89 @@
+package chrome
+
+import (
+	"context"
+	"fmt"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/animation"
+	"github.com/mafredri/cdp/protocol/page"
+	"log"
+	"time"
+)
+
+// freezeAnimationsCSS finishes css animations and transitions right away, so that elements are captured in their final
+// state, and hides the blinking caret
+const freezeAnimationsCSS = `*, *::before, *::after {
+	animation-delay: 0s !important;
+	animation-duration: 0s !important;
+	animation-iteration-count: 1 !important;
+	transition-delay: 0s !important;
+	transition-duration: 0s !important;
+	caret-color: transparent !important;
+}`
+
+// SetAnimationPlaybackRate sets the rate css animations, transitions and web animations of the tab play at, e.g. 0.5
+// for half speed or 0 to pause them
+func (t *tab) SetAnimationPlaybackRate(rate float64, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if err := t.setAnimationPlaybackRate(ctx, rate); err != nil {
+		log.Println("go-chrome-framework error: unable to set animation playback rate", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (t *tab) setAnimationPlaybackRate(ctx context.Context, rate float64) error {
+	return t.call(ctx, func(client *cdp.Client) error {
+		if err := client.Animation.Enable(ctx); err != nil {
+			return err
+		}
+
+		return client.Animation.SetPlaybackRate(ctx, animation.NewSetPlaybackRateArgs(rate))
+	})
+}
+
+// FreezeAnimations makes captures deterministic by finishing css animations and transitions right away, in the current
+// and later documents, and pausing web animations, e.g. of carousels. Animations driven by timers of scripts are not
+// affected, see SetAnimationPlaybackRate to only slow down animations
+func (t *tab) FreezeAnimations(timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if err := t.setAnimationPlaybackRate(ctx, 0); err != nil {
+		log.Println("go-chrome-framework error: unable to pause animations", err.Error())
+		return err
+	}
+
+	script := fmt.Sprintf(injectStyleScript, jsString(freezeAnimationsCSS))
+
+	client, err := t.currentClient()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Page.AddScriptToEvaluateOnNewDocument(ctx, page.NewAddScriptToEvaluateOnNewDocumentArgs(script))
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to freeze animations of new documents", err.Error())
+		return err
+	}
+
+	if err = t.evaluate(ctx, script, nil); err != nil {
+		log.Println("go-chrome-framework error: unable to freeze animations", err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package chrome
+
+import (
+	"context"
+	"fmt"
+	"github.com/mafredri/cdp/protocol/network"
+	"log"
+	"sync"
+	"time"
+)
+
+// inflightRequests counts the requests of a tab which are in flight
+type inflightRequests struct {
+	mu       sync.Mutex
+	requests map[network.RequestID]bool
+	// time at which the last request finished
+	lastActivity time.Time
+}
+
+func (i *inflightRequests) started(id network.RequestID) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.requests[id] = true
+	i.lastActivity = time.Now()
+}
+
+func (i *inflightRequests) finished(id network.RequestID) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	delete(i.requests, id)
+	i.lastActivity = time.Now()
+}
+
+// idle reports whether no request has been in flight for at least d
+func (i *inflightRequests) idle(d time.Duration) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return len(i.requests) == 0 && time.Since(i.lastActivity) >= d
+}
+
+// trackRequests counts the requests in flight until ctx is done
+func (t *tab) trackRequests(ctx context.Context) (*inflightRequests, error) {
+	client, err := t.currentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	requestWillBeSent, err := client.Network.RequestWillBeSent(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	loadingFinished, err := client.Network.LoadingFinished(ctx)
+	if err != nil {
+		closeRes(requestWillBeSent)
+		return nil, err
+	}
+
+	loadingFailed, err := client.Network.LoadingFailed(ctx)
+	if err != nil {
+		closeRes(requestWillBeSent)
+		closeRes(loadingFinished)
+		return nil, err
+	}
+
+	inflight := &inflightRequests{
+		requests:     make(map[network.RequestID]bool),
+		lastActivity: time.Now(),
+	}
+
+	go func() {
+		defer closeRes(requestWillBeSent)
+		for {
+			reply, err := requestWillBeSent.Recv()
+			if err != nil {
+				return
+			}
+			inflight.started(reply.RequestID)
+		}
+	}()
+
+	go func() {
+		defer closeRes(loadingFinished)
+		for {
+			reply, err := loadingFinished.Recv()
+			if err != nil {
+				return
+			}
+			inflight.finished(reply.RequestID)
+		}
+	}()
+
+	go func() {
+		defer closeRes(loadingFailed)
+		for {
+			reply, err := loadingFailed.Recv()
+			if err != nil {
+				return
+			}
+			inflight.finished(reply.RequestID)
+		}
+	}()
+
+	return inflight, nil
+}
+
+// AutoScroll keeps scrolling to the bottom of the page until no new content appears, that is the height of the
+// document stays the same for a number of consecutive steps while the network is idle
+func (t *tab) AutoScroll(opts AutoScrollOpts, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if opts.Delay == 0 {
+		opts.Delay = 250 * time.Millisecond
+	}
+	if opts.NetworkIdle == 0 {
+		opts.NetworkIdle = 500 * time.Millisecond
+	}
+	if opts.StableSteps == 0 {
+		opts.StableSteps = 3
+	}
+
+	inflight, err := t.trackRequests(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to track network requests", err.Error())
+		return err
+	}
+
+	stepExpression := fmt.Sprintf("%v", opts.Step)
+	if opts.Step <= 0 {
+		stepExpression = "window.innerHeight"
+	}
+
+	script := fmt.Sprintf(`(() => {
+		window.scrollBy(0, %v);
+		const scroller = document.scrollingElement || document.documentElement;
+		return scroller.scrollHeight;
+	})()`, stepExpression)
+
+	lastHeight, stable := -1.0, 0
+	for iteration := 1; opts.MaxIterations == 0 || iteration <= opts.MaxIterations; iteration++ {
+		var height float64
+		if err = t.evaluate(ctx, script, &height); err != nil {
+			log.Println("go-chrome-framework error: unable to scroll", err.Error())
+			return err
+		}
+
+		// give the page a chance to load more content, then wait for the network to settle
+		select {
+		case <-time.After(opts.Delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		for !inflight.idle(opts.NetworkIdle) {
+			select {
+			case <-time.After(50 * time.Millisecond):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if opts.OnStep != nil {
+			if err = opts.OnStep(iteration, height); err != nil {
+				return err
+			}
+		}
+
+		if height == lastHeight {
+			stable++
+			if stable >= opts.StableSteps {
+				return nil
+			}
+		} else {
+			stable = 0
+		}
+		lastHeight = height
+	}
+
+	return nil
+}
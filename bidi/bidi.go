@@ -0,0 +1,316 @@
+// Package bidi is an experimental transport driving browsers over WebDriver BiDi instead of the chrome devtools
+// protocol. It covers navigating, evaluating scripts and capturing screenshots with the same method signatures as
+// chrome.Tab, its browsers and tabs implement chrome.Browser and chrome.Page, so that code written against them runs on
+// browsers speaking BiDi, e.g. firefox launched with LaunchFirefox, or chrome through chromedriver
+package bidi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mafredri/cdp/rpcc"
+	chrome "go.ajitem.com/gcf/v3"
+	"log"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// ErrEvaluation is wrapped by the errors of scripts which throw
+var ErrEvaluation = errors.New("go-chrome-framework: script threw an exception")
+
+// Opts configures the connection to a BiDi endpoint
+type Opts struct {
+	// NewSession starts a session with session.new once connected. Endpoints of sessions started over webdriver
+	// classic, e.g. the webSocketUrl capability of chromedriver, have a session already
+	NewSession bool
+	// Capabilities requested when starting a session, e.g. {"acceptInsecureCerts": true}
+	Capabilities map[string]interface{}
+}
+
+// Browser is a browser driven over WebDriver BiDi
+type Browser struct {
+	conn *rpcc.Conn
+	// whether the session was started by us and is ended on Close
+	session bool
+	// guards closed
+	mu     sync.Mutex
+	closed bool
+	// process of the browser when launched by us, e.g. with LaunchFirefox
+	command *exec.Cmd
+	// closed once the process exits
+	exited chan struct{}
+	// temporary profile removed once the browser is terminated
+	profileDir string
+}
+
+// Connect connects to the BiDi websocket endpoint of a browser, e.g. ws://127.0.0.1:9222/session for firefox launched
+// with --remote-debugging-port=9222
+func Connect(ctx context.Context, url string, opts Opts) (*Browser, error) {
+	conn, err := rpcc.DialContext(ctx, url, rpcc.WithCodec(newCodec))
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to connect to bidi endpoint", err.Error())
+		return nil, err
+	}
+
+	b := &Browser{conn: conn}
+
+	if opts.NewSession {
+		capabilities := opts.Capabilities
+		if capabilities == nil {
+			capabilities = map[string]interface{}{}
+		}
+
+		args := map[string]interface{}{"capabilities": map[string]interface{}{"alwaysMatch": capabilities}}
+		if err = rpcc.Invoke(ctx, "session.new", args, nil, conn); err != nil {
+			log.Println("go-chrome-framework error: unable to start bidi session", err.Error())
+			closeRes(conn)
+			return nil, err
+		}
+		b.session = true
+	}
+
+	return b, nil
+}
+
+// OpenNewTab opens a new tab, the same way chrome.Chrome.OpenNewTab does
+func (b *Browser) OpenNewTab(timeout time.Duration) (*Tab, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout(timeout))
+	defer cancel()
+
+	var reply struct {
+		Context string `json:"context"`
+	}
+	if err := rpcc.Invoke(ctx, "browsingContext.create", map[string]string{"type": "tab"}, &reply, b.conn); err != nil {
+		log.Println("go-chrome-framework error: unable to create new tab", err.Error())
+		return nil, err
+	}
+
+	return &Tab{browser: b, context: reply.Context}, nil
+}
+
+// Tabs returns the top-level browsing contexts of the browser
+func (b *Browser) Tabs(timeout time.Duration) ([]*Tab, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout(timeout))
+	defer cancel()
+
+	var reply struct {
+		Contexts []struct {
+			Context string `json:"context"`
+		} `json:"contexts"`
+	}
+	if err := rpcc.Invoke(ctx, "browsingContext.getTree", map[string]int{"maxDepth": 0}, &reply, b.conn); err != nil {
+		log.Println("go-chrome-framework error: unable to get browsing contexts", err.Error())
+		return nil, err
+	}
+
+	tabs := make([]*Tab, 0, len(reply.Contexts))
+	for _, c := range reply.Contexts {
+		tabs = append(tabs, &Tab{browser: b, context: c.Context})
+	}
+
+	return tabs, nil
+}
+
+// Close ends the session started by Connect, if any, and closes the connection. The browser keeps running
+func (b *Browser) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	if b.session {
+		if err := rpcc.Invoke(ctx, "session.end", nil, nil, b.conn); err != nil {
+			log.Println("go-chrome-framework error: unable to end bidi session", err.Error())
+		}
+	}
+
+	return b.conn.Close()
+}
+
+// OpenPage opens a new tab, see OpenNewTab. Together with Terminate it implements chrome.Browser
+func (b *Browser) OpenPage(timeout time.Duration) (chrome.Page, error) {
+	return b.OpenNewTab(timeout)
+}
+
+// Terminate closes the connection and kills the browser if it was launched by us, e.g. with LaunchFirefox
+func (b *Browser) Terminate() error {
+	ctx, cancel := context.WithTimeout(context.Background(), chrome.DefaultTimeout)
+	defer cancel()
+
+	err := b.Close(ctx)
+
+	if b.command != nil {
+		if killErr := b.command.Process.Kill(); killErr != nil {
+			log.Println("go-chrome-framework error: unable to kill browser", killErr.Error())
+		}
+		<-b.exited
+		removeProfile(b.profileDir)
+	}
+
+	return err
+}
+
+// Tab is a top-level browsing context of a browser driven over WebDriver BiDi
+type Tab struct {
+	browser *Browser
+	// id of the browsing context
+	context string
+}
+
+// ID returns the id of the browsing context of the tab
+func (t *Tab) ID() string {
+	return t.context
+}
+
+// Navigate navigates the tab to the url and waits for the document to become interactive. BiDi does not report the
+// response of the document, the result holds its url only
+func (t *Tab) Navigate(url string, timeout time.Duration) (*chrome.NavigationResult, error) {
+	if timeout == 0 {
+		timeout = chrome.DefaultNavigationTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	args := map[string]string{"context": t.context, "url": url, "wait": "interactive"}
+
+	var reply struct {
+		URL string `json:"url"`
+	}
+	if err := rpcc.Invoke(ctx, "browsingContext.navigate", args, &reply, t.browser.conn); err != nil {
+		log.Println("go-chrome-framework error: unable to navigate", err.Error())
+		return nil, err
+	}
+
+	return &chrome.NavigationResult{URL: reply.URL}, nil
+}
+
+// Evaluate evaluates the javascript expression in the tab, awaiting promises, and stores its value in the value pointed
+// to by result, which may be nil
+func (t *Tab) Evaluate(expression string, result interface{}, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout(timeout))
+	defer cancel()
+
+	args := map[string]interface{}{
+		"expression":   expression,
+		"target":       map[string]string{"context": t.context},
+		"awaitPromise": true,
+	}
+
+	var reply struct {
+		Type             string      `json:"type"`
+		Result           remoteValue `json:"result"`
+		ExceptionDetails struct {
+			Text string `json:"text"`
+		} `json:"exceptionDetails"`
+	}
+	if err := rpcc.Invoke(ctx, "script.evaluate", args, &reply, t.browser.conn); err != nil {
+		log.Println("go-chrome-framework error: unable to evaluate script", err.Error())
+		return err
+	}
+
+	if reply.Type == "exception" {
+		return fmt.Errorf("%w: %v", ErrEvaluation, reply.ExceptionDetails.Text)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	value, err := reply.Result.decode()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, result)
+}
+
+// GetHTML returns the html of the document of the tab
+func (t *Tab) GetHTML(timeout time.Duration) (string, error) {
+	var html string
+	err := t.Evaluate("document.documentElement.outerHTML", &html, timeout)
+
+	return html, err
+}
+
+// CaptureScreenshot captures the full page as a data url, like chrome.Tab.CaptureScreenshot. Only the format and
+// quality of the options are supported
+func (t *Tab) CaptureScreenshot(opts chrome.ScreenshotOpts, timeout time.Duration) (string, error) {
+	mimeType, data, err := t.captureScreenshot(opts, timeout)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("data:%v;base64,%v", mimeType, data), nil
+}
+
+// CaptureScreenshotData captures the full page like CaptureScreenshot, returning the decoded image
+func (t *Tab) CaptureScreenshotData(opts chrome.ScreenshotOpts, timeout time.Duration) ([]byte, error) {
+	_, data, err := t.captureScreenshot(opts, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(data)
+}
+
+// captureScreenshot returns the mime type and base64 data of a screenshot of the full page
+func (t *Tab) captureScreenshot(opts chrome.ScreenshotOpts, timeout time.Duration) (string, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout(timeout))
+	defer cancel()
+
+	format := map[string]interface{}{"type": "image/png"}
+	if opts.Format == "jpeg" {
+		if opts.Quality == 0 {
+			opts.Quality = 80
+		}
+		format = map[string]interface{}{"type": "image/jpeg", "quality": float64(opts.Quality) / 100}
+	}
+
+	args := map[string]interface{}{"context": t.context, "origin": "document", "format": format}
+
+	var reply struct {
+		Data string `json:"data"`
+	}
+	if err := rpcc.Invoke(ctx, "browsingContext.captureScreenshot", args, &reply, t.browser.conn); err != nil {
+		log.Println("go-chrome-framework error: unable to capture screenshot", err.Error())
+		return "", "", err
+	}
+
+	return format["type"].(string), reply.Data, nil
+}
+
+// Close closes the tab
+func (t *Tab) Close(ctx context.Context) error {
+	if err := rpcc.Invoke(ctx, "browsingContext.close", map[string]string{"context": t.context}, nil, t.browser.conn); err != nil {
+		log.Println("go-chrome-framework error: unable to close tab", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// resolveTimeout falls back to chrome.DefaultTimeout for a zero timeout, like the methods of chrome.Tab
+func resolveTimeout(timeout time.Duration) time.Duration {
+	if timeout == 0 {
+		return chrome.DefaultTimeout
+	}
+
+	return timeout
+}
+
+func closeRes(conn *rpcc.Conn) {
+	if err := conn.Close(); err != nil {
+		log.Println("error occurred while trying to close resource", err.Error())
+	}
+}
@@ -0,0 +1,154 @@
+package bidi
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/mafredri/cdp/rpcc"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCodecWriteRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		request rpcc.Request
+		want    string
+	}{
+		{
+			name:    "params",
+			request: rpcc.Request{ID: 1, Method: "browsingContext.navigate", Args: map[string]string{"url": "about:blank"}},
+			want:    `{"id":1,"method":"browsingContext.navigate","params":{"url":"about:blank"}}`,
+		},
+		{
+			name:    "no params",
+			request: rpcc.Request{ID: 2, Method: "session.status"},
+			want:    `{"id":2,"method":"session.status","params":{}}`,
+		},
+	}
+
+	for _, test := range tests {
+		var conn bytes.Buffer
+		if err := newCodec(&conn).WriteRequest(&test.request); err != nil {
+			t.Fatalf("%v: WriteRequest() = %v", test.name, err)
+		}
+
+		if conn.String() != test.want {
+			t.Errorf("%v: WriteRequest() wrote %v, want %v", test.name, conn.String(), test.want)
+		}
+	}
+}
+
+func TestCodecReadResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    rpcc.Response
+	}{
+		{
+			name:    "result",
+			message: `{"type":"success","id":3,"result":{"ready":true}}`,
+			want:    rpcc.Response{ID: 3, Result: json.RawMessage(`{"ready":true}`)},
+		},
+		{
+			name:    "error",
+			message: `{"type":"error","id":4,"error":"no such frame","message":"frame 1 not found"}`,
+			want: rpcc.Response{ID: 4, Error: &rpcc.ResponseError{
+				Code:    -32000,
+				Message: "no such frame",
+				Data:    "frame 1 not found",
+			}},
+		},
+		{
+			name:    "event",
+			message: `{"type":"event","method":"log.entryAdded","params":{"level":"info"}}`,
+			want:    rpcc.Response{Method: "log.entryAdded", Args: json.RawMessage(`{"level":"info"}`)},
+		},
+		{
+			name: "error without a command",
+			message: `{"type":"error","id":null,"error":"invalid argument","message":"unparsable command"}
+{"type":"success","id":5,"result":{}}`,
+			want: rpcc.Response{ID: 5, Result: json.RawMessage(`{}`)},
+		},
+	}
+
+	for _, test := range tests {
+		var response rpcc.Response
+		if err := newCodec(bytes.NewBufferString(test.message)).ReadResponse(&response); err != nil {
+			t.Fatalf("%v: ReadResponse() = %v", test.name, err)
+		}
+
+		if !reflect.DeepEqual(response, test.want) {
+			t.Errorf("%v: ReadResponse() = %+v, want %+v", test.name, response, test.want)
+		}
+	}
+}
+
+func TestRemoteValueDecode(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  interface{}
+		err   bool
+	}{
+		{name: "string", value: `{"type":"string","value":"text"}`, want: "text"},
+		{name: "boolean", value: `{"type":"boolean","value":true}`, want: true},
+		{name: "number", value: `{"type":"number","value":1.5}`, want: 1.5},
+		{name: "NaN", value: `{"type":"number","value":"NaN"}`, want: nil},
+		{name: "infinity", value: `{"type":"number","value":"Infinity"}`, want: nil},
+		{name: "bigint", value: `{"type":"bigint","value":"9007199254740993"}`, want: "9007199254740993"},
+		{name: "undefined", value: `{"type":"undefined"}`, want: nil},
+		{name: "null", value: `{"type":"null"}`, want: nil},
+		{name: "function", value: `{"type":"function"}`, want: nil},
+		{
+			name:  "array",
+			value: `{"type":"array","value":[{"type":"number","value":1},{"type":"set","value":[{"type":"null"}]}]}`,
+			want:  []interface{}{1.0, []interface{}{nil}},
+		},
+		{
+			name:  "object",
+			value: `{"type":"object","value":[["a",{"type":"number","value":1}],["b",{"type":"null"}]]}`,
+			want:  map[string]interface{}{"a": 1.0, "b": nil},
+		},
+		{
+			name:  "map with a non-string key",
+			value: `{"type":"map","value":[[{"type":"number","value":1},{"type":"string","value":"one"}]]}`,
+			want:  map[string]interface{}{"1": "one"},
+		},
+		{name: "malformed array", value: `{"type":"array","value":{}}`, err: true},
+		{name: "malformed item", value: `{"type":"set","value":[{"type":"object","value":{}}]}`, err: true},
+	}
+
+	for _, test := range tests {
+		var remote remoteValue
+		if err := json.Unmarshal([]byte(test.value), &remote); err != nil {
+			t.Fatalf("%v: json.Unmarshal() = %v", test.name, err)
+		}
+
+		value, err := remote.decode()
+		if test.err {
+			if err == nil {
+				t.Errorf("%v: decode() = %v, want an error", test.name, value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: decode() = %v", test.name, err)
+		}
+
+		if !reflect.DeepEqual(value, test.want) {
+			t.Errorf("%v: decode() = %#v, want %#v", test.name, value, test.want)
+		}
+	}
+}
+
+func TestRemoteValueDecodeNegativeZero(t *testing.T) {
+	value, err := remoteValue{Type: "number", Value: json.RawMessage(`"-0"`)}.decode()
+	if err != nil {
+		t.Fatalf("decode() = %v", err)
+	}
+
+	if f, ok := value.(float64); !ok || f != 0 || !math.Signbit(f) {
+		t.Errorf("decode() = %v, want -0", value)
+	}
+}
@@ -0,0 +1,77 @@
+package bidi
+
+import (
+	"encoding/json"
+	"github.com/mafredri/cdp/rpcc"
+	"io"
+)
+
+// codec translates between the messages of WebDriver BiDi and the json-rpc messages of rpcc, so that commands are sent
+// with rpcc.Invoke and events are received with rpcc.NewStream
+type codec struct {
+	decoder *json.Decoder
+	w       io.Writer
+}
+
+func newCodec(conn io.ReadWriter) rpcc.Codec {
+	return &codec{decoder: json.NewDecoder(conn), w: conn}
+}
+
+// command is a BiDi command, whose params are required even when empty
+type command struct {
+	ID     uint64      `json:"id"`
+	Method string      `json:"method"`
+	Params interface{} `json:"params"`
+}
+
+// message is a BiDi command result, error or event
+type message struct {
+	Type    string          `json:"type"`
+	ID      *uint64         `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   string          `json:"error"`
+	Message string          `json:"message"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+func (c *codec) WriteRequest(request *rpcc.Request) error {
+	params := request.Args
+	if params == nil {
+		params = struct{}{}
+	}
+
+	data, err := json.Marshal(command{ID: request.ID, Method: request.Method, Params: params})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.w.Write(data)
+	return err
+}
+
+func (c *codec) ReadResponse(response *rpcc.Response) error {
+	for {
+		var m message
+		if err := c.decoder.Decode(&m); err != nil {
+			return err
+		}
+
+		switch {
+		case m.Type == "event":
+			response.Method = m.Method
+			response.Args = m.Params
+		case m.ID == nil:
+			// errors which do not belong to a command have nobody to report to
+			continue
+		case m.Type == "error":
+			response.ID = *m.ID
+			response.Error = &rpcc.ResponseError{Code: -32000, Message: m.Error, Data: m.Message}
+		default:
+			response.ID = *m.ID
+			response.Result = m.Result
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,130 @@
+package bidi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// firefoxPollInterval is how often connecting to firefox is attempted while it starts up
+const firefoxPollInterval = 100 * time.Millisecond
+
+// FirefoxOpts configures launching firefox, see LaunchFirefox
+type FirefoxOpts struct {
+	// Path of the firefox binary, defaults to "firefox"
+	Path     string
+	Headless bool
+	// Port the remote protocol listens on, a free port is picked if 0
+	Port int
+	// Arguments are additional arguments to launch firefox with
+	Arguments []string
+	// ProfileDir is the profile to launch firefox with, defaults to a temporary profile removed on Terminate
+	ProfileDir string
+	// Output receives the stdout and stderr of firefox
+	Output io.Writer
+}
+
+// LaunchFirefox launches firefox with its remote protocol enabled and connects to it over WebDriver BiDi once it is
+// ready, or ctx is done
+func LaunchFirefox(ctx context.Context, opts FirefoxOpts) (*Browser, error) {
+	if opts.Path == "" {
+		opts.Path = "firefox"
+	}
+
+	if opts.Port == 0 {
+		port, err := freePort()
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to find a free port", err.Error())
+			return nil, err
+		}
+		opts.Port = port
+	}
+
+	temporaryProfile := ""
+	if opts.ProfileDir == "" {
+		dir, err := os.MkdirTemp("", "gcf-firefox-profile")
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to create firefox profile", err.Error())
+			return nil, err
+		}
+		opts.ProfileDir = dir
+		temporaryProfile = dir
+	}
+
+	arguments := []string{
+		fmt.Sprintf("--remote-debugging-port=%v", opts.Port),
+		"--profile", opts.ProfileDir,
+		"--no-remote",
+		"--new-instance",
+	}
+	if opts.Headless {
+		arguments = append(arguments, "--headless")
+	}
+	arguments = append(arguments, opts.Arguments...)
+
+	command := exec.Command(opts.Path, arguments...)
+	command.Stdout = opts.Output
+	command.Stderr = opts.Output
+
+	if err := command.Start(); err != nil {
+		log.Println("go-chrome-framework error: unable to launch firefox", err.Error())
+		removeProfile(temporaryProfile)
+		return nil, err
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		_ = command.Wait()
+		close(exited)
+	}()
+
+	url := fmt.Sprintf("ws://127.0.0.1:%v/session", opts.Port)
+	for {
+		browser, err := Connect(ctx, url, Opts{NewSession: true})
+		if err == nil {
+			browser.command = command
+			browser.exited = exited
+			browser.profileDir = temporaryProfile
+			return browser, nil
+		}
+
+		select {
+		case <-time.After(firefoxPollInterval):
+		case <-exited:
+			removeProfile(temporaryProfile)
+			return nil, fmt.Errorf("go-chrome-framework: firefox exited before it was ready")
+		case <-ctx.Done():
+			_ = command.Process.Kill()
+			<-exited
+			removeProfile(temporaryProfile)
+			return nil, err
+		}
+	}
+}
+
+// freePort asks the kernel for a free ephemeral port on the loopback interface
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// removeProfile removes a temporary profile, if any
+func removeProfile(dir string) {
+	if dir == "" {
+		return
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		log.Println("go-chrome-framework error: unable to remove firefox profile", err.Error())
+	}
+}
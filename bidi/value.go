@@ -0,0 +1,98 @@
+package bidi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// remoteValue is a value serialized by the browser, e.g. the result of evaluating a script
+type remoteValue struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// decode converts the remote value to the value json.Unmarshal would produce for it. Values without a json
+// representation, e.g. functions or nodes, decode to nil
+func (r remoteValue) decode() (interface{}, error) {
+	switch r.Type {
+	case "string", "boolean", "bigint", "date", "regexp":
+		var value interface{}
+		if err := json.Unmarshal(r.Value, &value); err != nil {
+			return nil, err
+		}
+
+		return value, nil
+	case "number":
+		var value interface{}
+		if err := json.Unmarshal(r.Value, &value); err != nil {
+			return nil, err
+		}
+
+		// NaN, -0 and the infinities are serialized as strings
+		if special, ok := value.(string); ok {
+			switch special {
+			case "-0":
+				return math.Copysign(0, -1), nil
+			default:
+				return nil, nil
+			}
+		}
+
+		return value, nil
+	case "array", "set":
+		var items []remoteValue
+		if err := json.Unmarshal(r.Value, &items); err != nil {
+			return nil, err
+		}
+
+		values := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			value, err := item.decode()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+
+		return values, nil
+	case "object", "map":
+		var entries [][2]json.RawMessage
+		if err := json.Unmarshal(r.Value, &entries); err != nil {
+			return nil, err
+		}
+
+		values := make(map[string]interface{}, len(entries))
+		for _, entry := range entries {
+			// keys are strings, or remote values for the non-string keys of maps
+			var key string
+			if err := json.Unmarshal(entry[0], &key); err != nil {
+				var remoteKey remoteValue
+				if err = json.Unmarshal(entry[0], &remoteKey); err != nil {
+					return nil, err
+				}
+
+				decoded, err := remoteKey.decode()
+				if err != nil {
+					return nil, err
+				}
+				key = fmt.Sprint(decoded)
+			}
+
+			var item remoteValue
+			if err := json.Unmarshal(entry[1], &item); err != nil {
+				return nil, err
+			}
+
+			value, err := item.decode()
+			if err != nil {
+				return nil, err
+			}
+			values[key] = value
+		}
+
+		return values, nil
+	default:
+		return nil, nil
+	}
+}
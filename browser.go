@@ -0,0 +1,47 @@
+package chrome
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Page is the part of a tab other browsers can implement as well, e.g. firefox driven over WebDriver BiDi by the bidi
+// package. Code written against Page, e.g. cross-browser screenshot comparisons, runs on the tabs of any Browser
+type Page interface {
+	Navigate(url string, timeout time.Duration) (*NavigationResult, error)
+	GetHTML(timeout time.Duration) (string, error)
+	Evaluate(expression string, result interface{}, timeout time.Duration) error
+	CaptureScreenshot(opts ScreenshotOpts, timeout time.Duration) (string, error)
+	Close(ctx context.Context) error
+}
+
+// Browser opens pages, it is implemented by Chrome and by the browsers of the bidi package
+type Browser interface {
+	OpenPage(timeout time.Duration) (Page, error)
+	Terminate() error
+}
+
+// OpenPage opens a new tab, see OpenNewTab
+func (c *chrome) OpenPage(timeout time.Duration) (Page, error) {
+	return c.OpenNewTab(timeout)
+}
+
+// Evaluate evaluates the javascript expression, awaiting the result if it is a promise, and stores its value in the
+// value pointed to by result, which may be nil. Exceptions are returned as a *JavaScriptError
+func (t *tab) Evaluate(expression string, result interface{}, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if err := t.evaluate(ctx, expression, result); err != nil {
+		log.Println("go-chrome-framework error: unable to evaluate expression", err.Error())
+		return err
+	}
+
+	return nil
+}
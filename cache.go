@@ -0,0 +1,54 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/network"
+	"log"
+	"time"
+)
+
+// SetCacheDisabled makes the requests of the tab bypass the browser cache
+func (t *tab) SetCacheDisabled(disabled bool, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if err := t.setCacheDisabled(ctx, disabled); err != nil {
+		log.Println("go-chrome-framework error: unable to set cache disabled", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (t *tab) setCacheDisabled(ctx context.Context, disabled bool) error {
+	return t.call(ctx, func(client *cdp.Client) error {
+		return client.Network.SetCacheDisabled(ctx, network.NewSetCacheDisabledArgs(disabled))
+	})
+}
+
+// ClearBrowserCache clears the cache of the browser, shared by all the tabs of the browser context
+func (t *tab) ClearBrowserCache(timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.Network.ClearBrowserCache(ctx)
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to clear browser cache", err.Error())
+		return err
+	}
+
+	return nil
+}
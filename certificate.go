@@ -0,0 +1,133 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/security"
+	"log"
+	"time"
+)
+
+// CertificateError is a certificate error encountered while loading a resource
+type CertificateError struct {
+	// ErrorType is the network error, e.g. "net::ERR_CERT_DATE_INVALID"
+	ErrorType string
+	// RequestURL of the resource
+	RequestURL string
+}
+
+// CertificateErrorHandler decides whether loading a resource proceeds despite a certificate error
+type CertificateErrorHandler func(CertificateError) (proceed bool)
+
+// SetIgnoreCertificateErrors makes the tab ignore certificate errors, or report them when false. Certificate errors are
+// only reported when the browser is launched with LaunchOpts.SetStrictTLS, as --ignore-certificate-errors takes
+// precedence
+func (t *tab) SetIgnoreCertificateErrors(ignore bool, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return setIgnoreCertificateErrors(ctx, client, ignore)
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to set ignore certificate errors", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func setIgnoreCertificateErrors(ctx context.Context, client *cdp.Client, ignore bool) error {
+	return client.Security.SetIgnoreCertificateErrors(ctx, security.NewSetIgnoreCertificateErrorsArgs(ignore))
+}
+
+// OnCertificateError invokes the handler for every certificate error of the tab, the resource is loaded when the
+// handler returns true and fails to load otherwise. Passing a nil handler stops handling certificate errors. Requires
+// the browser to be launched with LaunchOpts.SetStrictTLS
+func (t *tab) OnCertificateError(handler CertificateErrorHandler, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if t.certificateErrors != nil {
+		t.certificateErrors()
+		t.certificateErrors = nil
+	}
+
+	client, err := t.currentClient()
+	if err != nil {
+		return err
+	}
+
+	if handler == nil {
+		err = client.Security.SetOverrideCertificateErrors(ctx, security.NewSetOverrideCertificateErrorsArgs(false))
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to stop handling certificate errors", err.Error())
+		}
+		return err
+	}
+
+	// certificate errors are reported until handling is stopped, hence the stream is not bound to the timeout
+	streamCtx, streamCancel := context.WithCancel(context.Background())
+
+	certificateError, err := client.Security.CertificateError(streamCtx)
+	if err != nil {
+		streamCancel()
+		log.Println("go-chrome-framework error: unable to open certificate error client", err.Error())
+		return err
+	}
+
+	if err = client.Security.Enable(ctx); err != nil {
+		streamCancel()
+		closeRes(certificateError)
+		log.Println("go-chrome-framework error: unable to enable security domain", err.Error())
+		return err
+	}
+
+	err = client.Security.SetOverrideCertificateErrors(ctx, security.NewSetOverrideCertificateErrorsArgs(true))
+	if err != nil {
+		streamCancel()
+		closeRes(certificateError)
+		log.Println("go-chrome-framework error: unable to handle certificate errors", err.Error())
+		return err
+	}
+
+	t.certificateErrors = streamCancel
+
+	go func(client *cdp.Client) {
+		defer closeRes(certificateError)
+
+		for {
+			event, err := certificateError.Recv()
+			if err != nil {
+				return
+			}
+
+			action := security.CertificateErrorActionCancel
+			if handler(CertificateError{ErrorType: event.ErrorType, RequestURL: event.RequestURL}) {
+				action = security.CertificateErrorActionContinue
+			}
+
+			handleCtx, handleCancel := context.WithTimeout(streamCtx, t.resolveTimeout(0))
+			err = client.Security.HandleCertificateError(handleCtx, security.NewHandleCertificateErrorArgs(event.EventID, action))
+			handleCancel()
+			if err != nil {
+				log.Println("go-chrome-framework error: unable to handle certificate error", err.Error())
+			}
+		}
+	}(client)
+
+	return nil
+}
@@ -3,32 +3,52 @@ package chrome
 import (
 	"context"
 	"fmt"
-	"github.com/flowchartsman/retry"
 	"github.com/mafredri/cdp"
 	"github.com/mafredri/cdp/devtool"
 	"github.com/mafredri/cdp/protocol/target"
 	"github.com/mafredri/cdp/rpcc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"io"
 	"log"
+	"net"
+	"os"
 	"os/exec"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
 type Chrome interface {
 	Launch(*LaunchOpts) (Tab, error)
+	LaunchContext(context.Context, *LaunchOpts) (Tab, error)
+	LaunchWithResult(context.Context, *LaunchOpts) (*LaunchResult, error)
 	Wait()
 	Terminate() error
-	OpenTab(target.ID, time.Duration) Tab
+	OpenPage(time.Duration) (Page, error)
+	OpenTab(target.ID, time.Duration) (Tab, error)
 	OpenNewTab(time.Duration) (Tab, error)
-	OpenNewIncognitoTab(time.Duration) (Tab, error)
+	OpenNewTabPaused(string, time.Duration) (PausedTab, error)
+	NewContext(*ContextOpts, time.Duration) (BrowserContext, error)
 	CloseTab(Tab, time.Duration) error
+	CloseTabWithOpts(Tab, CloseTabOpts, time.Duration) error
+	ExtensionTargets(time.Duration) ([]target.Info, error)
+	OpenExtensionTarget(string, time.Duration) (Tab, error)
+	ServiceWorkers(time.Duration) ([]target.Info, error)
+	ProcessOutput() string
+	OnCrash(CrashHandler)
+	Healthy(context.Context) error
+	Version(context.Context) (*BrowserVersion, error)
+	MemoryUsage(context.Context) (*MemoryUsage, error)
+	MonitorMemory(MemoryMonitorOpts) func()
+	Events(EventsOpts) (<-chan BrowserEvent, func())
 }
 
 func NewChrome() Chrome {
 	return &chrome{}
 }
 
-
 type chrome struct {
 	// command object to manage chrome process
 	command *exec.Cmd
@@ -38,18 +58,64 @@ type chrome struct {
 	conn *rpcc.Conn
 	// browser client
 	client *cdp.Client
+	// pipe connection to chrome process when launched with LaunchOpts.SetPipe
+	pipe *pipe
+	// flat sessions of the tabs, all tabs share the browser connection instead of opening a connection each
+	sessions *flatSessions
+	// options the browser was launched with, used to relaunch it after a crash
+	opts *LaunchOpts
+	// closed once the chrome process exits
+	exited chan struct{}
+	// error the chrome process exited with
+	exitErr error
+	// serializes launching and terminating the browser, including the relaunch after a crash
+	launchMu sync.Mutex
+	// guards terminating and crashHandlers
+	mu sync.Mutex
+	// set when the browser is being terminated so that the exit is not reported as a crash
+	terminating bool
+	// handlers invoked when the browser or one of its tabs crashes
+	crashHandlers []CrashHandler
+	// cancels the browser event streams
+	cancelEvents context.CancelFunc
+	// parent of the contexts of the calls to the browser and its tabs, canceled once the browser is terminated
+	lifetimeCtx context.Context
+	// cancels lifetimeCtx
+	cancelLifetime context.CancelFunc
+	// most recent output of chrome process
+	output *ringBuffer
+	// browser started by the launcher of the launch options, nil for local processes
+	launched LaunchedBrowser
+	// host and port the devtools protocol is exposed on, unless launched with a pipe
+	devtoolsAddr string
+	// url of the browser target the connection was made to, unless launched with a pipe
+	browserURL string
+	// profile directory the browser was launched with, if set with --user-data-dir
+	userDataDir string
+	// virtual display of headful chrome launched with LaunchOpts.SetVirtualDisplay
+	xvfb *xvfb
+	// learns the websocket url of the browser when launched with LaunchOpts.SetActivePortReadiness
+	activePort *activePortWatcher
+	// process group of chrome process and its child processes
+	processGroup *processGroup
+	// version of the browser, fetched on first use
+	version *BrowserVersion
+	// guards tabs
+	tabsMu sync.Mutex
+	// tabs of the browser by their target id, notified when their target is destroyed
+	tabs map[target.ID][]*tab
+	// serializes opening paused tabs, new targets are paused while the browser auto attaches to them
+	pauseMu sync.Mutex
+	// guards subscribers
+	eventsMu sync.RWMutex
+	// subscribers of the browser events
+	subscribers []*eventSubscriber
 }
 
-func (c *chrome) Launch(opts *LaunchOpts) (Tab, error) {
-	// if port is not specified, default to 9222
-	if opts.port == nil {
-		c.port = Int(9222)
-	} else {
-		c.port = opts.port
-	}
-
-	// prepare default arguments
-	defaultArguments := []string{
+// DefaultArguments returns the arguments the browser is launched with unless replaced with
+// LaunchOpts.ReplaceDefaultArguments. The devtools port or pipe argument is always added
+func DefaultArguments() []string {
+	return []string{
 		"--disable-background-networking",
 		"--disable-backgrounding-occluded-windows",
 		"--disable-background-timer-throttling",
@@ -78,10 +144,99 @@ func (c *chrome) Launch(opts *LaunchOpts) (Tab, error) {
 		"--no-first-run",
 		"--no-sandbox",
 		"--password-store=basic",
-		fmt.Sprintf("--remote-debugging-port=%v", IntValue(c.port)),
 		"--safebrowsing-disable-auto-update",
 		"--use-mock-keychain",
 	}
+}
+
+func (c *chrome) Launch(opts *LaunchOpts) (Tab, error) {
+	return c.LaunchContext(context.Background(), opts)
+}
+
+// LaunchContext launches the browser like Launch, giving up once the context is done. A browser which was started but
+// is not ready by then is terminated
+func (c *chrome) LaunchContext(ctx context.Context, opts *LaunchOpts) (Tab, error) {
+	c.launchMu.Lock()
+	defer c.launchMu.Unlock()
+
+	return c.launchTraced(ctx, opts)
+}
+
+// launchTraced launches the browser within a span and records the launch metrics. Must be called with launchMu held
+func (c *chrome) launchTraced(ctx context.Context, opts *LaunchOpts) (Tab, error) {
+	c.opts = opts
+
+	start := time.Now()
+	ctx, span := c.tracer().Start(ctx, "chrome.Launch", trace.WithAttributes(
+		attribute.Bool("chrome.headless", opts.headless),
+		attribute.Bool("chrome.pipe", opts.pipe),
+	))
+
+	tab, err := c.launch(ctx, opts)
+	c.metrics().ObserveLaunch(time.Since(start), err)
+	endSpan(span, err)
+
+	return tab, err
+}
+
+func (c *chrome) launch(ctx context.Context, opts *LaunchOpts) (Tab, error) {
+	c.mu.Lock()
+	c.terminating = false
+	c.mu.Unlock()
+
+	// calls still in-flight against a previous process of the browser are aborted
+	if c.cancelLifetime != nil {
+		c.cancelLifetime()
+	}
+	c.lifetimeCtx, c.cancelLifetime = context.WithCancel(context.Background())
+
+	c.pipe = nil
+	c.sessions = nil
+	c.version = nil
+	c.browserURL = ""
+
+	// if port is not specified, pick a free one so that multiple browsers can run alongside each other
+	if opts.pipe {
+		c.port = nil
+	} else if IntValue(opts.port) == 0 {
+		port, err := freePort()
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to find a free port", err.Error())
+			return nil, err
+		}
+		c.port = Int(port)
+	} else {
+		c.port = opts.port
+	}
+
+	// prepare default arguments
+	defaultArguments := DefaultArguments()
+	if opts.defaultArguments != nil {
+		defaultArguments = append([]string(nil), opts.defaultArguments...)
+	}
+
+	// expose devtools protocol either over a pipe or on a port
+	if opts.pipe {
+		defaultArguments = append(defaultArguments, "--remote-debugging-pipe")
+	} else {
+		defaultArguments = append(defaultArguments, fmt.Sprintf("--remote-debugging-port=%v", IntValue(c.port)))
+	}
+
+	// drop the default arguments which are disabled or overridden by additional arguments of the same flag
+	defaultArguments = filterDefaultArguments(defaultArguments, opts)
+
+	// load unpacked extensions, while keeping the other extensions disabled
+	if len(opts.extensions) > 0 {
+		extensions := strings.Join(opts.extensions, ",")
+		defaultArguments = append(defaultArguments,
+			"--load-extension="+extensions,
+			"--disable-extensions-except="+extensions,
+		)
+	}
+
+	if opts.allowMixedContent {
+		defaultArguments = append(defaultArguments, "--allow-running-insecure-content")
+	}
 
 	// if additional arguments are specified, use them alongside the default ones
 	if opts.arguments != nil {
@@ -92,57 +247,288 @@ func (c *chrome) Launch(opts *LaunchOpts) (Tab, error) {
 	if opts.headless {
 		defaultArguments = append(defaultArguments, "--headless")
 	}
+	c.userDataDir = userDataDir(defaultArguments)
 
-	// create command with chrome path and arguments
-	c.command = exec.Command(opts.path, defaultArguments...)
+	// capture stdout and stderr of chrome process, forwarding them to the output of launch options if any
+	c.output = newRingBuffer(processOutputSize)
+	var output io.Writer = c.output
+	if opts.output != nil {
+		output = io.MultiWriter(c.output, opts.output)
+	}
 
-	// launch chrome process
-	err := c.command.Start()
+	// learn when the browser is ready from the devtools active port file and the output of chrome instead of polling
+	c.activePort = nil
+	activePortFile := ""
+	if opts.activePortReadiness && !opts.pipe {
+		c.activePort = newActivePortWatcher()
+		output = io.MultiWriter(output, c.activePort)
+
+		// the file of a browser started by a launcher is out of reach
+		if opts.launcher == nil {
+			activePortFile = activePortPath(defaultArguments)
+		}
+		if activePortFile != "" {
+			if err := removeStaleActivePort(activePortFile); err != nil {
+				log.Println("go-chrome-framework error: unable to remove devtools active port file", err.Error())
+				return nil, err
+			}
+		}
+	}
+
+	c.command = nil
+	c.launched = nil
+	c.processGroup = nil
+
+	if opts.launcher != nil {
+		if opts.pipe {
+			return nil, errLauncherPipe
+		}
+
+		// the launcher starts the browser, e.g. in a container, and tells where its devtools protocol is exposed
+		if err := c.startLauncher(ctx, opts, defaultArguments, output); err != nil {
+			return nil, err
+		}
+	} else if err := c.startProcess(opts, defaultArguments, output); err != nil {
+		return nil, err
+	}
+
+	if activePortFile != "" {
+		go c.activePort.watchFile(ctx, activePortFile, c.exited)
+	}
+
+	tab, err := c.prepare(ctx, opts)
 	if err != nil {
-		log.Println("go-chrome-framework error: unable to launch chrome", err.Error())
+		// a browser which failed to become ready is not left running
+		_ = c.terminate()
+		c.closeTabs()
 		return nil, err
 	}
 
+	return tab, nil
+}
+
+// prepare connects to the started browser and prepares its first tab according to the launch options
+func (c *chrome) prepare(ctx context.Context, opts *LaunchOpts) (Tab, error) {
 	// attempt to connect with chrome over dev tools protocol
-	tab, err := c.connect(120 * time.Second)
+	tab, err := c.connect(ctx, c.connectTimeout())
 	if err != nil {
 		log.Println("go-chrome-framework error: unable to connect to browser devtools protocol", err.Error())
 		return nil, err
 	}
 
-	return tab, err
+	// report crashed and closed tabs
+	if err = c.watchTargets(c.connectTimeout()); err != nil {
+		log.Println("go-chrome-framework error: unable to watch for crashed and closed tabs", err.Error())
+		return nil, err
+	}
+
+	if tab, err = c.firstTab(tab, opts.firstTabPolicy, c.connectTimeout()); err != nil {
+		return nil, err
+	}
+
+	if err = c.applyFilterList(tab, c.connectTimeout()); err != nil {
+		return nil, err
+	}
+
+	if opts.startURL != "" {
+		if _, err = tab.Navigate(opts.startURL, 0); err != nil {
+			log.Println("go-chrome-framework error: unable to navigate first tab to start url", err.Error())
+			return nil, err
+		}
+	}
+
+	return tab, nil
 }
 
-func (c *chrome) Wait() {
-	err := c.command.Wait()
+// startProcess starts chrome as a local process with the arguments, writing its output to output
+func (c *chrome) startProcess(opts *LaunchOpts, arguments []string, output io.Writer) error {
+	// run headful chrome on a virtual display when the host has none
+	env, arguments := c.startVirtualDisplay(opts, arguments)
+
+	// create command with chrome path and arguments
+	c.command = exec.Command(opts.path, arguments...)
+
+	// run chrome process in the given working directory and environment, on top of the environment of this process
+	c.command.Dir = opts.dir
+	if len(env) > 0 {
+		c.command.Env = os.Environ()
+		for _, name := range sortedKeys(env) {
+			c.command.Env = append(c.command.Env, name+"="+env[name])
+		}
+	}
+
+	c.command.Stdout = output
+	c.command.Stderr = output
+
+	// pass the pipe to chrome process as file descriptors 3 and 4
+	var browserFiles []*os.File
+	if opts.pipe {
+		var err error
+		c.pipe, browserFiles, err = newPipe()
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to create pipe", err.Error())
+			return err
+		}
+		c.command.ExtraFiles = browserFiles
+	}
+
+	// launch chrome process in its own process group
+	prepareProcessGroup(c.command)
+	err := c.command.Start()
+
+	// the browser ends of the pipe belong to chrome process now
+	for _, file := range browserFiles {
+		closeRes(file)
+	}
+
 	if err != nil {
-		log.Println("go-chrome-framework error: premature exit", err.Error())
+		c.stopVirtualDisplay()
+		log.Println("go-chrome-framework error: unable to launch chrome", err.Error())
+		return err
+	}
+
+	c.processGroup, err = newProcessGroup(c.command)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to create process group", err.Error())
+	}
+
+	// report the exit of the chrome process
+	c.exited = make(chan struct{})
+	go c.monitor(c.command.Wait, c.exited)
+
+	c.devtoolsAddr = fmt.Sprintf("localhost:%v", IntValue(c.port))
+
+	return nil
+}
+
+func (c *chrome) Wait() {
+	// handle scenario when someone waits for a browser that never launched
+	if c.exited == nil {
+		return
+	}
+
+	<-c.exited
+	if c.exitErr != nil {
+		log.Println("go-chrome-framework error: premature exit", c.exitErr.Error())
 	}
 }
 
 func (c *chrome) Terminate() error {
+	c.launchMu.Lock()
+	err := c.terminate()
+	tabs := c.takeTabs()
+	c.launchMu.Unlock()
+
+	// the pages of the tabs go away along with the browser. The close handlers run without the lock, as they may
+	// terminate or launch the browser in turn
+	markTabsClosed(tabs)
+
+	return err
+}
+
+// terminate stops the browser, leaving its tabs to the caller. Must be called with launchMu held
+func (c *chrome) terminate() error {
+	c.mu.Lock()
+	c.terminating = true
+	c.mu.Unlock()
+	if c.cancelEvents != nil {
+		c.cancelEvents()
+	}
+
+	// abort the in-flight calls of the browser and its tabs instead of letting them run until their timeouts expire
+	if c.cancelLifetime != nil {
+		c.cancelLifetime()
+	}
+
+	// kill the whole process tree so that no renderer or gpu processes are left behind
+	if c.processGroup != nil {
+		processGroup := c.processGroup
+		c.processGroup = nil
+
+		if err := processGroup.kill(); err != nil {
+			log.Println("go-chrome-framework error: unable to kill browser process group", err.Error())
+		}
+	}
+
+	// browsers started by a launcher are stopped by it, e.g. by removing their container
+	if c.launched != nil {
+		return c.launched.Kill()
+	}
+
 	// handle scenario when someone tries to terminate a browser that never launched
-	if c.command.Process != nil {
-		return c.command.Process.Kill()
+	if c.command != nil && c.command.Process != nil {
+		err := c.command.Process.Kill()
+		if err != nil && c.processExited() {
+			// already reaped as part of the process group
+			return nil
+		}
+		return err
 	}
 
 	return nil
 }
 
-func (c *chrome) OpenTab(targetID target.ID, timeout time.Duration) Tab {
-	_, cancel := context.WithTimeout(context.Background(), timeout)
+// lifetime returns the context the calls to the browser are derived from, canceled once the browser is terminated
+func (c *chrome) lifetime() context.Context {
+	if c.lifetimeCtx == nil {
+		return context.Background()
+	}
+
+	return c.lifetimeCtx
+}
+
+// processExited reports whether chrome process has exited
+func (c *chrome) processExited() bool {
+	if c.exited == nil {
+		return false
+	}
+
+	select {
+	case <-c.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+// OpenTab returns a tab controlling the page with the target id. The target must exist, and the tab is connected
+// before it is returned so that the page can be controlled right away
+func (c *chrome) OpenTab(targetID target.ID, timeout time.Duration) (Tab, error) {
+	ctx, cancel := context.WithTimeout(c.lifetime(), timeout)
 	defer cancel()
-	// wrap the tab in an object and return
-	tab := new(tab)
 
-	tab.id = targetID
-	tab.port = c.port
+	targets, err := c.client.Target.GetTargets(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to get list of targets", err.Error())
+		return nil, err
+	}
+
+	var targetInfo *target.Info
+	for i := range targets.TargetInfos {
+		if targets.TargetInfos[i].TargetID == targetID {
+			targetInfo = &targets.TargetInfos[i]
+			break
+		}
+	}
+
+	if targetInfo == nil {
+		return nil, fmt.Errorf("go-chrome-framework: no target found with id %v", targetID)
+	}
 
-	return tab
+	// wrap the tab in an object and connect it over the browser connection
+	tab := c.newTab(targetID)
+	if targetInfo.BrowserContextID != nil {
+		tab.browserContextID = *targetInfo.BrowserContextID
+	}
+	if err = tab.ensureConnected(timeout); err != nil {
+		return nil, err
+	}
+
+	return c.publicTab(tab), nil
 }
 
 func (c *chrome) OpenNewTab(timeout time.Duration) (Tab, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(c.lifetime(), timeout)
 	defer cancel()
 
 	// create new target (tab)
@@ -153,80 +539,134 @@ func (c *chrome) OpenNewTab(timeout time.Duration) (Tab, error) {
 	}
 
 	// wrap the tab in an object and return
-	tab := new(tab)
+	tab := c.newTab(createTarget.TargetID)
+	if err = c.applyFilterList(tab, timeout); err != nil {
+		return nil, err
+	}
 
-	tab.id = createTarget.TargetID
-	tab.port = c.port
+	return c.publicTab(tab), nil
+}
 
-	return tab, nil
+// applyFilterList blocks the requests of the tab matching the filter list the browser was launched with, if any
+func (c *chrome) applyFilterList(tab Tab, timeout time.Duration) error {
+	if c.opts == nil || c.opts.filterList == nil {
+		return nil
+	}
+
+	if err := tab.SetFilterList(c.opts.filterList, timeout); err != nil {
+		log.Println("go-chrome-framework error: unable to apply filter list", err.Error())
+		return err
+	}
+
+	return nil
 }
 
-func (c *chrome) OpenNewIncognitoTab(timeout time.Duration) (Tab, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+func (c *chrome) NewContext(opts *ContextOpts, timeout time.Duration) (BrowserContext, error) {
+	ctx, cancel := context.WithTimeout(c.lifetime(), timeout)
 	defer cancel()
 
-	// create an empty browser context similar to incognito profile
-	createCtx, err := c.client.Target.CreateBrowserContext(ctx, target.NewCreateBrowserContextArgs())
-	if err != nil {
-		log.Println("go-chrome-framework error: unable to create browser context for new incognito tab", err.Error())
-		return nil, err
+	if opts == nil {
+		opts = NewContextOpts()
 	}
 
-	// create new target (tab) based on above incognito profile
-	createTarget, err := c.client.Target.CreateTarget(
-		ctx,
-		target.NewCreateTargetArgs("about:blank").
-			SetBrowserContextID(createCtx.BrowserContextID),
-	)
+	// create an empty browser context similar to incognito profile
+	createCtxArgs := target.NewCreateBrowserContextArgs()
+	if opts.proxyServer != "" {
+		createCtxArgs.SetProxyServer(opts.proxyServer)
+	}
+	if opts.proxyBypassList != "" {
+		createCtxArgs.SetProxyBypassList(opts.proxyBypassList)
+	}
 
+	createCtx, err := c.client.Target.CreateBrowserContext(ctx, createCtxArgs)
 	if err != nil {
-		log.Println("go-chrome-framework error: unable to create new incognito tab", err.Error())
+		log.Println("go-chrome-framework error: unable to create browser context", err.Error())
 		return nil, err
 	}
 
-	// wrap the tab in an object and return
-	tab := new(tab)
+	// wrap the browser context in an object and return
+	browserContext := new(browserContext)
 
-	tab.id = createTarget.TargetID
-	tab.port = c.port
+	browserContext.id = createCtx.BrowserContextID
+	browserContext.browser = c
+	browserContext.ignoreCertificateErrors = opts.ignoreCertificateErrors
 
-	return tab, nil
+	return browserContext, nil
 }
 
 func (c *chrome) CloseTab(tab Tab, timeout time.Duration) error {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(c.lifetime(), timeout)
 	defer cancel()
 
 	_, err := c.client.Target.CloseTarget(ctx, target.NewCloseTargetArgs(tab.GetTargetID()))
 	return err
 }
 
-func (c *chrome) connect(timeout time.Duration) (Tab, error) {
+func (c *chrome) connect(ctx context.Context, timeout time.Duration) (Tab, error) {
+	ctx, span := c.tracer().Start(ctx, "chrome.connect")
+
 	// prepare timeout context to cancel in case of a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	tab := new(tab)
 
-	rt := retry.NewRetrier(5, 100*time.Millisecond, time.Second)
-	err := rt.RunContext(ctx, func(ctx context.Context) error {
-		// use the devtool to create a Page Target
-		version, err := devtool.New(fmt.Sprintf("http://localhost:%v", IntValue(c.port))).Version(ctx)
-		if err != nil {
-			log.Println("go-chrome-framework error: unable to connect to browser over devtools protocol", err.Error())
-			return err
-		}
+	// a failed attempt closes the pipe along with its connection, and chrome reads no other pipe, hence connecting over
+	// a pipe is not retried
+	retry := retryAlways
+	if c.pipe != nil {
+		retry = retryNever
+	}
 
-		// Initiate a new RPC connection to the chrome DevTools Protocol targetInfo.
-		c.conn, err = rpcc.DialContext(ctx, version.WebSocketDebuggerURL)
-		if err != nil {
-			log.Println("go-chrome-framework error: unable to initiate a new rpc connection to chrome", err.Error())
-			return err
+	err := c.retryOpts().run(ctx, retry, func(ctx context.Context) (err error) {
+		// the connection of a failed attempt is closed before the next attempt, along with its sessions
+		defer func() {
+			if err != nil && c.conn != nil {
+				closeRes(c.conn)
+				c.conn = nil
+			}
+		}()
+
+		// tabs attach to their targets over the browser connection, see flatSessions
+		c.sessions = newFlatSessions(c.metrics(), c.recorder())
+
+		if c.pipe != nil {
+			// talk to chrome over the pipe it was launched with
+			c.conn, err = rpcc.DialContext(ctx, "pipe",
+				rpcc.WithDialer(func(ctx context.Context, addr string) (io.ReadWriteCloser, error) {
+					return c.pipe, nil
+				}),
+				rpcc.WithCodec(c.sessions.codec(newPipeFraming)),
+			)
+			if err != nil {
+				log.Println("go-chrome-framework error: unable to initiate a new rpc connection to chrome over pipe", err.Error())
+				return err
+			}
+		} else {
+			webSocketURL, err := c.webSocketURL(ctx)
+			if err != nil {
+				log.Println("go-chrome-framework error: unable to connect to browser over devtools protocol", err.Error())
+				return err
+			}
+
+			// Initiate a new RPC connection to the chrome DevTools Protocol targetInfo.
+			c.conn, err = rpcc.DialContext(ctx, webSocketURL, rpcc.WithCodec(c.sessions.codec(newWebsocketFraming)))
+			if err != nil {
+				log.Println("go-chrome-framework error: unable to initiate a new rpc connection to chrome", err.Error())
+				return err
+			}
+			c.browserURL = webSocketURL
 		}
 
 		// browser client
 		c.client = cdp.NewClient(c.conn)
 
+		// tabs are reached through sessions multiplexed over the browser connection
+		if err = c.sessions.start(c.client); err != nil {
+			log.Println("go-chrome-framework error: unable to watch sessions", err.Error())
+			return err
+		}
+
 		// as chrome launches with a new tab already opened, query the browser for a list of available targets to connect to
 		targets, err := c.client.Target.GetTargets(ctx)
 		if err != nil {
@@ -239,8 +679,7 @@ func (c *chrome) connect(timeout time.Duration) (Tab, error) {
 			// we want to connect to a page and not other target like service worker etc
 			if targetInfo.Type == "page" {
 				// wrap target in an object
-				tab.id = targetInfo.TargetID
-				tab.port = c.port
+				tab = c.newTab(targetInfo.TargetID)
 
 				break
 			}
@@ -248,11 +687,115 @@ func (c *chrome) connect(timeout time.Duration) (Tab, error) {
 
 		return nil
 	})
+	endSpan(span, err)
 	if err != nil {
 		return nil, err
 	}
 
-	return tab, err
+	return c.publicTab(tab), err
+}
+
+// webSocketURL returns the url of the browser target once the browser is ready, either learnt from the devtools
+// active port file and the output of chrome or by polling the http endpoint
+func (c *chrome) webSocketURL(ctx context.Context) (string, error) {
+	if c.activePort != nil {
+		webSocketURL, err := c.activePort.wait(ctx, c.exited)
+		if err != nil {
+			return "", err
+		}
+
+		return c.launchedURL(webSocketURL), nil
+	}
+
+	version, err := devtool.New("http://" + c.devtoolsAddr).Version(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return c.launchedURL(version.WebSocketDebuggerURL), nil
+}
+
+// filterDefaultArguments removes the default arguments disabled through launch options, as well as the ones whose flag
+// is also present in the additional arguments so that the additional argument takes precedence
+func filterDefaultArguments(defaultArguments []string, opts *LaunchOpts) []string {
+	removed := make(map[string]bool)
+	for _, argument := range opts.removedArguments {
+		removed[flagName(argument)] = true
+	}
+	for _, argument := range opts.arguments {
+		removed[flagName(argument)] = true
+	}
+
+	if opts.enableSandbox {
+		removed["--no-sandbox"] = true
+	}
+	if opts.strictTLS {
+		removed["--ignore-certificate-errors"] = true
+	}
+	if len(opts.extensions) > 0 {
+		removed["--disable-extensions"] = true
+	}
+
+	filtered := make([]string, 0, len(defaultArguments))
+	for _, argument := range defaultArguments {
+		if !removed[flagName(argument)] {
+			filtered = append(filtered, argument)
+		}
+	}
+
+	return filtered
+}
+
+// sortedKeys returns the keys of the map in ascending order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// flagName returns the flag of a command line argument without its value, e.g. --window-size for --window-size=800,600
+func flagName(argument string) string {
+	if i := strings.Index(argument, "="); i >= 0 {
+		return argument[:i]
+	}
+
+	return argument
+}
+
+// newTab wraps the target in a tab of this browser
+func (c *chrome) newTab(targetID target.ID) *tab {
+	t := new(tab)
+
+	t.id = targetID
+	t.port = c.port
+	t.browser = c
+	t.ctx, t.cancel = context.WithCancel(c.lifetime())
+
+	c.tabsMu.Lock()
+	if c.tabs == nil {
+		c.tabs = make(map[target.ID][]*tab)
+	}
+	c.tabs[targetID] = append(c.tabs[targetID], t)
+	c.tabsMu.Unlock()
+
+	c.metrics().TabOpened()
+
+	return t
+}
+
+// freePort asks the kernel for a free ephemeral port on the loopback interface
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer closeRes(listener)
+
+	return listener.Addr().(*net.TCPAddr).Port, nil
 }
 
 func closeRes(close io.Closer) {
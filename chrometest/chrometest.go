@@ -0,0 +1,458 @@
+// Package chrometest provides in-memory fakes of chrome.Chrome and chrome.Tab, so that code depending on the framework
+// can be unit tested without launching a browser. Responses to navigations, html, javascript and screenshots are
+// programmed per url, and the calls made are recorded for assertions. Methods the fakes do not implement panic
+package chrometest
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mafredri/cdp/protocol/runtime"
+	"github.com/mafredri/cdp/protocol/target"
+	chrome "go.ajitem.com/gcf/v3"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrUnhandled is wrapped by the errors of calls no response was programmed for
+var ErrUnhandled = errors.New("go-chrome-framework: no response programmed")
+
+// Response programs the page of a url
+type Response struct {
+	// Status of the document, defaults to 200
+	Status int
+	// HTML returned by GetHTML
+	HTML string
+	// Screenshot is the image returned by the screenshot methods, as encoded bytes
+	Screenshot []byte
+	// Results of javascript expressions evaluated in the page, by expression. The values are marshaled to json, an
+	// error value is returned as the error of the evaluation instead
+	Results map[string]interface{}
+	// Err fails the navigation to the url
+	Err error
+}
+
+// Call is a method called on a fake
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// calls records the calls of a fake
+type calls struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+func (c *calls) record(method string, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls made so far, in the order they were made
+func (c *calls) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Call{}, c.calls...)
+}
+
+// Tab is a fake chrome.Tab. Navigate loads the response programmed for the url, GetHTML, Exec, Evaluate and the
+// screenshot methods answer from the loaded response
+type Tab struct {
+	chrome.Tab
+	calls
+
+	id        target.ID
+	mu        sync.Mutex
+	responses map[string]Response
+	// results of commands sent with SendCommand, by command
+	commands  map[string]json.RawMessage
+	url       string
+	current   *Response
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewTab returns a fake tab with an empty page loaded
+func NewTab() *Tab {
+	return &Tab{
+		id:        target.ID(strconv.FormatInt(time.Now().UnixNano(), 36)),
+		responses: make(map[string]Response),
+		commands:  make(map[string]json.RawMessage),
+		url:       "about:blank",
+		current:   &Response{},
+		closed:    make(chan struct{}),
+	}
+}
+
+// Handle programs the response of the url, replacing any response programmed before
+func (t *Tab) Handle(url string, response Response) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.responses[url] = response
+}
+
+// SetResponse replaces the currently loaded page without navigating, e.g. to change the html after a click
+func (t *Tab) SetResponse(response Response) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.current = &response
+}
+
+// URL returns the url of the loaded page
+func (t *Tab) URL() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.url
+}
+
+func (t *Tab) page() *Response {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.current
+}
+
+func (t *Tab) Navigate(url string, timeout time.Duration) (*chrome.NavigationResult, error) {
+	t.record("Navigate", url, timeout)
+	return t.navigate(url)
+}
+
+func (t *Tab) NavigateWithOpts(url string, opts *chrome.NavigateOpts, timeout time.Duration) (*chrome.NavigationResult, error) {
+	t.record("NavigateWithOpts", url, opts, timeout)
+	return t.navigate(url)
+}
+
+func (t *Tab) navigate(url string) (*chrome.NavigationResult, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	response, ok := t.responses[url]
+	if !ok {
+		return nil, fmt.Errorf("%w: navigation to %v", ErrUnhandled, url)
+	}
+	if response.Err != nil {
+		return nil, response.Err
+	}
+
+	status := response.Status
+	if status == 0 {
+		status = 200
+	}
+
+	t.url = url
+	t.current = &response
+
+	return &chrome.NavigationResult{URL: url, StatusCode: status, MimeType: "text/html"}, nil
+}
+
+func (t *Tab) GetHTML(timeout time.Duration) (string, error) {
+	t.record("GetHTML", timeout)
+	return t.page().HTML, nil
+}
+
+func (t *Tab) Exec(javascript string, timeout time.Duration) (*runtime.EvaluateReply, error) {
+	t.record("Exec", javascript, timeout)
+
+	value, err := t.result(javascript)
+	if err != nil {
+		return nil, err
+	}
+
+	return &runtime.EvaluateReply{Result: runtime.RemoteObject{Type: remoteType(value), Value: value}}, nil
+}
+
+func (t *Tab) Evaluate(expression string, result interface{}, timeout time.Duration) error {
+	t.record("Evaluate", expression, result, timeout)
+
+	value, err := t.result(expression)
+	if err != nil || result == nil {
+		return err
+	}
+
+	return json.Unmarshal(value, result)
+}
+
+// result returns the programmed result of the expression as json
+func (t *Tab) result(expression string) (json.RawMessage, error) {
+	value, ok := t.page().Results[expression]
+	if !ok {
+		return nil, fmt.Errorf("%w: evaluation of %v", ErrUnhandled, expression)
+	}
+	if err, ok := value.(error); ok {
+		return nil, err
+	}
+
+	return json.Marshal(value)
+}
+
+// remoteType returns the javascript type of the json value
+func remoteType(value json.RawMessage) string {
+	switch {
+	case len(value) == 0 || string(value) == "null":
+		return "object"
+	case value[0] == '"':
+		return "string"
+	case value[0] == 't' || value[0] == 'f':
+		return "boolean"
+	case value[0] == '{' || value[0] == '[':
+		return "object"
+	default:
+		return "number"
+	}
+}
+
+// HandleCommand programs the result of sending the command with the params, see SendCommand
+func (t *Tab) HandleCommand(method string, params interface{}, result interface{}) error {
+	key, err := commandKey(method, params)
+	if err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.commands[key] = value
+	return nil
+}
+
+func (t *Tab) SendCommand(method string, params interface{}, result interface{}, timeout time.Duration) error {
+	t.record("SendCommand", method, params, result, timeout)
+
+	key, err := commandKey(method, params)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	value, ok := t.commands[key]
+	t.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: command %v", ErrUnhandled, key)
+	}
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(value, result)
+}
+
+// commandKey identifies the command by its method and params, the params are marshaled with their keys sorted so that
+// equal params match regardless of how they were built
+func commandKey(method string, params interface{}) (string, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+
+	var canonical interface{}
+	if err = json.Unmarshal(data, &canonical); err != nil {
+		return "", err
+	}
+
+	if data, err = json.Marshal(canonical); err != nil {
+		return "", err
+	}
+
+	return method + " " + string(data), nil
+}
+
+func (t *Tab) CaptureScreenshot(opts chrome.ScreenshotOpts, timeout time.Duration) (string, error) {
+	t.record("CaptureScreenshot", opts, timeout)
+
+	format := opts.Format
+	if format == "" {
+		format = "png"
+	}
+
+	return fmt.Sprintf("data:image/%v;base64,%v", format, base64.StdEncoding.EncodeToString(t.page().Screenshot)), nil
+}
+
+func (t *Tab) CaptureScreenshotTo(w io.Writer, opts chrome.ScreenshotOpts, timeout time.Duration) error {
+	t.record("CaptureScreenshotTo", w, opts, timeout)
+
+	_, err := w.Write(t.page().Screenshot)
+	return err
+}
+
+func (t *Tab) CaptureScreenshotFile(path string, opts chrome.ScreenshotOpts, timeout time.Duration) error {
+	t.record("CaptureScreenshotFile", path, opts, timeout)
+
+	return os.WriteFile(path, t.page().Screenshot, 0644)
+}
+
+func (t *Tab) GetTargetID() target.ID {
+	return t.id
+}
+
+func (t *Tab) SetDefaultTimeout(timeout time.Duration) {
+	t.record("SetDefaultTimeout", timeout)
+}
+
+func (t *Tab) SetDefaultNavigationTimeout(timeout time.Duration) {
+	t.record("SetDefaultNavigationTimeout", timeout)
+}
+
+func (t *Tab) SetTraceContext(ctx context.Context) {}
+
+func (t *Tab) Close(ctx context.Context) error {
+	t.record("Close")
+	t.closeOnce.Do(func() {
+		close(t.closed)
+	})
+
+	return nil
+}
+
+func (t *Tab) Closed() <-chan struct{} {
+	return t.closed
+}
+
+// Browser is a fake chrome.Chrome whose tabs are fake tabs
+type Browser struct {
+	chrome.Chrome
+	calls
+
+	// NewTab creates the tabs opened by the browser, defaults to NewTab. Set it to program the responses of tabs
+	// before they are handed to the code under test
+	NewTab func() *Tab
+
+	mu         sync.Mutex
+	tabs       []*Tab
+	terminated chan struct{}
+	once       sync.Once
+}
+
+// NewBrowser returns a fake browser
+func NewBrowser() *Browser {
+	return &Browser{terminated: make(chan struct{})}
+}
+
+// Tabs returns the tabs opened so far which were not closed
+func (b *Browser) Tabs() []*Tab {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]*Tab{}, b.tabs...)
+}
+
+func (b *Browser) open() *Tab {
+	newTab := b.NewTab
+	if newTab == nil {
+		newTab = NewTab
+	}
+
+	tab := newTab()
+
+	b.mu.Lock()
+	b.tabs = append(b.tabs, tab)
+	b.mu.Unlock()
+
+	return tab
+}
+
+func (b *Browser) Launch(opts *chrome.LaunchOpts) (chrome.Tab, error) {
+	b.record("Launch", opts)
+	return b.open(), nil
+}
+
+func (b *Browser) LaunchContext(ctx context.Context, opts *chrome.LaunchOpts) (chrome.Tab, error) {
+	b.record("LaunchContext", opts)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return b.open(), nil
+}
+
+func (b *Browser) LaunchWithResult(ctx context.Context, opts *chrome.LaunchOpts) (*chrome.LaunchResult, error) {
+	b.record("LaunchWithResult", opts)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return &chrome.LaunchResult{Tab: b.open()}, nil
+}
+
+func (b *Browser) OpenNewTab(timeout time.Duration) (chrome.Tab, error) {
+	b.record("OpenNewTab", timeout)
+	return b.open(), nil
+}
+
+func (b *Browser) OpenPage(timeout time.Duration) (chrome.Page, error) {
+	b.record("OpenPage", timeout)
+	return b.open(), nil
+}
+
+func (b *Browser) CloseTab(tab chrome.Tab, timeout time.Duration) error {
+	b.record("CloseTab", tab, timeout)
+	return b.closeTab(tab)
+}
+
+func (b *Browser) CloseTabWithOpts(tab chrome.Tab, opts chrome.CloseTabOpts, timeout time.Duration) error {
+	b.record("CloseTabWithOpts", tab, opts, timeout)
+	return b.closeTab(tab)
+}
+
+// closeTab removes the tab from the open tabs and closes it
+func (b *Browser) closeTab(tab chrome.Tab) error {
+	b.mu.Lock()
+	for i, t := range b.tabs {
+		if chrome.Tab(t) == tab {
+			b.tabs = append(b.tabs[:i], b.tabs[i+1:]...)
+			break
+		}
+	}
+	b.mu.Unlock()
+
+	return tab.Close(context.Background())
+}
+
+func (b *Browser) Terminate() error {
+	b.record("Terminate")
+	b.once.Do(func() {
+		for _, tab := range b.Tabs() {
+			_ = tab.Close(context.Background())
+		}
+		close(b.terminated)
+	})
+
+	return nil
+}
+
+func (b *Browser) Wait() {
+	<-b.terminated
+}
+
+func (b *Browser) Healthy(ctx context.Context) error {
+	select {
+	case <-b.terminated:
+		return errors.New("go-chrome-framework: browser terminated")
+	default:
+		return nil
+	}
+}
+
+var (
+	_ chrome.Chrome  = (*Browser)(nil)
+	_ chrome.Browser = (*Browser)(nil)
+	_ chrome.Tab     = (*Tab)(nil)
+	_ chrome.Page    = (*Tab)(nil)
+)
@@ -0,0 +1,162 @@
+package chrometest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/mafredri/cdp/protocol/runtime"
+	chrome "go.ajitem.com/gcf/v3"
+	"io"
+)
+
+// NewReplayTab returns a fake tab answering from a recording of a tab written by chrome.LaunchOpts.SetRecorder, so that
+// an automation flow recorded once against a real browser can be replayed deterministically in unit tests. The pages
+// navigated to are programmed with the html, javascript results and screenshots the browser responded with while on
+// them, other commands sent with SendCommand are answered with their recorded results
+func NewReplayTab(r io.Reader) (*Tab, error) {
+	messages, err := chrome.ReadRecording(r)
+	if err != nil {
+		return nil, err
+	}
+
+	tab := NewTab()
+	if err = tab.replay(messages); err != nil {
+		return nil, err
+	}
+
+	return tab, nil
+}
+
+// recordedMessage is a request or a response of a recording
+type recordedMessage struct {
+	ID        int64           `json:"id"`
+	SessionID string          `json:"sessionId"`
+	Method    string          `json:"method"`
+	Params    json.RawMessage `json:"params"`
+	Result    json.RawMessage `json:"result"`
+	Error     json.RawMessage `json:"error"`
+}
+
+// requestKey identifies a request by its session and id
+type requestKey struct {
+	sessionID string
+	id        int64
+}
+
+// replay programs the tab from the responses of the recorded requests
+func (t *Tab) replay(messages []chrome.RecordedMessage) error {
+	requests := make(map[requestKey]recordedMessage)
+
+	url := "about:blank"
+	responses := map[string]*Response{url: {}}
+	var order []string
+
+	for _, recorded := range messages {
+		var message recordedMessage
+		if err := json.Unmarshal(recorded.Message, &message); err != nil {
+			return err
+		}
+
+		key := requestKey{sessionID: message.SessionID, id: message.ID}
+		if recorded.Sent {
+			requests[key] = message
+			continue
+		}
+
+		// events carry no id, failed commands are left unhandled
+		request, ok := requests[key]
+		if message.ID == 0 || !ok || message.Error != nil {
+			continue
+		}
+		delete(requests, key)
+
+		page := responses[url]
+		switch request.Method {
+		case "Page.navigate":
+			var params struct {
+				URL string `json:"url"`
+			}
+			var result struct {
+				ErrorText string `json:"errorText"`
+			}
+			if err := unmarshalAll(request.Params, &params, message.Result, &result); err != nil {
+				return err
+			}
+
+			if responses[params.URL] == nil {
+				responses[params.URL] = &Response{}
+				order = append(order, params.URL)
+			}
+			if result.ErrorText != "" {
+				responses[params.URL].Err = errors.New(result.ErrorText)
+				continue
+			}
+			url = params.URL
+		case "Runtime.evaluate":
+			var params struct {
+				Expression string `json:"expression"`
+			}
+			var result runtime.EvaluateReply
+			if err := unmarshalAll(request.Params, &params, message.Result, &result); err != nil {
+				return err
+			}
+
+			if page.Results == nil {
+				page.Results = make(map[string]interface{})
+			}
+			if result.ExceptionDetails != nil {
+				page.Results[params.Expression] = &chrome.JavaScriptError{Details: result.ExceptionDetails}
+			} else {
+				page.Results[params.Expression] = result.Result.Value
+			}
+		case "DOM.getOuterHTML":
+			var result struct {
+				OuterHTML string `json:"outerHTML"`
+			}
+			if err := json.Unmarshal(message.Result, &result); err != nil {
+				return err
+			}
+			page.HTML = result.OuterHTML
+		case "Page.captureScreenshot":
+			var result struct {
+				Data string `json:"data"`
+			}
+			if err := json.Unmarshal(message.Result, &result); err != nil {
+				return err
+			}
+
+			data, err := base64.StdEncoding.DecodeString(result.Data)
+			if err != nil {
+				return err
+			}
+			page.Screenshot = data
+		default:
+			var params interface{}
+			if len(request.Params) > 0 {
+				if err := json.Unmarshal(request.Params, &params); err != nil {
+					return err
+				}
+			}
+
+			if err := t.HandleCommand(request.Method, params, message.Result); err != nil {
+				return err
+			}
+		}
+	}
+
+	t.SetResponse(*responses["about:blank"])
+	for _, url := range order {
+		t.Handle(url, *responses[url])
+	}
+
+	return nil
+}
+
+// unmarshalAll unmarshals the params and the result of a request
+func unmarshalAll(params json.RawMessage, paramsValue interface{}, result json.RawMessage, resultValue interface{}) error {
+	if err := json.Unmarshal(params, paramsValue); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(result, resultValue)
+}
@@ -0,0 +1,142 @@
+package chrome
+
+import (
+	"context"
+	"fmt"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/browser"
+	"github.com/mafredri/cdp/protocol/emulation"
+	"github.com/mafredri/cdp/protocol/input"
+	"log"
+	"time"
+)
+
+// clipboardFieldID is the id of the textarea used to copy and paste when the async clipboard api is unavailable
+const clipboardFieldID = "__go_chrome_framework_clipboard"
+
+// clipboardFieldScript inserts a focused textarea holding the text, selected so that it can be copied or pasted into
+const clipboardFieldScript = `((id, text) => {
+	const field = document.createElement('textarea');
+	field.id = id;
+	field.value = text;
+	field.style.position = 'fixed';
+	field.style.opacity = '0';
+	document.body.appendChild(field);
+	field.focus();
+	field.select();
+})(%v, %v)`
+
+// clipboardRemoveScript removes the textarea and returns its value
+const clipboardRemoveScript = `((id) => {
+	const field = document.getElementById(id);
+	const text = field.value;
+	field.remove();
+	return text;
+})(%v)`
+
+// SetClipboard writes the text to the clipboard. The async clipboard api is used after granting the clipboard
+// permissions, falling back to copying the text from a temporary field with key events where the api is unavailable
+func (t *tab) SetClipboard(text string, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if err := t.prepareClipboard(ctx); err == nil {
+		if err = t.evaluate(ctx, "navigator.clipboard.writeText("+jsString(text)+")", nil); err == nil {
+			return nil
+		}
+	}
+
+	if _, err := t.clipboardField(ctx, text, "copy"); err != nil {
+		log.Println("go-chrome-framework error: unable to write clipboard", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// ReadClipboard returns the text held by the clipboard. The async clipboard api is used after granting the clipboard
+// permissions, falling back to pasting into a temporary field with key events where the api is unavailable
+func (t *tab) ReadClipboard(timeout time.Duration) (string, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return "", err
+	}
+
+	if err := t.prepareClipboard(ctx); err == nil {
+		var text string
+		if err = t.evaluate(ctx, "navigator.clipboard.readText()", &text); err == nil {
+			return text, nil
+		}
+	}
+
+	text, err := t.clipboardField(ctx, "", "paste")
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to read clipboard", err.Error())
+		return "", err
+	}
+
+	return text, nil
+}
+
+// prepareClipboard grants the clipboard permissions and emulates focus, the async clipboard api rejects calls from
+// pages without focus
+func (t *tab) prepareClipboard(ctx context.Context) error {
+	permissions := []browser.PermissionType{browser.PermissionTypeClipboardReadWrite, browser.PermissionTypeClipboardSanitizedWrite}
+	return t.call(ctx, func(client *cdp.Client) error {
+		err := client.Browser.GrantPermissions(ctx, newGrantPermissionsArgs(t.browserContextID, "", permissions))
+		if err != nil {
+			return err
+		}
+
+		return client.Emulation.SetFocusEmulationEnabled(ctx, emulation.NewSetFocusEmulationEnabledArgs(true))
+	})
+}
+
+// clipboardField executes the editing command, "copy" or "paste", on a temporary field holding the text and returns
+// the text the field holds afterwards
+func (t *tab) clipboardField(ctx context.Context, text, command string) (string, error) {
+	if err := t.evaluate(ctx, fmt.Sprintf(clipboardFieldScript, jsString(clipboardFieldID), jsString(text)), nil); err != nil {
+		return "", err
+	}
+
+	client, err := t.currentClient()
+	if err != nil {
+		return "", err
+	}
+
+	key, code, virtualKeyCode := "c", "KeyC", 67
+	if command == "paste" {
+		key, code, virtualKeyCode = "v", "KeyV", 86
+	}
+
+	// the commands of the key event are executed by the browser like the shortcut, regardless of the platform
+	for _, eventType := range []string{"keyDown", "keyUp"} {
+		keyArgs := input.NewDispatchKeyEventArgs(eventType).
+			SetKey(key).
+			SetCode(code).
+			SetWindowsVirtualKeyCode(virtualKeyCode).
+			SetModifiers(2)
+		if eventType == "keyDown" {
+			keyArgs.SetCommands([]string{command})
+		}
+
+		if err := client.Input.DispatchKeyEvent(ctx, keyArgs); err != nil {
+			return "", err
+		}
+	}
+
+	var value string
+	if err := t.evaluate(ctx, fmt.Sprintf(clipboardRemoveScript, jsString(clipboardFieldID)), &value); err != nil {
+		return "", err
+	}
+
+	return value, nil
+}
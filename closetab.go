@@ -0,0 +1,107 @@
+package chrome
+
+import (
+	"context"
+	"errors"
+	"github.com/mafredri/cdp/protocol/page"
+	"log"
+	"time"
+)
+
+// ErrCloseCanceled is returned by CloseTabWithOpts when leaving the page is declined in its beforeunload dialog
+var ErrCloseCanceled = errors.New("go-chrome-framework: closing tab canceled by beforeunload dialog")
+
+// BeforeUnloadHandler decides whether the page is left when a beforeunload handler asks for confirmation, returning
+// true leaves the page. The message is the one of the dialog, browsers usually show a generic one instead
+type BeforeUnloadHandler func(message string) bool
+
+// CloseTabWithOpts closes the tab, optionally running the beforeunload handlers of its page. Pages asking for
+// confirmation in a beforeunload dialog are left unless OnBeforeUnload declines, in which case the tab stays open and
+// ErrCloseCanceled is returned
+func (c *chrome) CloseTabWithOpts(tab Tab, opts CloseTabOpts, timeout time.Duration) error {
+	if !opts.RunBeforeUnload {
+		return c.CloseTab(tab, timeout)
+	}
+
+	t, ok := internalTab(tab)
+	if !ok {
+		return c.CloseTab(tab, timeout)
+	}
+
+	return t.closeRunningBeforeUnload(opts.OnBeforeUnload, timeout)
+}
+
+// closeRunningBeforeUnload closes the page with Page.close, which runs its beforeunload handlers, and answers the
+// beforeunload dialogs until the target is destroyed
+func (t *tab) closeRunningBeforeUnload(handler BeforeUnloadHandler, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.browser.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	client, err := t.currentClient()
+	if err != nil {
+		return err
+	}
+
+	dialogOpening, err := client.Page.JavascriptDialogOpening(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to open javascript dialog opening client", err.Error())
+		return err
+	}
+	defer closeRes(dialogOpening)
+
+	if err = client.Page.Enable(ctx); err != nil {
+		log.Println("go-chrome-framework error: unable to enable page domain", err.Error())
+		return err
+	}
+
+	if err = client.Page.Close(ctx); err != nil {
+		log.Println("go-chrome-framework error: unable to close page", err.Error())
+		return err
+	}
+
+	for {
+		select {
+		case <-t.Closed():
+			return t.disconnect()
+		case <-dialogOpening.Ready():
+			dialog, err := dialogOpening.Recv()
+			if err != nil {
+				return err
+			}
+
+			// dialogs other than beforeunload cannot be opened while the page unloads
+			if dialog.Type != page.DialogTypeBeforeunload {
+				continue
+			}
+
+			leave := handler == nil || handler(dialog.Message)
+			if err = client.Page.HandleJavaScriptDialog(ctx, page.NewHandleJavaScriptDialogArgs(leave)); err != nil {
+				log.Println("go-chrome-framework error: unable to handle beforeunload dialog", err.Error())
+				return err
+			}
+
+			if !leave {
+				return ErrCloseCanceled
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// internalTab returns the tab behind a tab returned by the browser
+func internalTab(public Tab) (*tab, bool) {
+	switch t := public.(type) {
+	case *tab:
+		return t, true
+	case *artifactTab:
+		return t.tab, true
+	}
+
+	return nil, false
+}
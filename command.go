@@ -0,0 +1,32 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp/rpcc"
+	"log"
+	"time"
+)
+
+// SendCommand sends the protocol command with the params, marshalled to json, and unmarshals the result of the command
+// into result, e.g. SendCommand("Page.reload", map[string]interface{}{"ignoreCache": true}, nil, 0). Either params or
+// result may be nil. It gives access to commands which are not wrapped by GetClient yet
+func (t *tab) SendCommand(method string, params interface{}, result interface{}, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	conn := t.conn
+	t.mu.Unlock()
+
+	if err := rpcc.Invoke(ctx, method, params, result, conn); err != nil {
+		log.Println("go-chrome-framework error: unable to send command", method, err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp/protocol/page"
+	"log"
+	"time"
+)
+
+// loadedScript resolves once the document and its subresources have loaded
+const loadedScript = `new Promise(resolve => document.readyState === 'complete' ? resolve() : window.addEventListener('load', () => resolve()))`
+
+// SetContent replaces the document of the tab with the html and waits until its subresources, e.g. images and
+// stylesheets, have loaded. Relative urls in the html resolve against the url of the current document
+func (t *tab) SetContent(html string, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	client, err := t.currentClient()
+	if err != nil {
+		return err
+	}
+
+	frameTree, err := client.Page.GetFrameTree(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to get frame tree", err.Error())
+		return err
+	}
+
+	setContentArgs := page.NewSetDocumentContentArgs(frameTree.FrameTree.Frame.ID, html)
+	if err = client.Page.SetDocumentContent(ctx, setContentArgs); err != nil {
+		log.Println("go-chrome-framework error: unable to set document content", err.Error())
+		return err
+	}
+
+	if err = t.evaluate(ctx, loadedScript, nil); err != nil {
+		log.Println("go-chrome-framework error: unable to wait for document to load", err.Error())
+		return err
+	}
+
+	return nil
+}
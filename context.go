@@ -0,0 +1,109 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/browser"
+	"github.com/mafredri/cdp/protocol/target"
+	"log"
+	"time"
+)
+
+// BrowserContext is an isolated, incognito-like browser profile. Cookies, cache, permissions and proxy settings are
+// not shared with other browser contexts, and all the tabs opened in it are closed together when it is closed.
+type BrowserContext interface {
+	OpenNewTab(time.Duration) (Tab, error)
+	GrantPermissions(string, time.Duration, ...browser.PermissionType) error
+	GetBrowserContextID() browser.ContextID
+	Close(time.Duration) error
+}
+
+type browserContext struct {
+	// id of the browser context
+	id browser.ContextID
+	// browser which owns the browser context
+	browser *chrome
+	// whether the tabs opened in the browser context ignore certificate errors, nil leaves the browser default
+	ignoreCertificateErrors *bool
+}
+
+func (b *browserContext) OpenNewTab(timeout time.Duration) (Tab, error) {
+	ctx, cancel := context.WithTimeout(b.browser.lifetime(), timeout)
+	defer cancel()
+
+	// create new target (tab) inside the browser context
+	createTarget, err := b.browser.client.Target.CreateTarget(
+		ctx,
+		target.NewCreateTargetArgs("about:blank").
+			SetBrowserContextID(b.id),
+	)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to create new tab in browser context", err.Error())
+		return nil, err
+	}
+
+	// wrap the tab in an object and return
+	tab := b.browser.newTab(createTarget.TargetID)
+	tab.browserContextID = b.id
+
+	if b.ignoreCertificateErrors != nil {
+		ignore := *b.ignoreCertificateErrors
+		tab.AttachHook(func(client *cdp.Client) error {
+			ctx, cancel := context.WithTimeout(b.browser.lifetime(), DefaultTimeout)
+			defer cancel()
+
+			return setIgnoreCertificateErrors(ctx, client, ignore)
+		})
+	}
+
+	if err = b.browser.applyFilterList(tab, timeout); err != nil {
+		return nil, err
+	}
+
+	return b.browser.publicTab(tab), nil
+}
+
+func (b *browserContext) GrantPermissions(origin string, timeout time.Duration, permissions ...browser.PermissionType) error {
+	ctx, cancel := context.WithTimeout(b.browser.lifetime(), timeout)
+	defer cancel()
+
+	err := b.browser.client.Browser.GrantPermissions(ctx, newGrantPermissionsArgs(b.id, origin, permissions))
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to grant permissions to browser context", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// newGrantPermissionsArgs prepares arguments to grant permissions within a browser context. An empty origin grants the
+// permissions to all origins and an empty browser context id refers to the default browser context
+func newGrantPermissionsArgs(browserContextID browser.ContextID, origin string, permissions []browser.PermissionType) *browser.GrantPermissionsArgs {
+	args := browser.NewGrantPermissionsArgs(permissions)
+	if origin != "" {
+		args.SetOrigin(origin)
+	}
+	if browserContextID != "" {
+		args.SetBrowserContextID(browserContextID)
+	}
+
+	return args
+}
+
+func (b *browserContext) GetBrowserContextID() browser.ContextID {
+	return b.id
+}
+
+func (b *browserContext) Close(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(b.browser.lifetime(), timeout)
+	defer cancel()
+
+	// disposing the browser context closes all of its tabs along with it
+	err := b.browser.client.Target.DisposeBrowserContext(ctx, target.NewDisposeBrowserContextArgs(b.id))
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to dispose browser context", err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,379 @@
+package chrome
+
+import (
+	"context"
+	"errors"
+	"github.com/mafredri/cdp/protocol/css"
+	"github.com/mafredri/cdp/protocol/debugger"
+	"github.com/mafredri/cdp/protocol/profiler"
+	"log"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf16"
+)
+
+var (
+	// ErrCoverageStarted is returned when coverage collection is started on a tab which is already collecting it
+	ErrCoverageStarted = errors.New("go-chrome-framework: coverage already started")
+	// ErrCoverageNotStarted is returned when coverage collection is stopped on a tab which is not collecting it
+	ErrCoverageNotStarted = errors.New("go-chrome-framework: coverage not started")
+)
+
+// CoverageRange is a half open range [Start, End) of offsets into the text of a script or style sheet
+type CoverageRange struct {
+	Start int
+	End   int
+}
+
+// Coverage reports which parts of a script or style sheet were used while coverage was being collected
+type Coverage struct {
+	URL    string
+	Used   []CoverageRange
+	Unused []CoverageRange
+}
+
+type cssCoverage struct {
+	// cancels the style sheet added stream
+	cancel context.CancelFunc
+	// closed once the style sheet added stream is drained
+	done chan struct{}
+	// guards headers
+	mu sync.Mutex
+	// urls of the style sheets added while collecting coverage
+	headers map[css.StyleSheetID]string
+}
+
+func (t *tab) StartJSCoverage(timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if t.jsCoverage {
+		return ErrCoverageStarted
+	}
+
+	client, err := t.currentClient()
+	if err != nil {
+		return err
+	}
+
+	if err := client.Profiler.Enable(ctx); err != nil {
+		log.Println("go-chrome-framework error: unable to enable profiler domain", err.Error())
+		return err
+	}
+
+	// debugger domain is required to fetch the source of the covered scripts
+	if _, err := client.Debugger.Enable(ctx, nil); err != nil {
+		log.Println("go-chrome-framework error: unable to enable debugger domain", err.Error())
+		return err
+	}
+
+	startArgs := profiler.NewStartPreciseCoverageArgs().SetCallCount(false).SetDetailed(true)
+	if _, err := client.Profiler.StartPreciseCoverage(ctx, startArgs); err != nil {
+		log.Println("go-chrome-framework error: unable to start js coverage", err.Error())
+		return err
+	}
+
+	t.jsCoverage = true
+
+	return nil
+}
+
+func (t *tab) StopJSCoverage(timeout time.Duration) ([]Coverage, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	if !t.jsCoverage {
+		return nil, ErrCoverageNotStarted
+	}
+
+	// coverage stays started until the profiler is stopped, so that a failed call can be retried
+	client, err := t.currentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := client.Profiler.TakePreciseCoverage(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to take js coverage", err.Error())
+		return nil, err
+	}
+
+	var coverage []Coverage
+	for _, script := range reply.Result {
+		// skip anonymous scripts such as the ones evaluated by Exec
+		if script.URL == "" {
+			continue
+		}
+
+		source, err := client.Debugger.GetScriptSource(ctx, debugger.NewGetScriptSourceArgs(script.ScriptID))
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to get script source", err.Error())
+			return nil, err
+		}
+
+		var ranges []countedRange
+		for _, function := range script.Functions {
+			for _, r := range function.Ranges {
+				ranges = append(ranges, countedRange{start: r.StartOffset, end: r.EndOffset, count: r.Count})
+			}
+		}
+
+		coverage = append(coverage, newCoverage(script.URL, source.ScriptSource, ranges))
+	}
+
+	if err = client.Profiler.StopPreciseCoverage(ctx); err != nil {
+		log.Println("go-chrome-framework error: unable to stop js coverage", err.Error())
+		return nil, err
+	}
+	t.jsCoverage = false
+
+	if err = client.Debugger.Disable(ctx); err != nil {
+		log.Println("go-chrome-framework error: unable to disable debugger domain", err.Error())
+		return nil, err
+	}
+
+	if err = client.Profiler.Disable(ctx); err != nil {
+		log.Println("go-chrome-framework error: unable to disable profiler domain", err.Error())
+		return nil, err
+	}
+
+	return coverage, nil
+}
+
+func (t *tab) StartCSSCoverage(timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if t.cssCoverage != nil {
+		return ErrCoverageStarted
+	}
+
+	// style sheets are reported until coverage is stopped, hence the stream is not bound to the timeout
+	client, err := t.currentClient()
+	if err != nil {
+		return err
+	}
+
+	streamCtx, streamCancel := context.WithCancel(context.Background())
+
+	styleSheetAdded, err := client.CSS.StyleSheetAdded(streamCtx)
+	if err != nil {
+		streamCancel()
+		log.Println("go-chrome-framework error: unable to open style sheet added client", err.Error())
+		return err
+	}
+
+	// css domain depends on the dom domain
+	if err = client.DOM.Enable(ctx); err != nil {
+		streamCancel()
+		log.Println("go-chrome-framework error: unable to enable dom domain", err.Error())
+		return err
+	}
+
+	if err = client.CSS.Enable(ctx); err != nil {
+		streamCancel()
+		log.Println("go-chrome-framework error: unable to enable css domain", err.Error())
+		return err
+	}
+
+	if err = client.CSS.StartRuleUsageTracking(ctx); err != nil {
+		streamCancel()
+		log.Println("go-chrome-framework error: unable to start css coverage", err.Error())
+		return err
+	}
+
+	coverage := &cssCoverage{
+		cancel:  streamCancel,
+		done:    make(chan struct{}),
+		headers: make(map[css.StyleSheetID]string),
+	}
+
+	go func() {
+		defer close(coverage.done)
+		defer closeRes(styleSheetAdded)
+
+		for {
+			reply, err := styleSheetAdded.Recv()
+			if err != nil {
+				return
+			}
+
+			coverage.mu.Lock()
+			coverage.headers[reply.Header.StyleSheetID] = reply.Header.SourceURL
+			coverage.mu.Unlock()
+		}
+	}()
+
+	t.cssCoverage = coverage
+
+	return nil
+}
+
+func (t *tab) StopCSSCoverage(timeout time.Duration) ([]Coverage, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	if t.cssCoverage == nil {
+		return nil, ErrCoverageNotStarted
+	}
+
+	coverage := t.cssCoverage
+	t.cssCoverage = nil
+
+	client, err := t.currentClient()
+	if err != nil {
+		coverage.cancel()
+		<-coverage.done
+		return nil, err
+	}
+
+	reply, err := client.CSS.StopRuleUsageTracking(ctx)
+
+	// stop listening for new style sheets before reading the collected ones
+	coverage.cancel()
+	<-coverage.done
+
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to stop css coverage", err.Error())
+		return nil, err
+	}
+
+	ranges := make(map[css.StyleSheetID][]countedRange)
+	for _, usage := range reply.RuleUsage {
+		count := 0
+		if usage.Used {
+			count = 1
+		}
+
+		ranges[usage.StyleSheetID] = append(ranges[usage.StyleSheetID], countedRange{
+			start: int(usage.StartOffset),
+			end:   int(usage.EndOffset),
+			count: count,
+		})
+	}
+
+	var result []Coverage
+	for id, url := range coverage.headers {
+		// skip inline style sheets
+		if url == "" {
+			continue
+		}
+
+		text, err := client.CSS.GetStyleSheetText(ctx, css.NewGetStyleSheetTextArgs(id))
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to get style sheet text", err.Error())
+			return nil, err
+		}
+
+		result = append(result, newCoverage(url, text.Text, ranges[id]))
+	}
+
+	if err = client.CSS.Disable(ctx); err != nil {
+		log.Println("go-chrome-framework error: unable to disable css domain", err.Error())
+		return nil, err
+	}
+
+	return result, nil
+}
+
+type countedRange struct {
+	start int
+	end   int
+	count int
+}
+
+// newCoverage converts the possibly nested ranges reported by the browser into disjoint used and unused ranges. Offsets
+// reported by the browser are in UTF-16 code units
+func newCoverage(url string, text string, ranges []countedRange) Coverage {
+	coverage := Coverage{URL: url}
+
+	type point struct {
+		offset int
+		end    bool
+		r      countedRange
+	}
+
+	points := make([]point, 0, len(ranges)*2)
+	for _, r := range ranges {
+		points = append(points, point{offset: r.start, r: r}, point{offset: r.end, end: true, r: r})
+	}
+
+	sort.SliceStable(points, func(i, j int) bool {
+		a, b := points[i], points[j]
+		if a.offset != b.offset {
+			return a.offset < b.offset
+		}
+
+		// end points go before start points
+		if a.end != b.end {
+			return a.end
+		}
+
+		// among start points the longer range goes first, among end points the shorter one
+		aLength, bLength := a.r.end-a.r.start, b.r.end-b.r.start
+		if !a.end {
+			return aLength > bLength
+		}
+		return aLength < bLength
+	})
+
+	// the innermost range decides whether an offset was used
+	var counts []int
+	lastOffset := 0
+	for _, p := range points {
+		if len(counts) > 0 && lastOffset < p.offset && counts[len(counts)-1] > 0 {
+			if n := len(coverage.Used); n > 0 && coverage.Used[n-1].End == lastOffset {
+				coverage.Used[n-1].End = p.offset
+			} else {
+				coverage.Used = append(coverage.Used, CoverageRange{Start: lastOffset, End: p.offset})
+			}
+		}
+		lastOffset = p.offset
+
+		if p.end {
+			counts = counts[:len(counts)-1]
+		} else {
+			counts = append(counts, p.r.count)
+		}
+	}
+
+	// whatever is not used is unused
+	length := len(utf16.Encode([]rune(text)))
+	offset := 0
+	for _, used := range coverage.Used {
+		if used.Start > offset {
+			coverage.Unused = append(coverage.Unused, CoverageRange{Start: offset, End: used.Start})
+		}
+		offset = used.End
+	}
+	if offset < length {
+		coverage.Unused = append(coverage.Unused, CoverageRange{Start: offset, End: length})
+	}
+
+	return coverage
+}
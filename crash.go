@@ -0,0 +1,98 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp/protocol/target"
+	"log"
+)
+
+// Crash describes a crashed tab or an unexpected exit of the browser process
+type Crash struct {
+	// TargetID of the crashed tab, empty when the browser process exited
+	TargetID target.ID
+	// Status of the crashed renderer, e.g. "crashed" or "oom"
+	Status string
+	// Err is the error the browser process exited with
+	Err error
+	// Tab is the first tab of the relaunched browser when LaunchOpts.SetRelaunchOnCrash is enabled and the relaunch
+	// succeeds, nil otherwise
+	Tab Tab
+}
+
+// CrashHandler is invoked when the browser process exits unexpectedly or one of its tabs crashes
+type CrashHandler func(crash Crash)
+
+func (c *chrome) OnCrash(handler CrashHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.crashHandlers = append(c.crashHandlers, handler)
+}
+
+func (c *chrome) crashed(crash Crash) {
+	c.mu.Lock()
+	handlers := append([]CrashHandler{}, c.crashHandlers...)
+	c.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(crash)
+	}
+}
+
+// monitor waits for the chrome process to exit and reports the exit as a crash unless the browser is being terminated
+func (c *chrome) monitor(wait func() error, exited chan struct{}) {
+	err := wait()
+	c.stopVirtualDisplay()
+	c.exitErr = err
+	close(exited)
+
+	c.launchMu.Lock()
+	if c.exitExpected(exited) {
+		c.launchMu.Unlock()
+		return
+	}
+
+	log.Println("go-chrome-framework error: browser process exited unexpectedly", err)
+
+	// the calls and the tabs of the browser fail right away instead of waiting for their timeouts
+	if c.cancelEvents != nil {
+		c.cancelEvents()
+	}
+	if c.cancelLifetime != nil {
+		c.cancelLifetime()
+	}
+	tabs := c.takeTabs()
+	c.launchMu.Unlock()
+
+	markTabsClosed(tabs)
+
+	c.crashed(Crash{Err: err, Tab: c.relaunch(exited)})
+}
+
+// relaunch launches the browser again after its process exited if LaunchOpts.SetRelaunchOnCrash is enabled, unless it
+// was terminated or launched again by another caller meanwhile. It returns the first tab of the relaunched browser
+func (c *chrome) relaunch(exited chan struct{}) Tab {
+	c.launchMu.Lock()
+	defer c.launchMu.Unlock()
+
+	if c.opts == nil || !c.opts.relaunchOnCrash || c.exitExpected(exited) {
+		return nil
+	}
+
+	tab, err := c.launchTraced(context.Background(), c.opts)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to relaunch browser", err.Error())
+		return nil
+	}
+
+	return tab
+}
+
+// exitExpected reports whether the exit of the process is expected, as the browser is being terminated or a new
+// process was launched since. Must be called with launchMu held
+func (c *chrome) exitExpected(exited chan struct{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.terminating || c.exited != exited
+}
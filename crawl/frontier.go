@@ -0,0 +1,180 @@
+package crawl
+
+import (
+	chrome "go.ajitem.com/gcf/v3"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// FrontierOpts configures the urls a Frontier queues, see NewFrontier
+type FrontierOpts struct {
+	// MaxDepth is the number of links followed from the seeds, urls further away are dropped. 0 disables the limit
+	MaxDepth int
+	// MaxURLs is the number of urls queued in total, further urls are dropped. 0 disables the limit
+	MaxURLs int
+	// Domains restricts the crawl to the domains and their subdomains, e.g. "example.com" also allows
+	// "www.example.com". Every domain is allowed if empty
+	Domains []string
+	// SeedHosts restricts the crawl to the hosts of the seeds
+	SeedHosts bool
+	// FollowNofollow queues the links which are not to be followed as well
+	FollowNofollow bool
+	// Filter drops the urls it returns false for, e.g. to skip downloads
+	Filter func(url string) bool
+}
+
+// FrontierURL is a url queued by the frontier
+type FrontierURL struct {
+	URL string
+	// Depth is the number of links followed from a seed to the url, 0 for the seeds
+	Depth int
+	// Referrer is the url of the page linking to the url, empty for the seeds
+	Referrer string
+}
+
+// Frontier queues the urls of a breadth-first crawl. Urls are normalized with chrome.NormalizeURL and queued once, and
+// urls beyond the depth limit or outside the allowed domains are dropped. It is safe for concurrent use, e.g. by the
+// tabs of a TabPool taking the next url and adding the links they extract
+type Frontier struct {
+	opts  FrontierOpts
+	mu    sync.Mutex
+	queue []FrontierURL
+	seen  map[string]bool
+	// hosts of the seeds, if the crawl is restricted to them
+	seedHosts map[string]bool
+}
+
+// NewFrontier returns a frontier queueing the seeds
+func NewFrontier(opts FrontierOpts, seeds ...string) *Frontier {
+	f := &Frontier{
+		opts:      opts,
+		seen:      make(map[string]bool),
+		seedHosts: make(map[string]bool),
+	}
+
+	for _, seed := range seeds {
+		if u, err := url.Parse(seed); err == nil {
+			f.seedHosts[strings.ToLower(u.Hostname())] = true
+		}
+	}
+
+	for _, seed := range seeds {
+		f.Add(FrontierURL{URL: seed})
+	}
+
+	return f
+}
+
+// Add queues the url unless it was seen already or is not allowed, and reports whether it was queued
+func (f *Frontier) Add(u FrontierURL) bool {
+	normalized, err := chrome.NormalizeURL(u.URL)
+	if err != nil {
+		return false
+	}
+	u.URL = normalized
+
+	if !f.allowed(u) {
+		return false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.seen[normalized] || f.opts.MaxURLs > 0 && len(f.seen) >= f.opts.MaxURLs {
+		return false
+	}
+	f.seen[normalized] = true
+	f.queue = append(f.queue, u)
+
+	return true
+}
+
+// AddLinks queues the links extracted from the page one level deeper than the page, see Tab.ExtractLinks. It returns
+// the number of links queued
+func (f *Frontier) AddLinks(page FrontierURL, links []chrome.Link) int {
+	queued := 0
+	for _, link := range links {
+		if link.Nofollow && !f.opts.FollowNofollow {
+			continue
+		}
+
+		if f.Add(FrontierURL{URL: link.URL, Depth: page.Depth + 1, Referrer: page.URL}) {
+			queued++
+		}
+	}
+
+	return queued
+}
+
+// Next takes the next url to crawl, in the order the urls were queued. It reports false when the queue is empty
+func (f *Frontier) Next() (FrontierURL, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.queue) == 0 {
+		return FrontierURL{}, false
+	}
+
+	u := f.queue[0]
+	f.queue[0] = FrontierURL{}
+	f.queue = f.queue[1:]
+
+	return u, true
+}
+
+// Len returns the number of urls waiting to be crawled
+func (f *Frontier) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.queue)
+}
+
+// Seen reports whether the url was queued, including the urls which were taken already
+func (f *Frontier) Seen(rawURL string) bool {
+	normalized, err := chrome.NormalizeURL(rawURL)
+	if err != nil {
+		return false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.seen[normalized]
+}
+
+// allowed reports whether the normalized url passes the depth limit and the domain filters
+func (f *Frontier) allowed(u FrontierURL) bool {
+	if f.opts.MaxDepth > 0 && u.Depth > f.opts.MaxDepth {
+		return false
+	}
+
+	parsed, err := url.Parse(u.URL)
+	if err != nil {
+		return false
+	}
+	host := parsed.Hostname()
+
+	if f.opts.SeedHosts && !f.seedHosts[host] {
+		return false
+	}
+
+	if len(f.opts.Domains) > 0 && !inDomains(host, f.opts.Domains) {
+		return false
+	}
+
+	return f.opts.Filter == nil || f.opts.Filter(u.URL)
+}
+
+// inDomains reports whether the host is one of the domains or one of their subdomains
+func inDomains(host string, domains []string) bool {
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
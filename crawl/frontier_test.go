@@ -0,0 +1,170 @@
+package crawl
+
+import (
+	chrome "go.ajitem.com/gcf/v3"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFrontierAdd(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  FrontierOpts
+		seeds []string
+		adds  []FrontierURL
+		// urls queued in order, the seeds included
+		queued []string
+	}{
+		{
+			name:  "dedup of normalized urls",
+			seeds: []string{"https://example.com"},
+			adds: []FrontierURL{
+				{URL: "HTTPS://Example.com:443/#top"}, {URL: "https://example.com/a"}, {URL: "https://example.com/a#b"},
+			},
+			queued: []string{"https://example.com/", "https://example.com/a"},
+		},
+		{
+			name:   "invalid urls",
+			adds:   []FrontierURL{{URL: "mailto:someone@example.com"}, {URL: "/relative"}, {URL: "https://example.com/"}},
+			queued: []string{"https://example.com/"},
+		},
+		{
+			name:   "max depth",
+			opts:   FrontierOpts{MaxDepth: 1},
+			seeds:  []string{"https://example.com/"},
+			adds:   []FrontierURL{{URL: "https://example.com/1", Depth: 1}, {URL: "https://example.com/2", Depth: 2}},
+			queued: []string{"https://example.com/", "https://example.com/1"},
+		},
+		{
+			name:   "max urls",
+			opts:   FrontierOpts{MaxURLs: 2},
+			seeds:  []string{"https://example.com/"},
+			adds:   []FrontierURL{{URL: "https://example.com/1"}, {URL: "https://example.com/2"}},
+			queued: []string{"https://example.com/", "https://example.com/1"},
+		},
+		{
+			name:  "domains",
+			opts:  FrontierOpts{Domains: []string{"Example.com", ".example.org"}},
+			seeds: []string{"https://example.com/"},
+			adds: []FrontierURL{
+				{URL: "https://www.example.com/"}, {URL: "https://example.org/"}, {URL: "https://notexample.com/"},
+				{URL: "https://example.com.evil.net/"},
+			},
+			queued: []string{"https://example.com/", "https://www.example.com/", "https://example.org/"},
+		},
+		{
+			name:   "seed hosts",
+			opts:   FrontierOpts{SeedHosts: true},
+			seeds:  []string{"https://example.com/", "https://Docs.Example.org/"},
+			adds:   []FrontierURL{{URL: "https://docs.example.org/page"}, {URL: "https://www.example.com/"}},
+			queued: []string{"https://example.com/", "https://docs.example.org/", "https://docs.example.org/page"},
+		},
+		{
+			name: "filter",
+			opts: FrontierOpts{Filter: func(url string) bool {
+				return !strings.HasSuffix(url, ".zip")
+			}},
+			adds:   []FrontierURL{{URL: "https://example.com/a.zip"}, {URL: "https://example.com/a.html"}},
+			queued: []string{"https://example.com/a.html"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			frontier := NewFrontier(test.opts, test.seeds...)
+			for _, u := range test.adds {
+				frontier.Add(u)
+			}
+
+			var queued []string
+			for u, ok := frontier.Next(); ok; u, ok = frontier.Next() {
+				queued = append(queued, u.URL)
+			}
+			if !reflect.DeepEqual(queued, test.queued) {
+				t.Errorf("queued %v, want %v", queued, test.queued)
+			}
+		})
+	}
+}
+
+func TestFrontierAddLinks(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   FrontierOpts
+		links  []chrome.Link
+		queued []FrontierURL
+	}{
+		{
+			name:  "links are one level deeper",
+			links: []chrome.Link{{URL: "https://example.com/a"}, {URL: "https://example.com/"}},
+			queued: []FrontierURL{
+				{URL: "https://example.com/a", Depth: 2, Referrer: "https://example.com/page"},
+			},
+		},
+		{
+			name:   "nofollow",
+			links:  []chrome.Link{{URL: "https://example.com/a", Nofollow: true}},
+			queued: nil,
+		},
+		{
+			name:  "follow nofollow",
+			opts:  FrontierOpts{FollowNofollow: true},
+			links: []chrome.Link{{URL: "https://example.com/a", Nofollow: true}},
+			queued: []FrontierURL{
+				{URL: "https://example.com/a", Depth: 2, Referrer: "https://example.com/page"},
+			},
+		},
+		{
+			name:   "links beyond the max depth",
+			opts:   FrontierOpts{MaxDepth: 1},
+			links:  []chrome.Link{{URL: "https://example.com/a"}},
+			queued: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			frontier := NewFrontier(test.opts, "https://example.com/")
+			frontier.Next()
+
+			page := FrontierURL{URL: "https://example.com/page", Depth: 1}
+			if n := frontier.AddLinks(page, test.links); n != len(test.queued) {
+				t.Errorf("AddLinks() = %v, want %v", n, len(test.queued))
+			}
+
+			var queued []FrontierURL
+			for u, ok := frontier.Next(); ok; u, ok = frontier.Next() {
+				queued = append(queued, u)
+			}
+			if !reflect.DeepEqual(queued, test.queued) {
+				t.Errorf("queued %+v, want %+v", queued, test.queued)
+			}
+		})
+	}
+}
+
+func TestFrontierSeen(t *testing.T) {
+	frontier := NewFrontier(FrontierOpts{}, "https://example.com/a")
+	frontier.Next()
+
+	tests := []struct {
+		url  string
+		seen bool
+	}{
+		{"https://example.com/a", true},
+		{"HTTPS://EXAMPLE.COM/a#section", true},
+		{"https://example.com/b", false},
+		{"not a url", false},
+	}
+
+	for _, test := range tests {
+		if seen := frontier.Seen(test.url); seen != test.seen {
+			t.Errorf("Seen(%q) = %v, want %v", test.url, seen, test.seen)
+		}
+	}
+
+	if n := frontier.Len(); n != 0 {
+		t.Errorf("Len() = %v, want 0", n)
+	}
+}
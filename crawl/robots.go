@@ -0,0 +1,349 @@
+// Package crawl provides the building blocks of crawlers rendering pages with the browser: robots.txt rules and crawl
+// delays, sitemaps and a crawl frontier. It is optional, the browser does not depend on it
+package crawl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	chrome "go.ajitem.com/gcf/v3"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDisallowed is returned when navigating to a url disallowed by the robots.txt of its host
+var ErrDisallowed = errors.New("go-chrome-framework: url disallowed by robots.txt")
+
+// DefaultRobotsTTL is how long robots.txt files are cached unless Robots.TTL is set
+const DefaultRobotsTTL = 24 * time.Hour
+
+// maxRobotsSize is the size of robots.txt files beyond which their content is ignored
+const maxRobotsSize = 500 << 10
+
+// robotsRetryTTL is how long a robots.txt which failed with a server error is cached before it is fetched again
+const robotsRetryTTL = time.Minute
+
+// Robots fetches and caches the robots.txt files of the hosts being crawled and checks urls against their rules.
+// Hosts whose robots.txt is missing allow every url, hosts whose robots.txt fails with a server error disallow every
+// url until the file is fetched again a minute later
+type Robots struct {
+	// UserAgent is the product token the rules are looked up for, e.g. "MyCrawler". The rules for every crawler apply
+	// when robots.txt has none for it
+	UserAgent string
+	// Client fetches robots.txt, defaults to http.DefaultClient
+	Client *http.Client
+	// TTL is how long robots.txt files are cached, defaults to DefaultRobotsTTL
+	TTL time.Duration
+
+	mu sync.Mutex
+	// files by scheme and host, and by host name for HostDelay
+	hosts     map[string]*robotsFile
+	hostNames map[string]*robotsFile
+}
+
+// robotsFile holds the rules of a host for the user agent
+type robotsFile struct {
+	fetched time.Time
+	// ttl overrides Robots.TTL, e.g. for files which failed with a server error
+	ttl   time.Duration
+	rules []robotsRule
+	delay time.Duration
+	// sitemaps listed in robots.txt, regardless of the user agent
+	sitemaps []string
+}
+
+type robotsRule struct {
+	pattern string
+	allow   bool
+}
+
+// NewRobots returns robots.txt rules looked up for the user agent
+func NewRobots(userAgent string) *Robots {
+	return &Robots{UserAgent: userAgent}
+}
+
+// Allowed reports whether the robots.txt of the host of the url allows crawling the url
+func (r *Robots) Allowed(ctx context.Context, rawURL string) (bool, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false, err
+	}
+
+	file, err := r.file(ctx, u)
+	if err != nil {
+		return false, err
+	}
+
+	return file.allowed(u.RequestURI()), nil
+}
+
+// CrawlDelay returns the crawl delay the robots.txt of the host of the url asks for, 0 if none
+func (r *Robots) CrawlDelay(ctx context.Context, rawURL string) (time.Duration, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := r.file(ctx, u)
+	if err != nil {
+		return 0, err
+	}
+
+	return file.delay, nil
+}
+
+// Sitemaps returns the sitemaps listed in the robots.txt of the host of the url
+func (r *Robots) Sitemaps(ctx context.Context, rawURL string) ([]string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := r.file(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	return file.sitemaps, nil
+}
+
+// HostDelay returns the crawl delay of the host, to be used as chrome.SchedulerOpts.HostDelay. The robots.txt of the
+// host is fetched over https unless it was fetched already, a robots.txt which cannot be fetched has no delay
+func (r *Robots) HostDelay(host string) time.Duration {
+	r.mu.Lock()
+	file, ok := r.hostNames[host]
+	r.mu.Unlock()
+	if ok && r.fresh(file) {
+		return file.delay
+	}
+
+	delay, err := r.CrawlDelay(context.Background(), "https://"+host+"/")
+	if err != nil {
+		return 0
+	}
+
+	return delay
+}
+
+// Navigate navigates the tab to the url with the scheduler if robots.txt allows it, and fails with ErrDisallowed
+// otherwise. The scheduler may be nil to navigate right away
+func (r *Robots) Navigate(ctx context.Context, scheduler *chrome.NavigationScheduler, tab chrome.Tab, url string, opts *chrome.NavigateOpts) (*chrome.NavigationResult, error) {
+	allowed, err := r.Allowed(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("%w: %v", ErrDisallowed, url)
+	}
+
+	if scheduler == nil {
+		scheduler = chrome.NewNavigationScheduler(chrome.SchedulerOpts{})
+	}
+
+	return scheduler.Navigate(ctx, tab, url, opts)
+}
+
+// file returns the rules of the host of the url, fetching robots.txt unless cached
+func (r *Robots) file(ctx context.Context, u *url.URL) (*robotsFile, error) {
+	key := u.Scheme + "://" + u.Host
+
+	r.mu.Lock()
+	file, ok := r.hosts[key]
+	r.mu.Unlock()
+	if ok && r.fresh(file) {
+		return file, nil
+	}
+
+	file, err := r.fetch(ctx, key+"/robots.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if r.hosts == nil {
+		r.hosts = make(map[string]*robotsFile)
+		r.hostNames = make(map[string]*robotsFile)
+	}
+	r.hosts[key] = file
+	r.hostNames[u.Hostname()] = file
+	r.mu.Unlock()
+
+	return file, nil
+}
+
+// fresh reports whether the cached file has not expired yet
+func (r *Robots) fresh(file *robotsFile) bool {
+	ttl := file.ttl
+	if ttl <= 0 {
+		ttl = r.TTL
+	}
+	if ttl <= 0 {
+		ttl = DefaultRobotsTTL
+	}
+
+	return time.Since(file.fetched) < ttl
+}
+
+// fetch fetches and parses the robots.txt at the url
+func (r *Robots) fetch(ctx context.Context, robotsURL string) (*robotsFile, error) {
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode >= 500:
+		// the host may be overloaded, nothing is crawled until it recovers, which is checked again shortly
+		return &robotsFile{fetched: time.Now(), ttl: robotsRetryTTL, rules: []robotsRule{{pattern: "/"}}}, nil
+	case res.StatusCode >= 400:
+		return &robotsFile{fetched: time.Now()}, nil
+	}
+
+	file := parseRobots(io.LimitReader(res.Body, maxRobotsSize), r.UserAgent)
+	file.fetched = time.Now()
+
+	return file, nil
+}
+
+// parseRobots parses the rules of robots.txt for the product token of the user agent, falling back to the rules for
+// every crawler. Groups for the same user agent are merged
+func parseRobots(r io.Reader, userAgent string) *robotsFile {
+	token := strings.ToLower(strings.SplitN(userAgent, "/", 2)[0])
+
+	file := new(robotsFile)
+	var own, everyone robotsFile
+	var ownFound bool
+
+	// user agents of the group being parsed, a user-agent line after a rule starts a new group
+	var agents []string
+	inRules := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:i]))
+		value := strings.TrimSpace(line[i+1:])
+
+		switch key {
+		case "user-agent":
+			if inRules {
+				agents = nil
+				inRules = false
+			}
+			agents = append(agents, strings.ToLower(value))
+		case "allow", "disallow", "crawl-delay":
+			inRules = true
+			for _, agent := range agents {
+				var group *robotsFile
+				switch {
+				case token != "" && agent == token:
+					group = &own
+					ownFound = true
+				case agent == "*":
+					group = &everyone
+				default:
+					continue
+				}
+
+				switch key {
+				case "crawl-delay":
+					if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+						group.delay = time.Duration(seconds * float64(time.Second))
+					}
+				default:
+					// an empty disallow allows everything
+					if value != "" {
+						group.rules = append(group.rules, robotsRule{pattern: value, allow: key == "allow"})
+					}
+				}
+			}
+		case "sitemap":
+			file.sitemaps = append(file.sitemaps, value)
+		}
+	}
+
+	group := everyone
+	if ownFound {
+		group = own
+	}
+	file.rules = group.rules
+	file.delay = group.delay
+
+	return file
+}
+
+// allowed reports whether the rules allow the path, including its query. The longest matching rule decides, allow
+// rules win over disallow rules of the same length
+func (f *robotsFile) allowed(path string) bool {
+	allowed := true
+	longest := -1
+
+	for _, rule := range f.rules {
+		if !matchRobotsPattern(rule.pattern, path) {
+			continue
+		}
+
+		if len(rule.pattern) > longest || (len(rule.pattern) == longest && rule.allow) {
+			longest = len(rule.pattern)
+			allowed = rule.allow
+		}
+	}
+
+	return allowed
+}
+
+// matchRobotsPattern reports whether the path matches the pattern of a rule, in which * matches any characters and a
+// trailing $ anchors the pattern at the end of the path
+func matchRobotsPattern(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	parts := strings.Split(strings.TrimSuffix(pattern, "$"), "*")
+
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+
+	if len(parts) == 1 {
+		return !anchored || rest == ""
+	}
+
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+
+	last := parts[len(parts)-1]
+	if anchored {
+		return strings.HasSuffix(rest, last)
+	}
+
+	return strings.Contains(rest, last)
+}
@@ -0,0 +1,172 @@
+package crawl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		name      string
+		robots    string
+		userAgent string
+		rules     []robotsRule
+		delay     time.Duration
+		sitemaps  []string
+	}{
+		{
+			name:      "everyone",
+			robots:    "User-agent: *\nDisallow: /private\nAllow: /private/public\n",
+			userAgent: "gcf/3.0",
+			rules:     []robotsRule{{pattern: "/private"}, {pattern: "/private/public", allow: true}},
+		},
+		{
+			name:      "own group wins over everyone",
+			robots:    "User-agent: *\nDisallow: /\n\nUser-agent: GCF\nDisallow: /admin\nCrawl-delay: 2.5\n",
+			userAgent: "gcf/3.0",
+			rules:     []robotsRule{{pattern: "/admin"}},
+			delay:     2500 * time.Millisecond,
+		},
+		{
+			name:      "other crawlers are ignored",
+			robots:    "User-agent: other\nDisallow: /\n",
+			userAgent: "gcf",
+		},
+		{
+			name:      "groups for the same agent are merged",
+			robots:    "User-agent: gcf\nDisallow: /a\n\nUser-agent: gcf\nDisallow: /b\n",
+			userAgent: "gcf",
+			rules:     []robotsRule{{pattern: "/a"}, {pattern: "/b"}},
+		},
+		{
+			name:      "consecutive user agents share a group",
+			robots:    "User-agent: other\nUser-agent: gcf\nDisallow: /shared\n",
+			userAgent: "gcf",
+			rules:     []robotsRule{{pattern: "/shared"}},
+		},
+		{
+			name:      "comments, empty disallow and invalid delays",
+			robots:    "# comment\nUser-agent: * # everyone\nDisallow:\nCrawl-delay: soon\n",
+			userAgent: "gcf",
+		},
+		{
+			name:      "sitemaps apply to every crawler",
+			robots:    "Sitemap: https://example.com/sitemap.xml\nUser-agent: other\nSitemap: https://example.com/news.xml\n",
+			userAgent: "gcf",
+			sitemaps:  []string{"https://example.com/sitemap.xml", "https://example.com/news.xml"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := parseRobots(strings.NewReader(test.robots), test.userAgent)
+
+			if !reflect.DeepEqual(file.rules, test.rules) {
+				t.Errorf("rules = %+v, want %+v", file.rules, test.rules)
+			}
+			if file.delay != test.delay {
+				t.Errorf("delay = %v, want %v", file.delay, test.delay)
+			}
+			if !reflect.DeepEqual(file.sitemaps, test.sitemaps) {
+				t.Errorf("sitemaps = %v, want %v", file.sitemaps, test.sitemaps)
+			}
+		})
+	}
+}
+
+func TestRobotsFileAllowed(t *testing.T) {
+	file := &robotsFile{rules: []robotsRule{
+		{pattern: "/private"},
+		{pattern: "/private/public", allow: true},
+		{pattern: "/*.pdf$"},
+		{pattern: "/same"},
+		{pattern: "/same", allow: true},
+		{pattern: "/search?*q="},
+	}}
+
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{"/", true},
+		{"/private", false},
+		{"/private/page", false},
+		{"/private/public/page", true},
+		{"/files/report.pdf", false},
+		{"/files/report.pdf?download=1", true},
+		{"/same", true},
+		{"/search?lang=en&q=chrome", false},
+		{"/search", true},
+	}
+
+	for _, test := range tests {
+		if allowed := file.allowed(test.path); allowed != test.allowed {
+			t.Errorf("allowed(%q) = %v, want %v", test.path, allowed, test.allowed)
+		}
+	}
+}
+
+func TestRobotsServerError(t *testing.T) {
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		if fetches == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = w.Write([]byte("User-agent: *\nCrawl-delay: 2\n"))
+	}))
+	defer server.Close()
+
+	robots := &Robots{UserAgent: "test", Client: server.Client()}
+	if allowed, err := robots.Allowed(context.Background(), server.URL+"/page"); err != nil || allowed {
+		t.Fatalf("Allowed() = %v, %v, want false while the server fails", allowed, err)
+	}
+
+	// the failure is cached for the retry ttl only, not for the ttl of the robots.txt files
+	robots.hosts[server.URL].fetched = time.Now().Add(-robotsRetryTTL)
+	if allowed, err := robots.Allowed(context.Background(), server.URL+"/page"); err != nil || !allowed {
+		t.Fatalf("Allowed() = %v, %v, want true once the server recovered", allowed, err)
+	}
+
+	u, _ := url.Parse(server.URL)
+	if delay := robots.HostDelay(u.Hostname()); delay != 2*time.Second {
+		t.Errorf("HostDelay() = %v, want %v", delay, 2*time.Second)
+	}
+	if fetches != 2 {
+		t.Errorf("robots.txt fetched %v times, want 2", fetches)
+	}
+}
+
+func TestMatchRobotsPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"/", "/anything", true},
+		{"/dir", "/directory", true},
+		{"/dir", "/other/dir", false},
+		{"/dir$", "/dir", true},
+		{"/dir$", "/dir/", false},
+		{"/*.php", "/index.php", true},
+		{"/*.php", "/index.php?page=1", true},
+		{"/*.php$", "/index.php?page=1", false},
+		{"/a*b*c", "/axxbyyc", true},
+		{"/a*b*c", "/axxcyyb", false},
+		{"*", "/", true},
+	}
+
+	for _, test := range tests {
+		if match := matchRobotsPattern(test.pattern, test.path); match != test.match {
+			t.Errorf("matchRobotsPattern(%q, %q) = %v, want %v", test.pattern, test.path, match, test.match)
+		}
+	}
+}
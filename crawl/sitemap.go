@@ -0,0 +1,277 @@
+package crawl
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	chrome "go.ajitem.com/gcf/v3"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultConcurrency is the number of pages rendered at once unless SitemapOpts.Concurrency is set
+const DefaultConcurrency = 4
+
+// maxSitemapSize is the size of uncompressed sitemaps beyond which their content is ignored
+const maxSitemapSize = 50 << 20
+
+// maxSitemapDepth limits how deeply sitemap indexes may refer to further indexes
+const maxSitemapDepth = 3
+
+// SitemapURL is a url listed in a sitemap
+type SitemapURL struct {
+	Loc string `xml:"loc"`
+	// LastMod is the date the page was last modified at in W3C datetime format, if listed
+	LastMod string `xml:"lastmod"`
+}
+
+// sitemapDocument is either a set of urls or an index of further sitemaps
+type sitemapDocument struct {
+	URLs     []SitemapURL `xml:"url"`
+	Sitemaps []SitemapURL `xml:"sitemap"`
+}
+
+// ReadSitemap fetches the sitemap at the url and returns the urls it lists. The sitemaps listed by sitemap indexes are
+// read as well and gzip compressed sitemaps are decompressed. Urls listed more than once are returned once. The client
+// may be nil to use http.DefaultClient
+func ReadSitemap(ctx context.Context, client *http.Client, sitemapURL string) ([]SitemapURL, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var urls []SitemapURL
+	seen := make(map[string]bool)
+	if err := readSitemap(ctx, client, sitemapURL, 0, seen, &urls); err != nil {
+		return nil, err
+	}
+
+	return urls, nil
+}
+
+// readSitemap appends the urls listed by the sitemap to urls, following sitemap indexes up to maxSitemapDepth
+func readSitemap(ctx context.Context, client *http.Client, sitemapURL string, depth int, seen map[string]bool, urls *[]SitemapURL) error {
+	if depth > maxSitemapDepth {
+		return fmt.Errorf("go-chrome-framework: sitemap index %v nested too deeply", sitemapURL)
+	}
+
+	document, err := fetchSitemap(ctx, client, sitemapURL)
+	if err != nil {
+		return err
+	}
+
+	for _, u := range document.URLs {
+		if u.Loc == "" || seen[u.Loc] {
+			continue
+		}
+		seen[u.Loc] = true
+		*urls = append(*urls, u)
+	}
+
+	for _, sitemap := range document.Sitemaps {
+		if sitemap.Loc == "" || seen[sitemap.Loc] {
+			continue
+		}
+		seen[sitemap.Loc] = true
+
+		if err = readSitemap(ctx, client, sitemap.Loc, depth+1, seen, urls); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fetchSitemap fetches and decodes the sitemap at the url
+func fetchSitemap(ctx context.Context, client *http.Client, sitemapURL string) (*sitemapDocument, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sitemapURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		return nil, fmt.Errorf("go-chrome-framework: unable to fetch sitemap %v: %v", sitemapURL, res.Status)
+	}
+
+	// compressed sitemaps are recognized by their content, servers often send them without a content encoding
+	buffered := bufio.NewReader(res.Body)
+	var body io.Reader = buffered
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	document := new(sitemapDocument)
+	if err = xml.NewDecoder(io.LimitReader(body, maxSitemapSize)).Decode(document); err != nil {
+		return nil, fmt.Errorf("go-chrome-framework: unable to decode sitemap %v: %w", sitemapURL, err)
+	}
+
+	return document, nil
+}
+
+// SitemapOpts configures rendering the pages listed by sitemaps, see FromSitemap
+type SitemapOpts struct {
+	// Concurrency is the number of pages rendered at once, defaults to DefaultConcurrency. The size of the pool bounds
+	// it as well
+	Concurrency int
+	// Client fetches the sitemaps, defaults to http.DefaultClient
+	Client *http.Client
+	// Robots skips the urls disallowed by robots.txt, nothing is skipped if nil
+	Robots *Robots
+	// Scheduler spaces out the navigations to the same host, navigations start right away if nil
+	Scheduler *chrome.NavigationScheduler
+	// NavigateOpts the pages are navigated with, may be nil
+	NavigateOpts *chrome.NavigateOpts
+	// HTML captures the html of the pages
+	HTML bool
+	// Screenshot captures a screenshot of the pages with the options, no screenshot is captured if nil
+	Screenshot *chrome.ScreenshotOpts
+	// PageTimeout bounds rendering every page, including waiting for a tab and the turn of its navigation. Pages are
+	// only bound by the context if 0
+	PageTimeout time.Duration
+}
+
+// Page is a rendered page of a crawl
+type Page struct {
+	URL string
+	// Navigation is the result of navigating to the page
+	Navigation *chrome.NavigationResult
+	// HTML of the page, if SitemapOpts.HTML is set
+	HTML string
+	// Screenshot of the page, if SitemapOpts.Screenshot is set
+	Screenshot []byte
+	// Err is the error rendering the page, wrapping ErrDisallowed for urls disallowed by robots.txt
+	Err error
+}
+
+// PageHandler receives the rendered pages of a crawl, one at a time
+type PageHandler func(page *Page)
+
+// FromSitemap renders the pages listed in the sitemap at the url with the tabs of the pool and passes them to the
+// handler, e.g. to store their html for prerendering. Pages which fail to render are passed along with their error.
+// It returns once every page is handled, with an error if the sitemap cannot be read or the context is done
+func FromSitemap(ctx context.Context, sitemapURL string, pool *chrome.TabPool, opts SitemapOpts, handler PageHandler) error {
+	urls, err := ReadSitemap(ctx, opts.Client, sitemapURL)
+	if err != nil {
+		return err
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
+	}
+
+	scheduler := opts.Scheduler
+	if scheduler == nil {
+		scheduler = chrome.NewNavigationScheduler(chrome.SchedulerOpts{})
+	}
+
+	var wg sync.WaitGroup
+	var handlerMu sync.Mutex
+	slots := make(chan struct{}, concurrency)
+
+	for _, u := range urls {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			page := renderPage(ctx, pool, scheduler, opts, url)
+
+			handlerMu.Lock()
+			handler(page)
+			handlerMu.Unlock()
+		}(u.Loc)
+	}
+
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// renderPage navigates a tab of the pool to the url and captures the outputs of the page
+func renderPage(ctx context.Context, pool *chrome.TabPool, scheduler *chrome.NavigationScheduler, opts SitemapOpts, url string) *Page {
+	page := &Page{URL: url}
+
+	if opts.PageTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.PageTimeout)
+		defer cancel()
+	}
+
+	if opts.Robots != nil {
+		allowed, err := opts.Robots.Allowed(ctx, url)
+		if err != nil {
+			page.Err = err
+			return page
+		}
+		if !allowed {
+			page.Err = fmt.Errorf("%w: %v", ErrDisallowed, url)
+			return page
+		}
+	}
+
+	tab, err := pool.Acquire(ctx)
+	if err != nil {
+		page.Err = err
+		return page
+	}
+	defer pool.Release(tab)
+
+	page.Navigation, page.Err = scheduler.Navigate(ctx, tab, url, opts.NavigateOpts)
+	if page.Err != nil {
+		return page
+	}
+
+	if opts.HTML {
+		if page.HTML, page.Err = tab.GetHTML(timeout(ctx)); page.Err != nil {
+			return page
+		}
+	}
+
+	if opts.Screenshot != nil {
+		var screenshot bytes.Buffer
+		if page.Err = tab.CaptureScreenshotTo(&screenshot, *opts.Screenshot, timeout(ctx)); page.Err != nil {
+			return page
+		}
+		page.Screenshot = screenshot.Bytes()
+	}
+
+	return page
+}
+
+// timeout returns the time left until the deadline of the context
+func timeout(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0
+	}
+
+	// a timeout of zero would fall back to the default timeout of the tab instead of failing
+	if remaining := time.Until(deadline); remaining > 0 {
+		return remaining
+	}
+
+	return time.Nanosecond
+}
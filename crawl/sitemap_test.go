@@ -0,0 +1,154 @@
+package crawl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sitemapNamespace is the xml namespace of sitemaps
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+func urlset(locs ...string) string {
+	var document strings.Builder
+	fmt.Fprintf(&document, `<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="%v">`, sitemapNamespace)
+	for _, loc := range locs {
+		fmt.Fprintf(&document, "<url><loc>%v</loc><lastmod>2020-01-02</lastmod></url>", loc)
+	}
+	document.WriteString("</urlset>")
+
+	return document.String()
+}
+
+func sitemapIndex(locs ...string) string {
+	var document strings.Builder
+	fmt.Fprintf(&document, `<?xml version="1.0" encoding="UTF-8"?><sitemapindex xmlns="%v">`, sitemapNamespace)
+	for _, loc := range locs {
+		fmt.Fprintf(&document, "<sitemap><loc>%v</loc></sitemap>", loc)
+	}
+	document.WriteString("</sitemapindex>")
+
+	return document.String()
+}
+
+func gzipped(document string) string {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, _ = gz.Write([]byte(document))
+	_ = gz.Close()
+
+	return compressed.String()
+}
+
+func TestReadSitemap(t *testing.T) {
+	tests := []struct {
+		name string
+		// documents served by path, {{server}} is replaced with the url of the server
+		documents map[string]string
+		locs      []string
+		err       bool
+	}{
+		{
+			name:      "urlset",
+			documents: map[string]string{"/sitemap.xml": urlset("https://example.com/a", "https://example.com/b")},
+			locs:      []string{"https://example.com/a", "https://example.com/b"},
+		},
+		{
+			name:      "gzip",
+			documents: map[string]string{"/sitemap.xml": gzipped(urlset("https://example.com/a"))},
+			locs:      []string{"https://example.com/a"},
+		},
+		{
+			name: "index",
+			documents: map[string]string{
+				"/sitemap.xml":  sitemapIndex("{{server}}/pages.xml", "{{server}}/posts.xml.gz"),
+				"/pages.xml":    urlset("https://example.com/a", "https://example.com/b"),
+				"/posts.xml.gz": gzipped(urlset("https://example.com/b", "https://example.com/c")),
+			},
+			locs: []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"},
+		},
+		{
+			name: "nested index",
+			documents: map[string]string{
+				"/sitemap.xml": sitemapIndex("{{server}}/1.xml"),
+				"/1.xml":       sitemapIndex("{{server}}/2.xml"),
+				"/2.xml":       sitemapIndex("{{server}}/3.xml"),
+				"/3.xml":       urlset("https://example.com/deep"),
+			},
+			locs: []string{"https://example.com/deep"},
+		},
+		{
+			name: "index nested too deeply",
+			documents: map[string]string{
+				"/sitemap.xml": sitemapIndex("{{server}}/1.xml"),
+				"/1.xml":       sitemapIndex("{{server}}/2.xml"),
+				"/2.xml":       sitemapIndex("{{server}}/3.xml"),
+				"/3.xml":       sitemapIndex("{{server}}/4.xml"),
+				"/4.xml":       urlset("https://example.com/deep"),
+			},
+			err: true,
+		},
+		{
+			name: "index referring to itself",
+			documents: map[string]string{
+				"/sitemap.xml": sitemapIndex("{{server}}/sitemap.xml", "{{server}}/pages.xml"),
+				"/pages.xml":   urlset("https://example.com/a"),
+			},
+			locs: []string{"https://example.com/a"},
+		},
+		{
+			name:      "missing",
+			documents: map[string]string{},
+			err:       true,
+		},
+		{
+			name:      "malformed",
+			documents: map[string]string{"/sitemap.xml": "<urlset><url>"},
+			err:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var server *httptest.Server
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				document, ok := test.documents[r.URL.Path]
+				if !ok {
+					http.NotFound(w, r)
+					return
+				}
+
+				_, _ = w.Write([]byte(strings.Replace(document, "{{server}}", server.URL, -1)))
+			}))
+			defer server.Close()
+
+			urls, err := ReadSitemap(context.Background(), server.Client(), server.URL+"/sitemap.xml")
+			if test.err {
+				if err == nil {
+					t.Fatalf("ReadSitemap() = %v, want an error", urls)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadSitemap() = %v", err)
+			}
+
+			var locs []string
+			for _, u := range urls {
+				locs = append(locs, u.Loc)
+				if u.LastMod != "2020-01-02" {
+					t.Errorf("LastMod of %v = %q, want %q", u.Loc, u.LastMod, "2020-01-02")
+				}
+			}
+			if !reflect.DeepEqual(locs, test.locs) {
+				t.Errorf("ReadSitemap() = %v, want %v", locs, test.locs)
+			}
+		})
+	}
+}
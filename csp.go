@@ -0,0 +1,32 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/page"
+	"log"
+	"time"
+)
+
+// SetBypassCSP makes the browser ignore the content security policy of the pages of the tab, so that scripts injected
+// into pages with a strict policy run. Takes effect for documents loaded afterwards, see StripCSPMeta for policies
+// declared by meta elements of documents rewritten with RewriteDocument
+func (t *tab) SetBypassCSP(bypass bool, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.Page.SetBypassCSP(ctx, page.NewSetBypassCSPArgs(bypass))
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to set bypass csp", err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package chrome
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/mafredri/cdp/protocol/runtime"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// debugLogLimit is the number of console messages and failed requests retained for DebugDump
+const debugLogLimit = 500
+
+// ConsoleMessage is a message logged to the console of a page or an uncaught exception
+type ConsoleMessage struct {
+	// Type of the message, e.g. "log", "warning", "error", or "exception" for uncaught exceptions
+	Type string
+	Text string
+	// URL and Line of the script the message was logged from, if known
+	URL  string
+	Line int
+	Time time.Time
+}
+
+// debugLog retains the recent console messages and failed requests of a tab
+type debugLog struct {
+	mu       sync.Mutex
+	console  []ConsoleMessage
+	failures []RequestFailure
+}
+
+func (d *debugLog) addConsole(message ConsoleMessage) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.console) == debugLogLimit {
+		d.console = d.console[1:]
+	}
+	d.console = append(d.console, message)
+}
+
+func (d *debugLog) addFailure(failure RequestFailure) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.failures) == debugLogLimit {
+		d.failures = d.failures[1:]
+	}
+	d.failures = append(d.failures, failure)
+}
+
+// EnableDebugLog retains the most recent console messages, uncaught exceptions and failed requests of the tab, so that
+// DebugDump can include them
+func (t *tab) EnableDebugLog(timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	t.featureMu.Lock()
+	if t.debugLog != nil {
+		t.featureMu.Unlock()
+		return nil
+	}
+
+	// messages are retained as long as the connection lasts, hence the streams are not bound to the timeout
+	client, err := t.currentClient()
+	if err != nil {
+		t.featureMu.Unlock()
+		return err
+	}
+
+	consoleAPICalled, err := client.Runtime.ConsoleAPICalled(context.Background())
+	if err != nil {
+		t.featureMu.Unlock()
+		log.Println("go-chrome-framework error: unable to open console api called client", err.Error())
+		return err
+	}
+
+	exceptionThrown, err := client.Runtime.ExceptionThrown(context.Background())
+	if err != nil {
+		t.featureMu.Unlock()
+		closeRes(consoleAPICalled)
+		log.Println("go-chrome-framework error: unable to open exception thrown client", err.Error())
+		return err
+	}
+
+	if err = client.Runtime.Enable(ctx); err != nil {
+		t.featureMu.Unlock()
+		closeRes(consoleAPICalled)
+		closeRes(exceptionThrown)
+		log.Println("go-chrome-framework error: unable to enable runtime domain", err.Error())
+		return err
+	}
+
+	debug := &debugLog{}
+	t.debugLog = debug
+	t.featureMu.Unlock()
+
+	go func() {
+		defer closeRes(consoleAPICalled)
+		defer closeRes(exceptionThrown)
+
+		for {
+			select {
+			case <-consoleAPICalled.Ready():
+				ev, err := consoleAPICalled.Recv()
+				if err != nil {
+					return
+				}
+
+				texts := make([]string, len(ev.Args))
+				for i, arg := range ev.Args {
+					texts[i] = remoteObjectText(arg)
+				}
+
+				message := ConsoleMessage{Type: ev.Type, Text: strings.Join(texts, " "), Time: ev.Timestamp.Time()}
+				if ev.StackTrace != nil && len(ev.StackTrace.CallFrames) > 0 {
+					message.URL = ev.StackTrace.CallFrames[0].URL
+					message.Line = ev.StackTrace.CallFrames[0].LineNumber + 1
+				}
+				debug.addConsole(message)
+			case <-exceptionThrown.Ready():
+				ev, err := exceptionThrown.Recv()
+				if err != nil {
+					return
+				}
+
+				details := ev.ExceptionDetails
+				message := ConsoleMessage{Type: "exception", Text: (&JavaScriptError{Details: &details}).Error(),
+					Line: details.LineNumber + 1, Time: ev.Timestamp.Time()}
+				if details.URL != nil {
+					message.URL = *details.URL
+				}
+				debug.addConsole(message)
+			}
+		}
+	}()
+
+	// failed requests are reported to the handlers of OnRequestFailed
+	return t.OnRequestFailed(debug.addFailure, timeout)
+}
+
+// remoteObjectText formats a console argument the way the console displays it
+func remoteObjectText(object runtime.RemoteObject) string {
+	if len(object.Value) > 0 {
+		var text string
+		if err := json.Unmarshal(object.Value, &text); err == nil {
+			return text
+		}
+
+		return string(object.Value)
+	}
+
+	if object.UnserializableValue != nil {
+		return string(*object.UnserializableValue)
+	}
+
+	if object.Description != nil {
+		return *object.Description
+	}
+
+	return object.Type
+}
+
+// debugPage describes the page in a dump
+type debugPage struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	// navigation is the result of the last navigation of the tab
+	Navigation *NavigationResult `json:"navigation,omitempty"`
+	Time       time.Time         `json:"time"`
+}
+
+// lastNavigationResult returns the result of the last navigation of the tab, nil if it has not navigated
+func (t *tab) lastNavigationResult() *NavigationResult {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.lastNavigation
+}
+
+// DebugDump writes what is needed to analyze a failure of the page to the directory, creating it if needed:
+// screenshot.png, page.html, page.json with the current url and title and the url and status of the last navigation,
+// and, once EnableDebugLog was called, console.log and failed-requests.json. Every file is written even if writing
+// another fails, the error names the files which could not be written. Intended to be called from error paths, e.g.
+//
+//	if err != nil {
+//		_ = tab.DebugDump(filepath.Join("failures", jobID), 0)
+//	}
+func (t *tab) DebugDump(dir string, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	var failed []string
+	write := func(name string, data []byte, err error) {
+		if err == nil {
+			err = os.WriteFile(filepath.Join(dir, name), data, 0644)
+		}
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to dump", name, err.Error())
+			failed = append(failed, fmt.Sprintf("%v: %v", name, err))
+		}
+	}
+
+	var screenshot bytes.Buffer
+	err := t.CaptureScreenshotTo(&screenshot, ScreenshotOpts{}, contextTimeout(ctx))
+	write("screenshot.png", screenshot.Bytes(), err)
+
+	html, err := t.GetHTML(contextTimeout(ctx))
+	write("page.html", []byte(html), err)
+
+	page := debugPage{Navigation: t.lastNavigationResult(), Time: time.Now()}
+	err = t.Evaluate(`({url: location.href, title: document.title})`, &page, contextTimeout(ctx))
+	data, _ := json.MarshalIndent(page, "", "  ")
+	write("page.json", data, err)
+
+	t.featureMu.Lock()
+	debug := t.debugLog
+	t.featureMu.Unlock()
+
+	if debug != nil {
+		debug.mu.Lock()
+		var console strings.Builder
+		for _, message := range debug.console {
+			fmt.Fprintf(&console, "%v [%v] %v", message.Time.Format(time.RFC3339Nano), message.Type, message.Text)
+			if message.URL != "" {
+				fmt.Fprintf(&console, " (%v:%v)", message.URL, message.Line)
+			}
+			console.WriteString("\n")
+		}
+		failures, err := json.MarshalIndent(debug.failures, "", "  ")
+		debug.mu.Unlock()
+
+		write("console.log", []byte(console.String()), nil)
+		write("failed-requests.json", failures, err)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("go-chrome-framework: unable to dump %v", strings.Join(failed, "; "))
+	}
+
+	return nil
+}
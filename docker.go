@@ -0,0 +1,120 @@
+package chrome
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// DockerLauncher runs chrome in a docker container using the docker cli, see LaunchOpts.SetLauncher. The image must
+// run chrome with the arguments of the container, e.g. chromedp/headless-shell. The devtools port of the container is
+// published on a random port of the loopback interface, and the container is removed when the browser is terminated
+type DockerLauncher struct {
+	// Image to run, e.g. "chromedp/headless-shell:latest"
+	Image string
+	// RunArguments are additional arguments of docker run, e.g. "--shm-size=2g"
+	RunArguments []string
+	// Docker is the path of the docker cli, defaults to "docker"
+	Docker string
+}
+
+func (d *DockerLauncher) Start(ctx context.Context, config LauncherConfig) (LaunchedBrowser, error) {
+	docker := d.Docker
+	if docker == "" {
+		docker = "docker"
+	}
+
+	port := fmt.Sprintf("%v/tcp", config.Port)
+
+	arguments := []string{"run", "--detach", "--publish", "127.0.0.1::" + port}
+	for _, name := range sortedKeys(config.Env) {
+		arguments = append(arguments, "--env", name+"="+config.Env[name])
+	}
+	arguments = append(arguments, d.RunArguments...)
+	arguments = append(arguments, d.Image)
+	arguments = append(arguments, config.Arguments...)
+	// chrome listens on the loopback interface of the container unless told otherwise
+	arguments = append(arguments, "--remote-debugging-address=0.0.0.0")
+
+	id, err := dockerOutput(ctx, docker, arguments...)
+	if err != nil {
+		return nil, err
+	}
+
+	container := &dockerContainer{docker: docker, id: id}
+
+	addr, err := dockerOutput(ctx, docker, "port", id, port)
+	if err != nil {
+		closeRes(container)
+		return nil, err
+	}
+	// the port may be published on several interfaces, one per line
+	container.addr = strings.SplitN(addr, "\n", 2)[0]
+
+	// follow the output of chrome until the container exits
+	container.logs = exec.Command(docker, "logs", "--follow", id)
+	container.logs.Stdout = config.Output
+	container.logs.Stderr = config.Output
+	if err = container.logs.Start(); err != nil {
+		closeRes(container)
+		return nil, err
+	}
+
+	return container, nil
+}
+
+// dockerContainer is a browser running in a docker container
+type dockerContainer struct {
+	docker string
+	id     string
+	addr   string
+	// follows the output of the container
+	logs *exec.Cmd
+}
+
+func (d *dockerContainer) Addr() string {
+	return d.addr
+}
+
+func (d *dockerContainer) Wait() error {
+	status, err := dockerOutput(context.Background(), d.docker, "wait", d.id)
+	if d.logs != nil {
+		_ = d.logs.Wait()
+	}
+	if err != nil {
+		return err
+	}
+
+	if status != "0" {
+		return fmt.Errorf("go-chrome-framework: container %v exited with status %v", d.id, status)
+	}
+
+	return nil
+}
+
+func (d *dockerContainer) Kill() error {
+	_, err := dockerOutput(context.Background(), d.docker, "rm", "--force", d.id)
+	return err
+}
+
+// Close removes the container when starting it fails half way
+func (d *dockerContainer) Close() error {
+	return d.Kill()
+}
+
+// dockerOutput runs the docker cli and returns its trimmed output, the error includes what docker printed to stderr
+func dockerOutput(ctx context.Context, docker string, arguments ...string) (string, error) {
+	var stderr bytes.Buffer
+
+	command := exec.CommandContext(ctx, docker, arguments...)
+	command.Stderr = &stderr
+
+	output, err := command.Output()
+	if err != nil {
+		return "", fmt.Errorf("go-chrome-framework: docker %v failed: %v: %v", arguments[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,139 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/domsnapshot"
+	"log"
+	"time"
+)
+
+// DOMSnapshot is a flattened snapshot of the documents of a tab, including the documents of iframes
+type DOMSnapshot struct {
+	Documents []DocumentSnapshot
+}
+
+type DocumentSnapshot struct {
+	URL   string
+	Title string
+	// Nodes of the document in document order, ParentIndex of the nodes refers to this slice
+	Nodes []SnapshotNode
+}
+
+type SnapshotNode struct {
+	// NodeType as per the DOM specification, e.g. 1 for elements and 3 for text nodes
+	NodeType    int
+	NodeName    string
+	NodeValue   string
+	Attributes  map[string]string
+	ParentIndex int
+	// Layout is nil for nodes which are not rendered
+	Layout *SnapshotLayout
+}
+
+type SnapshotLayout struct {
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+	// Text contained by the layout box, if any
+	Text string
+	// Styles holds the computed styles requested in DOMSnapshotOpts
+	Styles map[string]string
+}
+
+func (t *tab) DOMSnapshot(opts DOMSnapshotOpts, timeout time.Duration) (*DOMSnapshot, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return nil, err
+	}
+
+	computedStyles := opts.ComputedStyles
+	if computedStyles == nil {
+		computedStyles = []string{}
+	}
+
+	var reply *domsnapshot.CaptureSnapshotReply
+	err := t.call(ctx, func(client *cdp.Client) (err error) {
+		reply, err = client.DOMSnapshot.CaptureSnapshot(ctx, domsnapshot.NewCaptureSnapshotArgs(computedStyles))
+		return err
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to capture dom snapshot", err.Error())
+		return nil, err
+	}
+
+	// all the strings of the snapshot are indices into a shared string table
+	str := func(index domsnapshot.StringIndex) string {
+		if index < 0 || int(index) >= len(reply.Strings) {
+			return ""
+		}
+		return reply.Strings[index]
+	}
+
+	snapshot := &DOMSnapshot{}
+	for _, document := range reply.Documents {
+		doc := DocumentSnapshot{
+			URL:   str(document.DocumentURL),
+			Title: str(document.Title),
+			Nodes: make([]SnapshotNode, len(document.Nodes.NodeType)),
+		}
+
+		for i := range doc.Nodes {
+			node := &doc.Nodes[i]
+
+			node.NodeType = document.Nodes.NodeType[i]
+			node.ParentIndex = -1
+			if i < len(document.Nodes.ParentIndex) {
+				node.ParentIndex = document.Nodes.ParentIndex[i]
+			}
+			if i < len(document.Nodes.NodeName) {
+				node.NodeName = str(document.Nodes.NodeName[i])
+			}
+			if i < len(document.Nodes.NodeValue) {
+				node.NodeValue = str(document.Nodes.NodeValue[i])
+			}
+			if i < len(document.Nodes.Attributes) && len(document.Nodes.Attributes[i]) > 0 {
+				// attributes are flattened into name, value pairs
+				attributes := document.Nodes.Attributes[i]
+				node.Attributes = make(map[string]string, len(attributes)/2)
+				for j := 0; j+1 < len(attributes); j += 2 {
+					node.Attributes[str(attributes[j])] = str(attributes[j+1])
+				}
+			}
+		}
+
+		layout := document.Layout
+		for i, nodeIndex := range layout.NodeIndex {
+			if nodeIndex < 0 || nodeIndex >= len(doc.Nodes) {
+				continue
+			}
+
+			box := &SnapshotLayout{}
+			if i < len(layout.Bounds) && len(layout.Bounds[i]) == 4 {
+				box.X, box.Y, box.Width, box.Height = layout.Bounds[i][0], layout.Bounds[i][1], layout.Bounds[i][2], layout.Bounds[i][3]
+			}
+			if i < len(layout.Text) {
+				box.Text = str(layout.Text[i])
+			}
+			if i < len(layout.Styles) && len(computedStyles) > 0 {
+				// styles are in the order they were requested in
+				box.Styles = make(map[string]string, len(computedStyles))
+				for j, style := range layout.Styles[i] {
+					if j < len(computedStyles) {
+						box.Styles[computedStyles[j]] = str(style)
+					}
+				}
+			}
+
+			doc.Nodes[nodeIndex].Layout = box
+		}
+
+		snapshot.Documents = append(snapshot.Documents, doc)
+	}
+
+	return snapshot, nil
+}
@@ -0,0 +1,145 @@
+package chrome
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/mafredri/cdp/rpcc"
+	"log"
+	"time"
+)
+
+// dragInterceptedEvent is the Input.dragIntercepted event, the drag data is passed back to Input.dispatchDragEvent as
+// is. The drag commands are sent over the connection directly as they are missing from the protocol bindings
+type dragInterceptedEvent struct {
+	Data json.RawMessage `json:"data"`
+}
+
+type setInterceptDragsArgs struct {
+	Enabled bool `json:"enabled"`
+}
+
+type dispatchDragEventArgs struct {
+	Type string          `json:"type"`
+	X    float64         `json:"x"`
+	Y    float64         `json:"y"`
+	Data json.RawMessage `json:"data"`
+}
+
+// DragAndDrop drags the element matching the source selector onto the element matching the target selector, moving the
+// mouse with the left button pressed like a user would. Native html drag and drop is completed with drag events when
+// the browser supports intercepting drags, libraries implementing dragging with mouse events are served by the mouse
+// events alone
+func (t *tab) DragAndDrop(sourceSelector, targetSelector string, opts DragAndDropOpts, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if opts.Steps <= 0 {
+		opts.Steps = 10
+	}
+
+	sourceNode, err := t.querySelector(ctx, sourceSelector)
+	if err != nil {
+		return err
+	}
+
+	targetNode, err := t.querySelector(ctx, targetSelector)
+	if err != nil {
+		return err
+	}
+
+	sourceX, sourceY, err := t.nodeCenter(ctx, sourceNode)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to locate drag source", err.Error())
+		return err
+	}
+
+	// the target is located after the source is scrolled into view, moving the mouse must not scroll the page
+	targetX, targetY, err := t.nodeCenter(ctx, targetNode)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to locate drop target", err.Error())
+		return err
+	}
+
+	// intercepting drags hands the drag data to us instead of the browser starting a native drag, which does not work
+	// with synthesized mouse events. Older browsers do not support it
+	t.mu.Lock()
+	conn := t.conn
+	t.mu.Unlock()
+
+	dragIntercepted, err := rpcc.NewStream(ctx, "Input.dragIntercepted", conn)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to open drag intercepted client", err.Error())
+		return err
+	}
+	defer closeRes(dragIntercepted)
+
+	intercepting := rpcc.Invoke(ctx, "Input.setInterceptDrags", setInterceptDragsArgs{Enabled: true}, nil, conn) == nil
+	if intercepting {
+		defer rpcc.Invoke(ctx, "Input.setInterceptDrags", setInterceptDragsArgs{Enabled: false}, nil, conn)
+	}
+
+	if err = t.dispatchMouse(ctx, "mouseMoved", sourceX, sourceY, false); err != nil {
+		log.Println("go-chrome-framework error: unable to move mouse", err.Error())
+		return err
+	}
+
+	if err = t.dispatchMouse(ctx, "mousePressed", sourceX, sourceY, true); err != nil {
+		log.Println("go-chrome-framework error: unable to press mouse", err.Error())
+		return err
+	}
+
+	var dragData json.RawMessage
+	for step := 1; step <= opts.Steps; step++ {
+		x := sourceX + (targetX-sourceX)*float64(step)/float64(opts.Steps)
+		y := sourceY + (targetY-sourceY)*float64(step)/float64(opts.Steps)
+
+		if err = t.dispatchMouse(ctx, "mouseMoved", x, y, true); err != nil {
+			log.Println("go-chrome-framework error: unable to move mouse", err.Error())
+			return err
+		}
+
+		if intercepting && dragData == nil {
+			select {
+			case <-dragIntercepted.Ready():
+				var ev dragInterceptedEvent
+				if err = dragIntercepted.RecvMsg(&ev); err != nil {
+					log.Println("go-chrome-framework error: unable to get drag intercepted event", err.Error())
+					return err
+				}
+				dragData = ev.Data
+			default:
+			}
+		}
+
+		if opts.Delay > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(opts.Delay):
+			}
+		}
+	}
+
+	// a native drag started, deliver it to the target
+	if dragData != nil {
+		for _, eventType := range []string{"dragEnter", "dragOver", "drop"} {
+			dragArgs := dispatchDragEventArgs{Type: eventType, X: targetX, Y: targetY, Data: dragData}
+			if err = rpcc.Invoke(ctx, "Input.dispatchDragEvent", dragArgs, nil, conn); err != nil {
+				log.Println("go-chrome-framework error: unable to dispatch drag event", err.Error())
+				return err
+			}
+		}
+	}
+
+	if err = t.dispatchMouse(ctx, "mouseReleased", targetX, targetY, true); err != nil {
+		log.Println("go-chrome-framework error: unable to release mouse", err.Error())
+		return err
+	}
+
+	return nil
+}
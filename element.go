@@ -0,0 +1,163 @@
+package chrome
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/dom"
+	"github.com/mafredri/cdp/protocol/runtime"
+	"log"
+	"time"
+)
+
+// Element is a node of the document of a tab. It refers to the node it was queried for, if the document is replaced,
+// e.g. by a navigation, the element has to be queried again
+type Element interface {
+	NodeID() dom.NodeID
+	Hover(timeout time.Duration) error
+	Focus(timeout time.Duration) error
+	Blur(timeout time.Duration) error
+	SelectOption(timeout time.Duration, values ...string) error
+	SelectByLabel(timeout time.Duration, labels ...string) error
+}
+
+type element struct {
+	tab    *tab
+	nodeID dom.NodeID
+}
+
+// Element returns the first element matching the selector
+func (t *tab) Element(selector string, timeout time.Duration) (Element, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return nil, err
+	}
+
+	nodeID, err := t.querySelector(ctx, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	return &element{tab: t, nodeID: nodeID}, nil
+}
+
+func (e *element) NodeID() dom.NodeID {
+	return e.nodeID
+}
+
+// begin resolves the timeout of a call on the element and connects its tab
+func (e *element) begin(timeout time.Duration) (context.Context, context.CancelFunc, error) {
+	timeout = e.tab.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(e.tab.lifetime(), timeout)
+
+	if err := e.tab.ensureConnected(timeout); err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	return ctx, cancel, nil
+}
+
+// callFunction calls the javascript function with the element as this and unmarshals the result into value unless
+// value is nil
+func (e *element) callFunction(ctx context.Context, function string, value interface{}, arguments ...interface{}) error {
+	client, err := e.tab.currentClient()
+	if err != nil {
+		return err
+	}
+
+	object, err := client.DOM.ResolveNode(ctx, dom.NewResolveNodeArgs().SetNodeID(e.nodeID))
+	if err != nil {
+		return err
+	}
+	defer client.Runtime.ReleaseObject(ctx, runtime.NewReleaseObjectArgs(*object.Object.ObjectID))
+
+	callArguments := make([]runtime.CallArgument, len(arguments))
+	for i, argument := range arguments {
+		encoded, err := json.Marshal(argument)
+		if err != nil {
+			return err
+		}
+		callArguments[i] = runtime.CallArgument{Value: encoded}
+	}
+
+	callArgs := runtime.NewCallFunctionOnArgs(function).
+		SetObjectID(*object.Object.ObjectID).
+		SetArguments(callArguments).
+		SetAwaitPromise(true).
+		SetReturnByValue(true)
+	reply, err := client.Runtime.CallFunctionOn(ctx, callArgs)
+	if err != nil {
+		return err
+	}
+
+	if reply.ExceptionDetails != nil {
+		return &JavaScriptError{Details: reply.ExceptionDetails}
+	}
+
+	if value == nil || len(reply.Result.Value) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(reply.Result.Value, value)
+}
+
+// Hover scrolls the element into view and moves the mouse to its center, triggering hover styles and mouse over events
+func (e *element) Hover(timeout time.Duration) error {
+	ctx, cancel, err := e.begin(timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	x, y, err := e.tab.nodeCenter(ctx, e.nodeID)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to locate element", err.Error())
+		return err
+	}
+
+	if err = e.tab.dispatchMouse(ctx, "mouseMoved", x, y, false); err != nil {
+		log.Println("go-chrome-framework error: unable to move mouse", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// Focus focuses the element, dispatching focus events
+func (e *element) Focus(timeout time.Duration) error {
+	ctx, cancel, err := e.begin(timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	err = e.tab.call(ctx, func(client *cdp.Client) error {
+		return client.DOM.Focus(ctx, dom.NewFocusArgs().SetNodeID(e.nodeID))
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to focus element", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// Blur removes the focus from the element, dispatching blur events
+func (e *element) Blur(timeout time.Duration) error {
+	ctx, cancel, err := e.begin(timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if err = e.callFunction(ctx, `function() { this.blur(); }`, nil); err != nil {
+		log.Println("go-chrome-framework error: unable to blur element", err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/emulation"
+	"log"
+	"time"
+)
+
+// VisionDeficiency is a vision deficiency the rendering of a page can be emulated with
+type VisionDeficiency string
+
+const (
+	VisionDeficiencyNone          VisionDeficiency = "none"
+	VisionDeficiencyAchromatopsia VisionDeficiency = "achromatopsia"
+	VisionDeficiencyBlurredVision VisionDeficiency = "blurredVision"
+	VisionDeficiencyDeuteranopia  VisionDeficiency = "deuteranopia"
+	VisionDeficiencyProtanopia    VisionDeficiency = "protanopia"
+	VisionDeficiencyTritanopia    VisionDeficiency = "tritanopia"
+)
+
+// EmulateVisionDeficiency renders the page the way it is seen with the vision deficiency, VisionDeficiencyNone stops
+// the emulation
+func (t *tab) EmulateVisionDeficiency(deficiency VisionDeficiency, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	deficiencyArgs := emulation.NewSetEmulatedVisionDeficiencyArgs(string(deficiency))
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.Emulation.SetEmulatedVisionDeficiency(ctx, deficiencyArgs)
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to emulate vision deficiency", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// EmulateMediaFeatures overrides the values of css media features, e.g. "forced-colors" to "active",
+// "prefers-reduced-motion" to "reduce" or "prefers-color-scheme" to "dark". Every call replaces the overrides of the
+// previous call, passing no features removes them
+func (t *tab) EmulateMediaFeatures(features map[string]string, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	mediaFeatures := make([]emulation.MediaFeature, 0, len(features))
+	for _, name := range sortedKeys(features) {
+		mediaFeatures = append(mediaFeatures, emulation.MediaFeature{Name: name, Value: features[name]})
+	}
+
+	mediaArgs := emulation.NewSetEmulatedMediaArgs().SetFeatures(mediaFeatures)
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.Emulation.SetEmulatedMedia(ctx, mediaArgs)
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to emulate media features", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// SetFocusEmulationEnabled makes the page behave as if it had focus even when its window is in the background or the
+// browser is headless
+func (t *tab) SetFocusEmulationEnabled(enabled bool, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	focusArgs := emulation.NewSetFocusEmulationEnabledArgs(enabled)
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.Emulation.SetFocusEmulationEnabled(ctx, focusArgs)
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to set focus emulation", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// SetIdleOverride overrides the state reported by the idle detection api, whether the user is active and whether the
+// screen is unlocked
+func (t *tab) SetIdleOverride(userActive, screenUnlocked bool, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	idleArgs := emulation.NewSetIdleOverrideArgs(userActive, screenUnlocked)
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.Emulation.SetIdleOverride(ctx, idleArgs)
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to set idle override", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// ClearIdleOverride removes the override set by SetIdleOverride
+func (t *tab) ClearIdleOverride(timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.Emulation.ClearIdleOverride(ctx)
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to clear idle override", err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp/protocol/page"
+	"github.com/mafredri/cdp/protocol/target"
+	"github.com/mafredri/cdp/rpcc"
+	"sync"
+)
+
+// BrowserEventType is the kind of a BrowserEvent
+type BrowserEventType string
+
+const (
+	EventTargetCreated     BrowserEventType = "targetCreated"
+	EventTargetDestroyed   BrowserEventType = "targetDestroyed"
+	EventTargetCrashed     BrowserEventType = "targetCrashed"
+	EventDownloadWillBegin BrowserEventType = "downloadWillBegin"
+)
+
+// BrowserEvent is a browser wide change, only the fields relevant to its type are set
+type BrowserEvent struct {
+	Type     BrowserEventType
+	TargetID target.ID
+	// TargetInfo of created targets
+	TargetInfo *target.Info
+	// Status and ErrorCode of crashed targets
+	Status    string
+	ErrorCode int
+	// GUID, URL and SuggestedFilename of downloads
+	GUID              string
+	URL               string
+	SuggestedFilename string
+}
+
+// BackpressurePolicy decides what happens to events when the buffer of a subscriber is full
+type BackpressurePolicy int
+
+const (
+	// BackpressureBlock waits for the subscriber to receive the event, holding up the delivery of all browser events
+	// including crash detection
+	BackpressureBlock BackpressurePolicy = iota
+	// BackpressureDropNewest discards the event
+	BackpressureDropNewest
+	// BackpressureDropOldest discards the oldest buffered event to make room for the event
+	BackpressureDropOldest
+)
+
+type EventsOpts struct {
+	// Buffer is the capacity of the channel, defaults to 64
+	Buffer int
+	// Policy applied when the channel is full, defaults to BackpressureBlock
+	Policy BackpressurePolicy
+}
+
+type eventSubscriber struct {
+	events chan BrowserEvent
+	policy BackpressurePolicy
+	// closed when the subscriber unsubscribes
+	done chan struct{}
+	once sync.Once
+}
+
+// Events subscribes to the browser wide events: targets being created, destroyed or crashing and downloads beginning.
+// The returned function unsubscribes and closes the channel, it must be called once the events are no longer needed
+func (c *chrome) Events(opts EventsOpts) (<-chan BrowserEvent, func()) {
+	if opts.Buffer <= 0 {
+		opts.Buffer = 64
+	}
+
+	subscriber := &eventSubscriber{
+		events: make(chan BrowserEvent, opts.Buffer),
+		policy: opts.Policy,
+		done:   make(chan struct{}),
+	}
+
+	c.eventsMu.Lock()
+	c.subscribers = append(c.subscribers, subscriber)
+	c.eventsMu.Unlock()
+
+	unsubscribe := func() {
+		subscriber.once.Do(func() {
+			// stop a blocked delivery before waiting for the lock held while delivering
+			close(subscriber.done)
+
+			c.eventsMu.Lock()
+			for i, s := range c.subscribers {
+				if s == subscriber {
+					c.subscribers = append(c.subscribers[:i], c.subscribers[i+1:]...)
+					break
+				}
+			}
+			c.eventsMu.Unlock()
+
+			close(subscriber.events)
+		})
+	}
+
+	return subscriber.events, unsubscribe
+}
+
+// publish delivers the event to the subscribers according to their backpressure policy
+func (c *chrome) publish(event BrowserEvent) {
+	c.eventsMu.RLock()
+	defer c.eventsMu.RUnlock()
+
+	for _, subscriber := range c.subscribers {
+		subscriber.deliver(event)
+	}
+}
+
+func (s *eventSubscriber) deliver(event BrowserEvent) {
+	switch s.policy {
+	case BackpressureDropNewest:
+		select {
+		case s.events <- event:
+		case <-s.done:
+		default:
+		}
+	case BackpressureDropOldest:
+		for {
+			select {
+			case s.events <- event:
+				return
+			case <-s.done:
+				return
+			default:
+			}
+
+			// make room by discarding the oldest event, unless the subscriber received it meanwhile
+			select {
+			case <-s.events:
+			default:
+			}
+		}
+	default:
+		select {
+		case s.events <- event:
+		case <-s.done:
+		}
+	}
+}
+
+type setDownloadBehaviorArgs struct {
+	Behavior      string `json:"behavior"`
+	EventsEnabled bool   `json:"eventsEnabled"`
+}
+
+// watchBrowserEvents publishes the creation of targets and the beginning of downloads. Crashed and destroyed targets
+// are published by watchTargets
+func (c *chrome) watchBrowserEvents(ctx, streamCtx context.Context) error {
+	targetCreated, err := c.client.Target.TargetCreated(streamCtx)
+	if err != nil {
+		return err
+	}
+
+	// the browser download events are missing from the protocol bindings, they share the shape of the page events
+	downloadWillBegin, err := rpcc.NewStream(streamCtx, "Browser.downloadWillBegin", c.conn)
+	if err != nil {
+		closeRes(targetCreated)
+		return err
+	}
+
+	// download events are only sent when enabled, the default behavior keeps downloads working as before
+	downloadArgs := setDownloadBehaviorArgs{Behavior: "default", EventsEnabled: true}
+	if err = rpcc.Invoke(ctx, "Browser.setDownloadBehavior", downloadArgs, nil, c.conn); err != nil {
+		closeRes(targetCreated)
+		closeRes(downloadWillBegin)
+		return err
+	}
+
+	go func() {
+		defer closeRes(targetCreated)
+
+		for {
+			reply, err := targetCreated.Recv()
+			if err != nil {
+				return
+			}
+
+			info := reply.TargetInfo
+			c.publish(BrowserEvent{Type: EventTargetCreated, TargetID: info.TargetID, TargetInfo: &info})
+		}
+	}()
+
+	go func() {
+		defer closeRes(downloadWillBegin)
+
+		for {
+			var reply page.DownloadWillBeginReply
+			if err := downloadWillBegin.RecvMsg(&reply); err != nil {
+				return
+			}
+
+			c.publish(BrowserEvent{
+				Type:              EventDownloadWillBegin,
+				GUID:              reply.GUID,
+				URL:               reply.URL,
+				SuggestedFilename: reply.SuggestedFilename,
+			})
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package chrome
+
+import (
+	"github.com/mafredri/cdp/protocol/network"
+	"log"
+	"sync"
+	"time"
+)
+
+// EventSourceMessage is a server-sent event received by an EventSource of the page
+type EventSourceMessage struct {
+	// RequestID identifies the event source
+	RequestID network.RequestID
+	// URL of the event source
+	URL       string
+	EventName string
+	EventID   string
+	Data      string
+	// Time the message was reported at
+	Time time.Time
+}
+
+// EventSourceMessageHandler is invoked for every server-sent event received by a tab
+type EventSourceMessageHandler func(EventSourceMessage)
+
+// eventSources dispatches the server-sent events of a tab to the handlers
+type eventSources struct {
+	// guards handlers and urls
+	mu       sync.Mutex
+	handlers []EventSourceMessageHandler
+	// urls of the event sources by their request id
+	urls map[network.RequestID]string
+}
+
+// OnEventSourceMessage invokes the handler for every server-sent event received by the event sources of the tab.
+// Handlers are invoked one at a time in the order the events are received
+func (t *tab) OnEventSourceMessage(handler EventSourceMessageHandler, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if t.eventSources != nil {
+		t.eventSources.mu.Lock()
+		t.eventSources.handlers = append(t.eventSources.handlers, handler)
+		t.eventSources.mu.Unlock()
+
+		return nil
+	}
+
+	sources := &eventSources{
+		handlers: []EventSourceMessageHandler{handler},
+		urls:     make(map[network.RequestID]string),
+	}
+	if err := t.openEventSources(sources); err != nil {
+		return err
+	}
+	t.eventSources = sources
+
+	return nil
+}
+
+// openEventSources opens the event source streams of the current connection and dispatches the messages to the
+// handlers, see restoreFeatures
+func (t *tab) openEventSources(sources *eventSources) error {
+	// messages are reported for the lifetime of the tab, not just the timeout of a call
+	client, err := t.currentClient()
+	if err != nil {
+		return err
+	}
+
+	requestWillBeSent, err := client.Network.RequestWillBeSent(t.lifetime())
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to open request will be sent client", err.Error())
+		return err
+	}
+
+	messageReceived, err := client.Network.EventSourceMessageReceived(t.lifetime())
+	if err != nil {
+		closeRes(requestWillBeSent)
+		log.Println("go-chrome-framework error: unable to open event source message received client", err.Error())
+		return err
+	}
+
+	go func() {
+		defer closeRes(requestWillBeSent)
+		defer closeRes(messageReceived)
+
+		defer t.streamsEnded(func() {
+			if t.eventSources == sources {
+				t.eventSources = nil
+			}
+		})
+
+		for {
+			select {
+			case <-requestWillBeSent.Ready():
+				ev, err := requestWillBeSent.Recv()
+				if err != nil {
+					return
+				}
+
+				if ev.Type == network.ResourceTypeEventSource {
+					sources.mu.Lock()
+					sources.urls[ev.RequestID] = ev.Request.URL
+					sources.mu.Unlock()
+				}
+			case <-messageReceived.Ready():
+				ev, err := messageReceived.Recv()
+				if err != nil {
+					return
+				}
+
+				message := EventSourceMessage{
+					RequestID: ev.RequestID,
+					EventName: ev.EventName,
+					EventID:   ev.EventID,
+					Data:      ev.Data,
+					Time:      time.Now(),
+				}
+
+				sources.mu.Lock()
+				message.URL = sources.urls[ev.RequestID]
+				handlers := append([]EventSourceMessageHandler{}, sources.handlers...)
+				sources.mu.Unlock()
+
+				for _, handler := range handlers {
+					handler(message)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
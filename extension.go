@@ -0,0 +1,63 @@
+package chrome
+
+import (
+	"context"
+	"fmt"
+	"github.com/mafredri/cdp/protocol/target"
+	"log"
+	"net/url"
+	"time"
+)
+
+// ExtensionTargets returns the background pages and service workers of the loaded extensions
+func (c *chrome) ExtensionTargets(timeout time.Duration) ([]target.Info, error) {
+	ctx, cancel := context.WithTimeout(c.lifetime(), timeout)
+	defer cancel()
+
+	targets, err := c.client.Target.GetTargets(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to get list of targets", err.Error())
+		return nil, err
+	}
+
+	var extensionTargets []target.Info
+	for _, targetInfo := range targets.TargetInfos {
+		if extensionID(targetInfo) != "" {
+			extensionTargets = append(extensionTargets, targetInfo)
+		}
+	}
+
+	return extensionTargets, nil
+}
+
+// OpenExtensionTarget returns a tab controlling the background page or service worker of the extension, so that the
+// javascript passed to Exec runs with access to the extension APIs
+func (c *chrome) OpenExtensionTarget(id string, timeout time.Duration) (Tab, error) {
+	extensionTargets, err := c.ExtensionTargets(timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, targetInfo := range extensionTargets {
+		if extensionID(targetInfo) == id {
+			return c.publicTab(c.newTab(targetInfo.TargetID)), nil
+		}
+	}
+
+	return nil, fmt.Errorf("go-chrome-framework: no background target found for extension %v", id)
+}
+
+// extensionID returns the id of the extension the target is the background of, or an empty string if it is not an
+// extension background target
+func extensionID(targetInfo target.Info) string {
+	if targetInfo.Type != "background_page" && targetInfo.Type != "service_worker" {
+		return ""
+	}
+
+	u, err := url.Parse(targetInfo.URL)
+	if err != nil || u.Scheme != "chrome-extension" {
+		return ""
+	}
+
+	return u.Host
+}
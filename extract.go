@@ -0,0 +1,115 @@
+package chrome
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// elementScript evaluates an expression against the element matched by a selector, reporting whether it was found
+const elementScript = `((el) => el ? {found: true, value: (%v)} : {found: false})(document.querySelector(%v))`
+
+// evaluateElement evaluates the expression, which refers to the matched element as el, and unmarshals its value into v.
+// ErrNodeNotFound is returned when no element matches the selector
+func (t *tab) evaluateElement(ctx context.Context, selector, expression string, v interface{}) error {
+	var result struct {
+		Found bool            `json:"found"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := t.evaluate(ctx, fmt.Sprintf(elementScript, expression, jsString(selector)), &result); err != nil {
+		return err
+	}
+
+	if !result.Found {
+		return fmt.Errorf("%w: %v", ErrNodeNotFound, selector)
+	}
+
+	if v == nil || len(result.Value) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(result.Value, v)
+}
+
+// GetText returns the rendered text of the first element matching the selector
+func (t *tab) GetText(selector string, timeout time.Duration) (string, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return "", err
+	}
+
+	var text string
+	if err := t.evaluateElement(ctx, selector, "el.innerText", &text); err != nil {
+		log.Println("go-chrome-framework error: unable to get text", err.Error())
+		return "", err
+	}
+
+	return text, nil
+}
+
+// GetAttribute returns the value of the named attribute of the first element matching the selector, an empty string
+// is returned when the element does not have the attribute
+func (t *tab) GetAttribute(selector, name string, timeout time.Duration) (string, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return "", err
+	}
+
+	var value *string
+	if err := t.evaluateElement(ctx, selector, "el.getAttribute("+jsString(name)+")", &value); err != nil {
+		log.Println("go-chrome-framework error: unable to get attribute", err.Error())
+		return "", err
+	}
+
+	if value == nil {
+		return "", nil
+	}
+
+	return *value, nil
+}
+
+// GetInnerHTML returns the inner html of the first element matching the selector
+func (t *tab) GetInnerHTML(selector string, timeout time.Duration) (string, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return "", err
+	}
+
+	var html string
+	if err := t.evaluateElement(ctx, selector, "el.innerHTML", &html); err != nil {
+		log.Println("go-chrome-framework error: unable to get inner html", err.Error())
+		return "", err
+	}
+
+	return html, nil
+}
+
+// Count returns the number of elements matching the selector
+func (t *tab) Count(selector string, timeout time.Duration) (int, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := t.evaluate(ctx, "document.querySelectorAll("+jsString(selector)+").length", &count); err != nil {
+		log.Println("go-chrome-framework error: unable to count elements", err.Error())
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,540 @@
+package chrome
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/mafredri/cdp/protocol/browser"
+	"github.com/mafredri/cdp/protocol/dom"
+	"github.com/mafredri/cdp/protocol/runtime"
+	"go.ajitem.com/gcf/v3/visualdiff"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// failureDumpTimeout bounds the dump of a failure, which must not hold up the error it is saved for
+const failureDumpTimeout = 5 * time.Second
+
+// artifactTab saves failure artifacts of its tab whenever a method returns an error, see
+// LaunchOpts.SetFailureArtifacts
+type artifactTab struct {
+	*tab
+	// directory the artifacts are saved to, in a directory per failure
+	dir string
+}
+
+// publicTab returns the tab handed out by the browser, saving failure artifacts if the browser was launched with
+// SetFailureArtifacts
+func (c *chrome) publicTab(t *tab) Tab {
+	if c.opts == nil || c.opts.failureArtifacts == "" {
+		return t
+	}
+
+	return &artifactTab{tab: t, dir: c.opts.failureArtifacts}
+}
+
+// capture saves the artifacts of the failure of the method, unless err is nil or the tab is closed. The error is
+// returned as is
+func (a *artifactTab) capture(method string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	select {
+	case <-a.tab.Closed():
+		return err
+	default:
+	}
+
+	id, idErr := correlationID()
+	if idErr != nil {
+		log.Println("go-chrome-framework error: unable to generate failure correlation id", idErr.Error())
+		return err
+	}
+
+	dir := filepath.Join(a.dir, id)
+	if dirErr := os.MkdirAll(dir, 0755); dirErr != nil {
+		log.Println("go-chrome-framework error: unable to save failure artifacts", dirErr.Error())
+	}
+
+	// nothing can be dumped once the connection is gone, e.g. when the browser exited
+	if _, err := a.tab.currentClient(); err == nil && a.tab.lifetime().Err() == nil {
+		if dumpErr := a.tab.DebugDump(dir, failureDumpTimeout); dumpErr != nil {
+			log.Println("go-chrome-framework error: unable to save failure artifacts", dumpErr.Error())
+		}
+	}
+
+	description := fmt.Sprintf("%v failed at %v: %v\n", method, time.Now().Format(time.RFC3339Nano), err)
+	if writeErr := os.WriteFile(filepath.Join(dir, "error.txt"), []byte(description), 0644); writeErr != nil {
+		log.Println("go-chrome-framework error: unable to save failure artifacts", writeErr.Error())
+	}
+
+	log.Printf("go-chrome-framework: saved failure artifacts of %v with correlation id %v to %v\n", method, id, dir)
+	return err
+}
+
+// correlationID returns a sortable id of a failure, its time followed by random characters
+func correlationID() (string, error) {
+	random := make([]byte, 4)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(random), nil
+}
+
+func (a *artifactTab) Navigate(url string, timeout time.Duration) (*NavigationResult, error) {
+	result, err := a.tab.Navigate(url, timeout)
+	return result, a.capture("Navigate", err)
+}
+
+func (a *artifactTab) NavigateWithOpts(url string, opts *NavigateOpts, timeout time.Duration) (*NavigationResult, error) {
+	result, err := a.tab.NavigateWithOpts(url, opts, timeout)
+	return result, a.capture("NavigateWithOpts", err)
+}
+
+func (a *artifactTab) WaitForURL(pattern string, timeout time.Duration) (string, error) {
+	result, err := a.tab.WaitForURL(pattern, timeout)
+	return result, a.capture("WaitForURL", err)
+}
+
+func (a *artifactTab) WaitForURLMatch(re *regexp.Regexp, timeout time.Duration) (string, error) {
+	result, err := a.tab.WaitForURLMatch(re, timeout)
+	return result, a.capture("WaitForURLMatch", err)
+}
+
+func (a *artifactTab) GetHTML(timeout time.Duration) (string, error) {
+	result, err := a.tab.GetHTML(timeout)
+	return result, a.capture("GetHTML", err)
+}
+
+func (a *artifactTab) CaptureScreenshot(opts ScreenshotOpts, timeout time.Duration) (string, error) {
+	result, err := a.tab.CaptureScreenshot(opts, timeout)
+	return result, a.capture("CaptureScreenshot", err)
+}
+
+func (a *artifactTab) CaptureScreenshotTo(w io.Writer, opts ScreenshotOpts, timeout time.Duration) error {
+	return a.capture("CaptureScreenshotTo", a.tab.CaptureScreenshotTo(w, opts, timeout))
+}
+
+func (a *artifactTab) CaptureScreenshotFile(path string, opts ScreenshotOpts, timeout time.Duration) error {
+	return a.capture("CaptureScreenshotFile", a.tab.CaptureScreenshotFile(path, opts, timeout))
+}
+
+func (a *artifactTab) CompareScreenshot(baselinePath string, opts visualdiff.Opts, timeout time.Duration) (*visualdiff.Result, error) {
+	result, err := a.tab.CompareScreenshot(baselinePath, opts, timeout)
+	return result, a.capture("CompareScreenshot", err)
+}
+
+func (a *artifactTab) ScreenshotElement(selector string, opts ElementScreenshotOpts, timeout time.Duration) ([]byte, error) {
+	result, err := a.tab.ScreenshotElement(selector, opts, timeout)
+	return result, a.capture("ScreenshotElement", err)
+}
+
+func (a *artifactTab) ScrollTo(x, y float64, timeout time.Duration) error {
+	return a.capture("ScrollTo", a.tab.ScrollTo(x, y, timeout))
+}
+
+func (a *artifactTab) ScrollIntoView(selector string, timeout time.Duration) error {
+	return a.capture("ScrollIntoView", a.tab.ScrollIntoView(selector, timeout))
+}
+
+func (a *artifactTab) ScrollToBottom(step float64, delay time.Duration, timeout time.Duration) error {
+	return a.capture("ScrollToBottom", a.tab.ScrollToBottom(step, delay, timeout))
+}
+
+func (a *artifactTab) AutoScroll(opts AutoScrollOpts, timeout time.Duration) error {
+	return a.capture("AutoScroll", a.tab.AutoScroll(opts, timeout))
+}
+
+func (a *artifactTab) FillForm(fields map[string]string, timeout time.Duration) error {
+	return a.capture("FillForm", a.tab.FillForm(fields, timeout))
+}
+
+func (a *artifactTab) Submit(selector string, timeout time.Duration) error {
+	return a.capture("Submit", a.tab.Submit(selector, timeout))
+}
+
+func (a *artifactTab) GetText(selector string, timeout time.Duration) (string, error) {
+	result, err := a.tab.GetText(selector, timeout)
+	return result, a.capture("GetText", err)
+}
+
+func (a *artifactTab) GetAttribute(selector, name string, timeout time.Duration) (string, error) {
+	result, err := a.tab.GetAttribute(selector, name, timeout)
+	return result, a.capture("GetAttribute", err)
+}
+
+func (a *artifactTab) GetInnerHTML(selector string, timeout time.Duration) (string, error) {
+	result, err := a.tab.GetInnerHTML(selector, timeout)
+	return result, a.capture("GetInnerHTML", err)
+}
+
+func (a *artifactTab) Count(selector string, timeout time.Duration) (int, error) {
+	result, err := a.tab.Count(selector, timeout)
+	return result, a.capture("Count", err)
+}
+
+func (a *artifactTab) QueryXPath(expression string, timeout time.Duration) ([]dom.NodeID, error) {
+	result, err := a.tab.QueryXPath(expression, timeout)
+	return result, a.capture("QueryXPath", err)
+}
+
+func (a *artifactTab) WaitForXPath(expression string, timeout time.Duration) ([]dom.NodeID, error) {
+	result, err := a.tab.WaitForXPath(expression, timeout)
+	return result, a.capture("WaitForXPath", err)
+}
+
+func (a *artifactTab) ExtractTable(selector string, timeout time.Duration) ([][]string, error) {
+	result, err := a.tab.ExtractTable(selector, timeout)
+	return result, a.capture("ExtractTable", err)
+}
+
+func (a *artifactTab) ExtractTableTo(selector string, out interface{}, opts TableOpts, timeout time.Duration) error {
+	return a.capture("ExtractTableTo", a.tab.ExtractTableTo(selector, out, opts, timeout))
+}
+
+func (a *artifactTab) ExtractLinks(opts LinkOpts, timeout time.Duration) ([]Link, error) {
+	result, err := a.tab.ExtractLinks(opts, timeout)
+	return result, a.capture("ExtractLinks", err)
+}
+
+func (a *artifactTab) SetContent(html string, timeout time.Duration) error {
+	return a.capture("SetContent", a.tab.SetContent(html, timeout))
+}
+
+func (a *artifactTab) WaitForFonts(timeout time.Duration) error {
+	return a.capture("WaitForFonts", a.tab.WaitForFonts(timeout))
+}
+
+func (a *artifactTab) InjectFonts(fonts []FontFace, timeout time.Duration) error {
+	return a.capture("InjectFonts", a.tab.InjectFonts(fonts, timeout))
+}
+
+func (a *artifactTab) WaitForImages(timeout time.Duration) ([]FailedImage, error) {
+	result, err := a.tab.WaitForImages(timeout)
+	return result, a.capture("WaitForImages", err)
+}
+
+func (a *artifactTab) FreezeAnimations(timeout time.Duration) error {
+	return a.capture("FreezeAnimations", a.tab.FreezeAnimations(timeout))
+}
+
+func (a *artifactTab) SetAnimationPlaybackRate(rate float64, timeout time.Duration) error {
+	return a.capture("SetAnimationPlaybackRate", a.tab.SetAnimationPlaybackRate(rate, timeout))
+}
+
+func (a *artifactTab) PrintToPDF(opts PDFOpts, timeout time.Duration) ([]byte, error) {
+	result, err := a.tab.PrintToPDF(opts, timeout)
+	return result, a.capture("PrintToPDF", err)
+}
+
+func (a *artifactTab) ClearSiteData(origin string, types []StorageType, timeout time.Duration) error {
+	return a.capture("ClearSiteData", a.tab.ClearSiteData(origin, types, timeout))
+}
+
+func (a *artifactTab) ServiceWorkerRegistrations(timeout time.Duration) ([]ServiceWorkerRegistration, error) {
+	result, err := a.tab.ServiceWorkerRegistrations(timeout)
+	return result, a.capture("ServiceWorkerRegistrations", err)
+}
+
+func (a *artifactTab) UnregisterServiceWorker(scopeURL string, timeout time.Duration) error {
+	return a.capture("UnregisterServiceWorker", a.tab.UnregisterServiceWorker(scopeURL, timeout))
+}
+
+func (a *artifactTab) SkipServiceWorkerWaiting(scopeURL string, timeout time.Duration) error {
+	return a.capture("SkipServiceWorkerWaiting", a.tab.SkipServiceWorkerWaiting(scopeURL, timeout))
+}
+
+func (a *artifactTab) StopAllServiceWorkers(timeout time.Duration) error {
+	return a.capture("StopAllServiceWorkers", a.tab.StopAllServiceWorkers(timeout))
+}
+
+func (a *artifactTab) SetBypassServiceWorker(bypass bool, timeout time.Duration) error {
+	return a.capture("SetBypassServiceWorker", a.tab.SetBypassServiceWorker(bypass, timeout))
+}
+
+func (a *artifactTab) SetCacheDisabled(disabled bool, timeout time.Duration) error {
+	return a.capture("SetCacheDisabled", a.tab.SetCacheDisabled(disabled, timeout))
+}
+
+func (a *artifactTab) SetBypassCSP(bypass bool, timeout time.Duration) error {
+	return a.capture("SetBypassCSP", a.tab.SetBypassCSP(bypass, timeout))
+}
+
+func (a *artifactTab) ClearBrowserCache(timeout time.Duration) error {
+	return a.capture("ClearBrowserCache", a.tab.ClearBrowserCache(timeout))
+}
+
+func (a *artifactTab) SecurityState(timeout time.Duration) (*SecurityState, error) {
+	result, err := a.tab.SecurityState(timeout)
+	return result, a.capture("SecurityState", err)
+}
+
+func (a *artifactTab) SetIgnoreCertificateErrors(ignore bool, timeout time.Duration) error {
+	return a.capture("SetIgnoreCertificateErrors", a.tab.SetIgnoreCertificateErrors(ignore, timeout))
+}
+
+func (a *artifactTab) OnCertificateError(handler CertificateErrorHandler, timeout time.Duration) error {
+	return a.capture("OnCertificateError", a.tab.OnCertificateError(handler, timeout))
+}
+
+func (a *artifactTab) DragAndDrop(sourceSelector, targetSelector string, opts DragAndDropOpts, timeout time.Duration) error {
+	return a.capture("DragAndDrop", a.tab.DragAndDrop(sourceSelector, targetSelector, opts, timeout))
+}
+
+func (a *artifactTab) Element(selector string, timeout time.Duration) (Element, error) {
+	result, err := a.tab.Element(selector, timeout)
+	if err != nil {
+		return nil, a.capture("Element", err)
+	}
+
+	return &artifactElement{Element: result, tab: a}, nil
+}
+
+func (a *artifactTab) Actions() *Actions {
+	actions := a.tab.Actions()
+	actions.owner = a
+	actions.capture = a.capture
+	return actions
+}
+
+func (a *artifactTab) LocalStorage() WebStorage {
+	return &artifactStorage{WebStorage: a.tab.LocalStorage(), tab: a, method: "LocalStorage"}
+}
+
+func (a *artifactTab) SessionStorage() WebStorage {
+	return &artifactStorage{WebStorage: a.tab.SessionStorage(), tab: a, method: "SessionStorage"}
+}
+
+func (a *artifactTab) SetClipboard(text string, timeout time.Duration) error {
+	return a.capture("SetClipboard", a.tab.SetClipboard(text, timeout))
+}
+
+func (a *artifactTab) ReadClipboard(timeout time.Duration) (string, error) {
+	result, err := a.tab.ReadClipboard(timeout)
+	return result, a.capture("ReadClipboard", err)
+}
+
+func (a *artifactTab) BringToFront(timeout time.Duration) error {
+	return a.capture("BringToFront", a.tab.BringToFront(timeout))
+}
+
+func (a *artifactTab) SetWindowBounds(bounds WindowBounds, timeout time.Duration) error {
+	return a.capture("SetWindowBounds", a.tab.SetWindowBounds(bounds, timeout))
+}
+
+func (a *artifactTab) Minimize(timeout time.Duration) error {
+	return a.capture("Minimize", a.tab.Minimize(timeout))
+}
+
+func (a *artifactTab) Maximize(timeout time.Duration) error {
+	return a.capture("Maximize", a.tab.Maximize(timeout))
+}
+
+func (a *artifactTab) Fullscreen(timeout time.Duration) error {
+	return a.capture("Fullscreen", a.tab.Fullscreen(timeout))
+}
+
+func (a *artifactTab) RestoreWindow(timeout time.Duration) error {
+	return a.capture("RestoreWindow", a.tab.RestoreWindow(timeout))
+}
+
+func (a *artifactTab) SetViewport(width, height int, deviceScaleFactor float64, mobile bool, timeout time.Duration) error {
+	return a.capture("SetViewport", a.tab.SetViewport(width, height, deviceScaleFactor, mobile, timeout))
+}
+
+func (a *artifactTab) ResetViewport(timeout time.Duration) error {
+	return a.capture("ResetViewport", a.tab.ResetViewport(timeout))
+}
+
+func (a *artifactTab) EmulateVisionDeficiency(deficiency VisionDeficiency, timeout time.Duration) error {
+	return a.capture("EmulateVisionDeficiency", a.tab.EmulateVisionDeficiency(deficiency, timeout))
+}
+
+func (a *artifactTab) EmulateMediaFeatures(features map[string]string, timeout time.Duration) error {
+	return a.capture("EmulateMediaFeatures", a.tab.EmulateMediaFeatures(features, timeout))
+}
+
+func (a *artifactTab) SetFocusEmulationEnabled(enabled bool, timeout time.Duration) error {
+	return a.capture("SetFocusEmulationEnabled", a.tab.SetFocusEmulationEnabled(enabled, timeout))
+}
+
+func (a *artifactTab) SetIdleOverride(userActive, screenUnlocked bool, timeout time.Duration) error {
+	return a.capture("SetIdleOverride", a.tab.SetIdleOverride(userActive, screenUnlocked, timeout))
+}
+
+func (a *artifactTab) ClearIdleOverride(timeout time.Duration) error {
+	return a.capture("ClearIdleOverride", a.tab.ClearIdleOverride(timeout))
+}
+
+func (a *artifactTab) SendCommand(method string, params interface{}, result interface{}, timeout time.Duration) error {
+	return a.capture("SendCommand", a.tab.SendCommand(method, params, result, timeout))
+}
+
+func (a *artifactTab) OnWebSocketFrame(handler WebSocketFrameHandler, timeout time.Duration) error {
+	return a.capture("OnWebSocketFrame", a.tab.OnWebSocketFrame(handler, timeout))
+}
+
+func (a *artifactTab) OnEventSourceMessage(handler EventSourceMessageHandler, timeout time.Duration) error {
+	return a.capture("OnEventSourceMessage", a.tab.OnEventSourceMessage(handler, timeout))
+}
+
+func (a *artifactTab) OnRequestFailed(handler RequestFailedHandler, timeout time.Duration) error {
+	return a.capture("OnRequestFailed", a.tab.OnRequestFailed(handler, timeout))
+}
+
+func (a *artifactTab) OnRouteChange(handler RouteChangeHandler, timeout time.Duration) error {
+	return a.capture("OnRouteChange", a.tab.OnRouteChange(handler, timeout))
+}
+
+func (a *artifactTab) SetFilterList(list *FilterList, timeout time.Duration) error {
+	return a.capture("SetFilterList", a.tab.SetFilterList(list, timeout))
+}
+
+func (a *artifactTab) EnableNetworkStats(timeout time.Duration) error {
+	return a.capture("EnableNetworkStats", a.tab.EnableNetworkStats(timeout))
+}
+
+func (a *artifactTab) Exec(javascript string, timeout time.Duration) (*runtime.EvaluateReply, error) {
+	result, err := a.tab.Exec(javascript, timeout)
+	return result, a.capture("Exec", err)
+}
+
+func (a *artifactTab) Evaluate(expression string, result interface{}, timeout time.Duration) error {
+	return a.capture("Evaluate", a.tab.Evaluate(expression, result, timeout))
+}
+
+func (a *artifactTab) GrantPermissions(origin string, timeout time.Duration, permissions ...browser.PermissionType) error {
+	return a.capture("GrantPermissions", a.tab.GrantPermissions(origin, timeout, permissions...))
+}
+
+func (a *artifactTab) StartTracing(w io.Writer, categories []string, timeout time.Duration) error {
+	return a.capture("StartTracing", a.tab.StartTracing(w, categories, timeout))
+}
+
+func (a *artifactTab) StopTracing(timeout time.Duration) error {
+	return a.capture("StopTracing", a.tab.StopTracing(timeout))
+}
+
+func (a *artifactTab) StartJSCoverage(timeout time.Duration) error {
+	return a.capture("StartJSCoverage", a.tab.StartJSCoverage(timeout))
+}
+
+func (a *artifactTab) StopJSCoverage(timeout time.Duration) ([]Coverage, error) {
+	result, err := a.tab.StopJSCoverage(timeout)
+	return result, a.capture("StopJSCoverage", err)
+}
+
+func (a *artifactTab) StartCSSCoverage(timeout time.Duration) error {
+	return a.capture("StartCSSCoverage", a.tab.StartCSSCoverage(timeout))
+}
+
+func (a *artifactTab) StopCSSCoverage(timeout time.Duration) ([]Coverage, error) {
+	result, err := a.tab.StopCSSCoverage(timeout)
+	return result, a.capture("StopCSSCoverage", err)
+}
+
+func (a *artifactTab) Metrics(timeout time.Duration) (*PerformanceMetrics, error) {
+	result, err := a.tab.Metrics(timeout)
+	return result, a.capture("Metrics", err)
+}
+
+func (a *artifactTab) AccessibilitySnapshot(opts AccessibilitySnapshotOpts, timeout time.Duration) (*AXNode, error) {
+	result, err := a.tab.AccessibilitySnapshot(opts, timeout)
+	return result, a.capture("AccessibilitySnapshot", err)
+}
+
+func (a *artifactTab) DOMSnapshot(opts DOMSnapshotOpts, timeout time.Duration) (*DOMSnapshot, error) {
+	result, err := a.tab.DOMSnapshot(opts, timeout)
+	return result, a.capture("DOMSnapshot", err)
+}
+
+func (a *artifactTab) CaptureMHTML(timeout time.Duration) (string, error) {
+	result, err := a.tab.CaptureMHTML(timeout)
+	return result, a.capture("CaptureMHTML", err)
+}
+
+func (a *artifactTab) StartScreencast(opts ScreencastOpts, timeout time.Duration) error {
+	return a.capture("StartScreencast", a.tab.StartScreencast(opts, timeout))
+}
+
+func (a *artifactTab) StopScreencast(timeout time.Duration) error {
+	return a.capture("StopScreencast", a.tab.StopScreencast(timeout))
+}
+
+func (a *artifactTab) Route(pattern string, handler RouteHandler, timeout time.Duration) error {
+	return a.capture("Route", a.tab.Route(pattern, handler, timeout))
+}
+
+func (a *artifactTab) RouteResponse(pattern string, handler RouteHandler, timeout time.Duration) error {
+	return a.capture("RouteResponse", a.tab.RouteResponse(pattern, handler, timeout))
+}
+
+func (a *artifactTab) RewriteDocument(timeout time.Duration, rewriters ...DocumentRewriter) error {
+	return a.capture("RewriteDocument", a.tab.RewriteDocument(timeout, rewriters...))
+}
+
+func (a *artifactTab) Unroute(pattern string, timeout time.Duration) error {
+	return a.capture("Unroute", a.tab.Unroute(pattern, timeout))
+}
+
+// artifactElement saves the failure artifacts of the tab of the element whenever a method returns an error
+type artifactElement struct {
+	Element
+	tab *artifactTab
+}
+
+func (e *artifactElement) Hover(timeout time.Duration) error {
+	return e.tab.capture("Element.Hover", e.Element.Hover(timeout))
+}
+
+func (e *artifactElement) Focus(timeout time.Duration) error {
+	return e.tab.capture("Element.Focus", e.Element.Focus(timeout))
+}
+
+func (e *artifactElement) Blur(timeout time.Duration) error {
+	return e.tab.capture("Element.Blur", e.Element.Blur(timeout))
+}
+
+func (e *artifactElement) SelectOption(timeout time.Duration, values ...string) error {
+	return e.tab.capture("Element.SelectOption", e.Element.SelectOption(timeout, values...))
+}
+
+func (e *artifactElement) SelectByLabel(timeout time.Duration, labels ...string) error {
+	return e.tab.capture("Element.SelectByLabel", e.Element.SelectByLabel(timeout, labels...))
+}
+
+// artifactStorage saves the failure artifacts of the tab of the storage whenever a method returns an error
+type artifactStorage struct {
+	WebStorage
+	tab *artifactTab
+	// name of the method of the tab returning the storage
+	method string
+}
+
+func (s *artifactStorage) Items(origin string, timeout time.Duration) (map[string]string, error) {
+	result, err := s.WebStorage.Items(origin, timeout)
+	return result, s.tab.capture(s.method+".Items", err)
+}
+
+func (s *artifactStorage) Get(origin, key string, timeout time.Duration) (string, bool, error) {
+	value, ok, err := s.WebStorage.Get(origin, key, timeout)
+	return value, ok, s.tab.capture(s.method+".Get", err)
+}
+
+func (s *artifactStorage) Set(origin, key, value string, timeout time.Duration) error {
+	return s.tab.capture(s.method+".Set", s.WebStorage.Set(origin, key, value, timeout))
+}
+
+func (s *artifactStorage) Remove(origin, key string, timeout time.Duration) error {
+	return s.tab.capture(s.method+".Remove", s.WebStorage.Remove(origin, key, timeout))
+}
+
+func (s *artifactStorage) Clear(origin string, timeout time.Duration) error {
+	return s.tab.capture(s.method+".Clear", s.WebStorage.Clear(origin, timeout))
+}
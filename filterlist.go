@@ -0,0 +1,362 @@
+package chrome
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/mafredri/cdp/protocol/network"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// filterTypes maps the resource type options of filter rules to the resource types reported by the browser
+var filterTypes = map[string][]network.ResourceType{
+	"document":       {network.ResourceTypeDocument},
+	"subdocument":    {network.ResourceTypeDocument},
+	"stylesheet":     {network.ResourceTypeStylesheet},
+	"image":          {network.ResourceTypeImage},
+	"media":          {network.ResourceTypeMedia},
+	"font":           {network.ResourceTypeFont},
+	"script":         {network.ResourceTypeScript},
+	"xmlhttprequest": {network.ResourceTypeXHR, network.ResourceTypeFetch},
+	"websocket":      {network.ResourceTypeWebSocket},
+	"ping":           {network.ResourceTypePing},
+	"other":          {network.ResourceTypeOther},
+}
+
+// filterHostPattern matches rules blocking a host and its subdomains, e.g. ||ads.example.com^
+var filterHostPattern = regexp.MustCompile(`^\|\|([a-z0-9.-]+)\^$`)
+
+// filterRule is a rule of a filter list matching request urls
+type filterRule struct {
+	re *regexp.Regexp
+	// resource types the rule applies to, nil for all but documents
+	types map[network.ResourceType]bool
+}
+
+func (r *filterRule) matches(requestURL string, resourceType network.ResourceType) bool {
+	if r.types == nil {
+		if resourceType == network.ResourceTypeDocument {
+			return false
+		}
+	} else if !r.types[resourceType] {
+		return false
+	}
+
+	return r.re.MatchString(requestURL)
+}
+
+// filterRules are the parsed rules of a filter list
+type filterRules struct {
+	// hosts blocked along with their subdomains, the most common kind of rule
+	hosts map[string]bool
+	block []*filterRule
+	allow []*filterRule
+}
+
+// FilterList is an EasyList style list of rules blocking requests, see Tab.SetFilterList. Rules blocking urls,
+// including exceptions and resource type options, are supported. Element hiding rules and rules with options which
+// cannot be evaluated for a request, e.g. third-party or domain, are ignored
+type FilterList struct {
+	// urls or paths the list is loaded from
+	sources []string
+	// guards rules
+	mu    sync.RWMutex
+	rules *filterRules
+}
+
+// LoadFilterList loads the filter lists at the urls or file paths into a single list
+func LoadFilterList(ctx context.Context, sources ...string) (*FilterList, error) {
+	list := &FilterList{sources: sources, rules: newFilterRules()}
+	if err := list.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// ParseFilterList parses the rules of a filter list. Refreshing the list has no effect
+func ParseFilterList(r io.Reader) (*FilterList, error) {
+	rules := newFilterRules()
+	if err := rules.parse(r); err != nil {
+		return nil, err
+	}
+
+	return &FilterList{rules: rules}, nil
+}
+
+// Refresh loads the sources of the list again, replacing its rules once all the sources are loaded
+func (f *FilterList) Refresh(ctx context.Context) error {
+	if len(f.sources) == 0 {
+		return nil
+	}
+
+	rules := newFilterRules()
+	for _, source := range f.sources {
+		if err := rules.load(ctx, source); err != nil {
+			return fmt.Errorf("go-chrome-framework: unable to load filter list %v: %w", source, err)
+		}
+	}
+
+	f.mu.Lock()
+	f.rules = rules
+	f.mu.Unlock()
+
+	return nil
+}
+
+// AutoRefresh refreshes the list at the interval until the returned function is called. Failed refreshes are logged
+// and the previous rules are kept
+func (f *FilterList) AutoRefresh(interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := f.Refresh(ctx); err != nil {
+					log.Println("go-chrome-framework error: unable to refresh filter list", err.Error())
+				}
+			}
+		}
+	}()
+
+	return cancel
+}
+
+// Blocks reports whether the list blocks the request
+func (f *FilterList) Blocks(requestURL string, resourceType network.ResourceType) bool {
+	f.mu.RLock()
+	rules := f.rules
+	f.mu.RUnlock()
+
+	for _, rule := range rules.allow {
+		if rule.matches(requestURL, resourceType) {
+			return false
+		}
+	}
+
+	if resourceType != network.ResourceTypeDocument {
+		if u, err := url.Parse(requestURL); err == nil {
+			// the host and all its parent domains
+			for host := u.Hostname(); host != ""; {
+				if rules.hosts[host] {
+					return true
+				}
+
+				i := strings.IndexByte(host, '.')
+				if i < 0 {
+					break
+				}
+				host = host[i+1:]
+			}
+		}
+	}
+
+	for _, rule := range rules.block {
+		if rule.matches(requestURL, resourceType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newFilterRules() *filterRules {
+	return &filterRules{hosts: make(map[string]bool)}
+}
+
+// load adds the rules of the filter list at the url or file path
+func (r *filterRules) load(ctx context.Context, source string) error {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		file, err := os.Open(source)
+		if err != nil {
+			return err
+		}
+		defer closeRes(file)
+
+		return r.parse(file)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer closeRes(response.Body)
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %v", response.Status)
+	}
+
+	return r.parse(response.Body)
+}
+
+// parse adds the rules of the filter list, one rule per line
+func (r *filterRules) parse(reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		r.add(strings.TrimSpace(scanner.Text()))
+	}
+
+	return scanner.Err()
+}
+
+// add adds the rule, skipping comments, element hiding rules and rules which cannot be evaluated
+func (r *filterRules) add(line string) {
+	if line == "" || strings.HasPrefix(line, "!") || strings.HasPrefix(line, "[") || strings.Contains(line, "##") ||
+		strings.Contains(line, "#@#") || strings.Contains(line, "#?#") || strings.Contains(line, "#$#") {
+		return
+	}
+
+	exception := strings.HasPrefix(line, "@@")
+	line = strings.TrimPrefix(line, "@@")
+
+	pattern, options := line, ""
+	if i := strings.LastIndexByte(line, '$'); i >= 0 && !strings.HasPrefix(line, "/") {
+		pattern, options = line[:i], line[i+1:]
+	}
+
+	rule := &filterRule{}
+	matchCase := false
+	if options != "" {
+		include := make(map[network.ResourceType]bool)
+		exclude := make(map[network.ResourceType]bool)
+		for _, option := range strings.Split(options, ",") {
+			negated := strings.HasPrefix(option, "~")
+			option = strings.TrimPrefix(option, "~")
+
+			if option == "match-case" {
+				matchCase = true
+				continue
+			}
+
+			types, ok := filterTypes[option]
+			if !ok {
+				// the rule depends on something other than the url and type of the request
+				return
+			}
+
+			for _, resourceType := range types {
+				if negated {
+					exclude[resourceType] = true
+				} else {
+					include[resourceType] = true
+				}
+			}
+		}
+
+		if len(include) > 0 || len(exclude) > 0 {
+			rule.types = include
+			if len(include) == 0 {
+				// every type but the excluded ones, documents are only matched when included explicitly
+				for _, types := range filterTypes {
+					for _, resourceType := range types {
+						if resourceType != network.ResourceTypeDocument {
+							include[resourceType] = true
+						}
+					}
+				}
+			}
+			for resourceType := range exclude {
+				delete(include, resourceType)
+			}
+		}
+	}
+
+	if !exception && rule.types == nil && !matchCase {
+		if match := filterHostPattern.FindStringSubmatch(strings.ToLower(pattern)); match != nil {
+			r.hosts[match[1]] = true
+			return
+		}
+	}
+
+	re, err := compileFilterPattern(pattern, matchCase)
+	if err != nil {
+		return
+	}
+	rule.re = re
+
+	if exception {
+		r.allow = append(r.allow, rule)
+	} else {
+		r.block = append(r.block, rule)
+	}
+}
+
+// compileFilterPattern compiles the pattern of a rule, where * matches any characters, ^ matches a separator or the
+// end of the url, | anchors the start or end of the url and || anchors the start of a domain. Patterns enclosed in
+// slashes are regular expressions
+func compileFilterPattern(pattern string, matchCase bool) (*regexp.Regexp, error) {
+	prefix := "(?i)"
+	if matchCase {
+		prefix = ""
+	}
+
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return regexp.Compile(prefix + pattern[1:len(pattern)-1])
+	}
+
+	var expr strings.Builder
+	expr.WriteString(prefix)
+
+	switch {
+	case strings.HasPrefix(pattern, "||"):
+		expr.WriteString(`^[a-z][a-z0-9+.-]*://([^/?#]*\.)?`)
+		pattern = pattern[2:]
+	case strings.HasPrefix(pattern, "|"):
+		expr.WriteString("^")
+		pattern = pattern[1:]
+	}
+
+	anchorEnd := strings.HasSuffix(pattern, "|")
+	pattern = strings.TrimSuffix(pattern, "|")
+
+	for _, c := range pattern {
+		switch c {
+		case '*':
+			expr.WriteString(".*")
+		case '^':
+			expr.WriteString(`(?:[^\w.%-]|$)`)
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	if anchorEnd {
+		expr.WriteString("$")
+	}
+
+	return regexp.Compile(expr.String())
+}
+
+// SetFilterList aborts the requests of the tab blocked by the filter list, reporting them as blocked by the client. The
+// list is consulted for every request, so refreshing it takes effect immediately. It is implemented as a route
+// matching all requests, hence routes registered afterwards take precedence over it
+func (t *tab) SetFilterList(list *FilterList, timeout time.Duration) error {
+	return t.Route("*", func(route *Route) error {
+		if !list.Blocks(route.Request().URL, route.ResourceType()) {
+			return route.Continue()
+		}
+
+		return route.Abort(network.ErrorReasonBlockedByClient)
+	}, timeout)
+}
@@ -0,0 +1,90 @@
+package chrome
+
+import (
+	"github.com/mafredri/cdp/protocol/network"
+	"strings"
+	"testing"
+)
+
+func TestFilterListBlocks(t *testing.T) {
+	list, err := ParseFilterList(strings.NewReader(`[Adblock Plus 2.0]
+! comment
+||ads.example.com^
+||tracker.example.org^$script
+/banner/*
+|https://cdn.example.com/ad.js|
+@@||ads.example.com/allowed^
+||media.example.net^$image,~third-party
+||fonts.example.net^$~font
+/\/pixel\.gif\?id=\d+/
+*/Popup.$match-case
+example.com##.ad
+`))
+	if err != nil {
+		t.Fatalf("ParseFilterList() = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		url          string
+		resourceType network.ResourceType
+		blocked      bool
+	}{
+		{"host", "https://ads.example.com/banner.png", network.ResourceTypeImage, true},
+		{"subdomain of host", "https://eu.ads.example.com/", network.ResourceTypeScript, true},
+		{"parent of host", "https://example.com/", network.ResourceTypeScript, false},
+		{"host document", "https://ads.example.com/", network.ResourceTypeDocument, false},
+		{"exception", "https://ads.example.com/allowed/ad.js", network.ResourceTypeScript, false},
+		{"type option", "https://tracker.example.org/t.js", network.ResourceTypeScript, true},
+		{"other type", "https://tracker.example.org/t.png", network.ResourceTypeImage, false},
+		{"wildcard path", "https://example.com/banner/top.png", network.ResourceTypeImage, true},
+		{"anchored url", "https://cdn.example.com/ad.js", network.ResourceTypeScript, true},
+		{"anchored url with query", "https://cdn.example.com/ad.js?v=1", network.ResourceTypeScript, false},
+		{"unsupported option", "https://media.example.net/a.png", network.ResourceTypeImage, false},
+		{"negated type", "https://fonts.example.net/a.css", network.ResourceTypeStylesheet, true},
+		{"negated type excluded", "https://fonts.example.net/a.woff", network.ResourceTypeFont, false},
+		{"regular expression", "https://example.com/pixel.gif?id=42", network.ResourceTypeImage, true},
+		{"regular expression mismatch", "https://example.com/pixel.gif?id=x", network.ResourceTypeImage, false},
+		{"match case", "https://example.com/Popup.html", network.ResourceTypeScript, true},
+		{"match case mismatch", "https://example.com/popup.html", network.ResourceTypeScript, false},
+		{"element hiding", "https://example.com/.ad", network.ResourceTypeImage, false},
+	}
+
+	for _, test := range tests {
+		if blocked := list.Blocks(test.url, test.resourceType); blocked != test.blocked {
+			t.Errorf("%v: Blocks(%q, %v) = %v, want %v", test.name, test.url, test.resourceType, blocked, test.blocked)
+		}
+	}
+}
+
+func TestCompileFilterPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		url     string
+		match   bool
+	}{
+		{"||example.com^", "https://example.com/", true},
+		{"||example.com^", "https://www.example.com:8080/", true},
+		{"||example.com^", "https://notexample.com/", false},
+		{"||example.com^", "https://example.com.evil.org/", false},
+		{"|https://example.com", "https://example.com/page", true},
+		{"|https://example.com", "http://a.org/?u=https://example.com", false},
+		{"/ads^", "https://example.com/ads?x=1", true},
+		{"/ads^", "https://example.com/ads", true},
+		{"/ads^", "https://example.com/adserver", false},
+		{"/ad*.js|", "https://example.com/ad/main.js", true},
+		{"/ad*.js|", "https://example.com/ad/main.json", false},
+		{"AD", "https://example.com/ad", true},
+	}
+
+	for _, test := range tests {
+		re, err := compileFilterPattern(test.pattern, false)
+		if err != nil {
+			t.Fatalf("compileFilterPattern(%q) = %v", test.pattern, err)
+		}
+
+		if match := re.MatchString(test.url); match != test.match {
+			t.Errorf("compileFilterPattern(%q) matches %q = %v, want %v", test.pattern, test.url, match, test.match)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp/protocol/target"
+	"log"
+	"time"
+)
+
+// FirstTabPolicy decides which tab Launch returns, see LaunchOpts.SetFirstTabPolicy
+type FirstTabPolicy int
+
+const (
+	// FirstTabReuse returns the tab chrome launches with, usually showing about:blank
+	FirstTabReuse FirstTabPolicy = iota
+	// FirstTabFresh opens a new tab and closes the one chrome launches with, so that the tab has no state left from
+	// the startup of the browser, e.g. a page restored from the profile
+	FirstTabFresh
+)
+
+// firstTab returns the tab Launch returns according to the policy, closing the initial tab when a fresh one is opened
+func (c *chrome) firstTab(initial Tab, policy FirstTabPolicy, timeout time.Duration) (Tab, error) {
+	if policy != FirstTabFresh {
+		return initial, nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.lifetime(), timeout)
+	defer cancel()
+
+	createTarget, err := c.client.Target.CreateTarget(ctx, target.NewCreateTargetArgs("about:blank"))
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to create first tab", err.Error())
+		return nil, err
+	}
+
+	// the initial tab is closed to save its memory, once the browser has another tab so that it keeps running
+	if initial.GetTargetID() != "" {
+		_, err = c.client.Target.CloseTarget(ctx, target.NewCloseTargetArgs(initial.GetTargetID()))
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to close initial tab", err.Error())
+			return nil, err
+		}
+	}
+
+	return c.publicTab(c.newTab(createTarget.TargetID)), nil
+}
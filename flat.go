@@ -0,0 +1,375 @@
+package chrome
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/target"
+	"github.com/mafredri/cdp/rpcc"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+// errSessionClosed is returned when reading from or writing to a session which has been detached
+var errSessionClosed = errors.New("go-chrome-framework: session closed")
+
+// framing reads and writes whole protocol messages from and to the browser connection
+type framing interface {
+	readMessage() ([]byte, error)
+	writeMessage(message []byte) error
+}
+
+// websocketFraming frames messages over a websocket connection, where every write is sent as a single message
+type websocketFraming struct {
+	decoder *json.Decoder
+	w       io.Writer
+}
+
+func newWebsocketFraming(conn io.ReadWriter) framing {
+	return &websocketFraming{decoder: json.NewDecoder(conn), w: conn}
+}
+
+func (w *websocketFraming) readMessage() ([]byte, error) {
+	var message json.RawMessage
+	if err := w.decoder.Decode(&message); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+func (w *websocketFraming) writeMessage(message []byte) error {
+	_, err := w.w.Write(message)
+	return err
+}
+
+// flatSessions multiplexes the sessions of tabs over the browser connection. Tabs attach to their targets with
+// Target.attachToTarget in flat mode, so that their messages carry the id of their session instead of being wrapped in
+// Target.sendMessageToTarget, and no connection is opened per tab
+type flatSessions struct {
+	// client of the browser connection, used to attach to and detach from targets
+	client *cdp.Client
+	// records the error responses of the browser connection and the sessions
+	metrics Metrics
+	// records the messages of the browser connection and the sessions, nil unless recording
+	recorder *recorder
+	// framing of the browser connection, set once the browser connection is dialed
+	framing framing
+	// serializes writes of the browser connection and the sessions
+	writeMu sync.Mutex
+	// guards sessions and err
+	mu       sync.Mutex
+	sessions map[target.SessionID]*flatSession
+	// error the browser connection failed with, sessions can no longer be connected once set
+	err error
+}
+
+func newFlatSessions(metrics Metrics, recorder *recorder) *flatSessions {
+	return &flatSessions{sessions: make(map[target.SessionID]*flatSession), metrics: metrics, recorder: recorder}
+}
+
+// unmarshal decodes the response, recording it if it is an error response
+func (f *flatSessions) unmarshal(message []byte, response *rpcc.Response) error {
+	if err := json.Unmarshal(message, response); err != nil {
+		return err
+	}
+
+	if response.Error != nil {
+		f.metrics.ObserveCDPError(cdpErrorKind(response.Error.Code))
+	}
+
+	return nil
+}
+
+// codec returns the codec of the browser connection using the framing, messages of sessions are handed to the sessions
+// instead of the browser connection
+func (f *flatSessions) codec(newFraming func(io.ReadWriter) framing) func(io.ReadWriter) rpcc.Codec {
+	return func(conn io.ReadWriter) rpcc.Codec {
+		f.framing = newFraming(conn)
+		return &browserCodec{sessions: f}
+	}
+}
+
+// start watches the browser connection for sessions being detached, the stream is closed along with the connection
+func (f *flatSessions) start(client *cdp.Client) error {
+	f.client = client
+
+	detached, err := client.Target.DetachedFromTarget(context.Background())
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer closeRes(detached)
+
+		for {
+			reply, err := detached.Recv()
+			if err != nil {
+				return
+			}
+
+			f.mu.Lock()
+			session := f.sessions[reply.SessionID]
+			f.mu.Unlock()
+
+			if session != nil {
+				session.detached()
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (f *flatSessions) write(message []byte) error {
+	f.writeMu.Lock()
+	defer f.writeMu.Unlock()
+
+	f.recorder.record(true, message)
+	return f.framing.writeMessage(message)
+}
+
+// deliver hands the message to its session, messages of unknown sessions are dropped
+func (f *flatSessions) deliver(sessionID target.SessionID, message []byte) {
+	f.mu.Lock()
+	session := f.sessions[sessionID]
+	f.mu.Unlock()
+
+	if session != nil {
+		session.deliver(message)
+	}
+}
+
+// dial attaches to the target and returns a connection exchanging messages with it over the browser connection
+func (f *flatSessions) dial(ctx context.Context, targetID target.ID) (*rpcc.Conn, error) {
+	attach, err := f.client.Target.AttachToTarget(ctx, target.NewAttachToTargetArgs(targetID).SetFlatten(true))
+	if err != nil {
+		return nil, err
+	}
+
+	return f.connect(ctx, attach.SessionID)
+}
+
+// connect returns a connection exchanging messages with the session the browser connection is attached to
+func (f *flatSessions) connect(ctx context.Context, sessionID target.SessionID) (*rpcc.Conn, error) {
+	session := &flatSession{
+		id:       sessionID,
+		sessions: f,
+		ready:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+
+	f.mu.Lock()
+	if f.err != nil {
+		f.mu.Unlock()
+		return nil, f.err
+	}
+	f.sessions[session.id] = session
+	f.mu.Unlock()
+
+	conn, err := rpcc.DialContext(ctx, string(session.id),
+		rpcc.WithDialer(func(ctx context.Context, addr string) (io.ReadWriteCloser, error) {
+			return session, nil
+		}),
+		rpcc.WithCodec(func(conn io.ReadWriter) rpcc.Codec {
+			return session
+		}),
+	)
+	if err != nil {
+		closeRes(session)
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// closeAll closes the sessions once the browser connection failed with err, so that the calls waiting for responses of
+// the sessions fail with err instead of blocking forever
+func (f *flatSessions) closeAll(err error) {
+	f.mu.Lock()
+	f.err = err
+	sessions := make([]*flatSession, 0, len(f.sessions))
+	for _, session := range f.sessions {
+		sessions = append(sessions, session)
+	}
+	f.mu.Unlock()
+
+	for _, session := range sessions {
+		session.fail(err)
+	}
+}
+
+// browserCodec is the codec of the browser connection, it hands messages carrying a session id to their session
+type browserCodec struct {
+	sessions *flatSessions
+}
+
+func (b *browserCodec) WriteRequest(request *rpcc.Request) error {
+	message, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	return b.sessions.write(message)
+}
+
+func (b *browserCodec) ReadResponse(response *rpcc.Response) error {
+	for {
+		message, err := b.sessions.framing.readMessage()
+		if err != nil {
+			// the connection is closed on read errors, the sessions multiplexed over it go along with it
+			b.sessions.closeAll(err)
+			return err
+		}
+		b.sessions.recorder.record(false, message)
+
+		var envelope struct {
+			SessionID target.SessionID `json:"sessionId"`
+		}
+		if err = json.Unmarshal(message, &envelope); err != nil {
+			b.sessions.closeAll(err)
+			return err
+		}
+
+		if envelope.SessionID == "" {
+			return b.sessions.unmarshal(message, response)
+		}
+
+		b.sessions.deliver(envelope.SessionID, message)
+	}
+}
+
+// flatSession is the transport and codec of the connection of a session, it exchanges messages through the browser
+// connection
+type flatSession struct {
+	id       target.SessionID
+	sessions *flatSessions
+	// guards queue, closed and err
+	mu sync.Mutex
+	// messages received for the session which have not been read yet, queued so that a slow session does not hold up
+	// the browser connection
+	queue [][]byte
+	// signalled when a message is queued
+	ready chan struct{}
+	// closed when the session is closed or detached
+	done   chan struct{}
+	closed bool
+	// error the reads of a closed session fail with, io.EOF if nil
+	err error
+}
+
+func (s *flatSession) deliver(message []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.queue = append(s.queue, message)
+
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (s *flatSession) WriteRequest(request *rpcc.Request) error {
+	select {
+	case <-s.done:
+		return errSessionClosed
+	default:
+	}
+
+	message, err := json.Marshal(struct {
+		*rpcc.Request
+		SessionID target.SessionID `json:"sessionId"`
+	}{request, s.id})
+	if err != nil {
+		return err
+	}
+
+	return s.sessions.write(message)
+}
+
+func (s *flatSession) ReadResponse(response *rpcc.Response) error {
+	for {
+		s.mu.Lock()
+		if len(s.queue) > 0 {
+			message := s.queue[0]
+			s.queue = s.queue[1:]
+			s.mu.Unlock()
+
+			return s.sessions.unmarshal(message, response)
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-s.ready:
+		case <-s.done:
+			s.mu.Lock()
+			err := s.err
+			s.mu.Unlock()
+
+			if err != nil {
+				return err
+			}
+			return io.EOF
+		}
+	}
+}
+
+// Read and Write are never called as messages are exchanged through the codec
+func (s *flatSession) Read(b []byte) (int, error) {
+	return 0, errSessionClosed
+}
+
+func (s *flatSession) Write(b []byte) (int, error) {
+	return 0, errSessionClosed
+}
+
+// detached closes the session after the browser detached it, e.g. because its target was closed
+func (s *flatSession) detached() {
+	s.fail(nil)
+}
+
+// fail closes the session, its reads fail with err
+func (s *flatSession) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.err = err
+	close(s.done)
+
+	s.sessions.mu.Lock()
+	delete(s.sessions.sessions, s.id)
+	s.sessions.mu.Unlock()
+}
+
+// Close detaches the session from its target, leaving the target running
+func (s *flatSession) Close() error {
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+
+	if closed {
+		return nil
+	}
+	s.detached()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := s.sessions.client.Target.DetachFromTarget(ctx, target.NewDetachFromTargetArgs().SetSessionID(s.id))
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to detach from target", err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package chrome
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/mafredri/cdp/protocol/page"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fontsReadyScript resolves once the fonts used by the document have loaded or failed to load
+const fontsReadyScript = `document.fonts ? document.fonts.ready.then(() => true) : true`
+
+// injectStyleScript adds a style element with the css to the document, once the document has a head if it has none yet
+const injectStyleScript = `((css) => {
+	const inject = () => {
+		const style = document.createElement('style');
+		style.textContent = css;
+		(document.head || document.documentElement).appendChild(style);
+	};
+	if (document.documentElement) {
+		inject();
+	} else {
+		document.addEventListener('DOMContentLoaded', inject, {once: true});
+	}
+})(%v)`
+
+// FontFace is a font loaded from a local file
+type FontFace struct {
+	// Family the font is used by in css, e.g. "Noto Sans"
+	Family string
+	// Path of a woff2, woff, ttf or otf file
+	Path string
+	// Weight and Style of the face, e.g. "700" and "italic", default to "normal"
+	Weight string
+	Style  string
+}
+
+// fontFormats maps the extensions of font files to their css formats
+var fontFormats = map[string]string{
+	".woff2": "woff2",
+	".woff":  "woff",
+	".ttf":   "truetype",
+	".otf":   "opentype",
+}
+
+// fontFaceCSS returns @font-face rules embedding the fonts as data urls
+func fontFaceCSS(fonts []FontFace) (string, error) {
+	var css strings.Builder
+
+	for _, font := range fonts {
+		format, ok := fontFormats[strings.ToLower(filepath.Ext(font.Path))]
+		if !ok {
+			return "", fmt.Errorf("go-chrome-framework: unsupported font file %v", font.Path)
+		}
+
+		data, err := os.ReadFile(font.Path)
+		if err != nil {
+			return "", err
+		}
+
+		weight, style := font.Weight, font.Style
+		if weight == "" {
+			weight = "normal"
+		}
+		if style == "" {
+			style = "normal"
+		}
+
+		fmt.Fprintf(&css, "@font-face{font-family:%v;src:url(data:font/%v;base64,%v) format(%q);font-weight:%v;font-style:%v;}\n",
+			jsString(font.Family), format, base64.StdEncoding.EncodeToString(data), format, weight, style)
+	}
+
+	return css.String(), nil
+}
+
+// WaitForFonts waits until the fonts used by the document have loaded, screenshots and pdfs wait for the fonts as well
+func (t *tab) WaitForFonts(timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	return t.waitForFonts(ctx)
+}
+
+func (t *tab) waitForFonts(ctx context.Context) error {
+	if err := t.evaluate(ctx, fontsReadyScript, nil); err != nil {
+		log.Println("go-chrome-framework error: unable to wait for fonts", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// InjectFonts makes the fonts available to the current document and the documents loaded afterwards, e.g. to render
+// scripts the host has no system fonts for instead of boxes. The font files are embedded into the pages
+func (t *tab) InjectFonts(fonts []FontFace, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	css, err := fontFaceCSS(fonts)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to read fonts", err.Error())
+		return err
+	}
+
+	if err = t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	client, err := t.currentClient()
+	if err != nil {
+		return err
+	}
+
+	script := fmt.Sprintf(injectStyleScript, jsString(css))
+
+	_, err = client.Page.AddScriptToEvaluateOnNewDocument(ctx, page.NewAddScriptToEvaluateOnNewDocumentArgs(script))
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to inject fonts into new documents", err.Error())
+		return err
+	}
+
+	if err = t.evaluate(ctx, script, nil); err != nil {
+		log.Println("go-chrome-framework error: unable to inject fonts", err.Error())
+		return err
+	}
+
+	return nil
+}
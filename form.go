@@ -0,0 +1,134 @@
+package chrome
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// fillFormScript fills the fields the way a user would, so that frameworks listening for input and change events pick
+// up the values. It returns the selectors which did not match any element
+const fillFormScript = `((fields) => {
+	const missing = [];
+	const truthy = value => ['true', 'on', '1', 'checked', 'yes'].includes(String(value).toLowerCase());
+	const fire = (el, ...types) => types.forEach(type => el.dispatchEvent(new Event(type, {bubbles: true})));
+	const setValue = (el, value) => {
+		// use the native setter so that frameworks wrapping the value property notice the change
+		const proto = Object.getPrototypeOf(el);
+		const descriptor = Object.getOwnPropertyDescriptor(proto, 'value');
+		if (descriptor && descriptor.set) {
+			descriptor.set.call(el, value);
+		} else {
+			el.value = value;
+		}
+	};
+	for (const [selector, value] of fields) {
+		const el = document.querySelector(selector);
+		if (!el) {
+			missing.push(selector);
+			continue;
+		}
+		el.focus();
+		const tag = el.tagName.toLowerCase();
+		const type = (el.type || '').toLowerCase();
+		if (tag === 'select') {
+			const values = el.multiple ? value.split(',') : [value];
+			for (const option of el.options) {
+				option.selected = values.includes(option.value);
+			}
+			fire(el, 'input', 'change');
+		} else if (type === 'checkbox') {
+			if (el.checked !== truthy(value)) {
+				el.click();
+			}
+		} else if (type === 'radio') {
+			const group = el.form && el.name ? el.form.querySelectorAll('input[type=radio][name="' + CSS.escape(el.name) + '"]') : [el];
+			const target = Array.from(group).find(radio => radio.value === value) || (truthy(value) ? el : null);
+			if (target && !target.checked) {
+				target.click();
+			}
+		} else {
+			setValue(el, value);
+			fire(el, 'input', 'change');
+		}
+		el.blur();
+	}
+	return missing;
+})(%v)`
+
+// submitScript submits the form matched by the selector, or clicks the matched submit button
+const submitScript = `((selector) => {
+	const el = document.querySelector(selector);
+	if (!el) {
+		return false;
+	}
+	if (el.tagName.toLowerCase() === 'form') {
+		el.requestSubmit ? el.requestSubmit() : el.submit();
+	} else {
+		el.click();
+	}
+	return true;
+})(%v)`
+
+// FillForm sets the values of the form fields matched by the selectors, dispatching input and change events like a
+// user would. Text like fields are set to the value, select elements select the option with the value or the comma
+// separated values for multiple selects, checkboxes are checked for values such as "true" or "on" and unchecked
+// otherwise, and radio buttons select the button of the group with the value. Fields are filled in the order of their
+// selectors
+func (t *tab) FillForm(fields map[string]string, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	pairs := make([][2]string, 0, len(fields))
+	for _, selector := range sortedKeys(fields) {
+		pairs = append(pairs, [2]string{selector, fields[selector]})
+	}
+
+	encoded, err := json.Marshal(pairs)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	if err = t.evaluate(ctx, fmt.Sprintf(fillFormScript, string(encoded)), &missing); err != nil {
+		log.Println("go-chrome-framework error: unable to fill form", err.Error())
+		return err
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %v", ErrNodeNotFound, missing[0])
+	}
+
+	return nil
+}
+
+// Submit submits the form matched by the selector, or clicks the element matched by the selector when it is not a
+// form, e.g. a submit button. It does not wait for the resulting navigation
+func (t *tab) Submit(selector string, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	var found bool
+	if err := t.evaluate(ctx, fmt.Sprintf(submitScript, jsString(selector)), &found); err != nil {
+		log.Println("go-chrome-framework error: unable to submit form", err.Error())
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("%w: %v", ErrNodeNotFound, selector)
+	}
+
+	return nil
+}
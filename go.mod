@@ -1,8 +1,11 @@
 module go.ajitem.com/gcf/v3
 
-go 1.12
+go 1.16
 
 require (
-	github.com/flowchartsman/retry v1.2.0 // indirect
 	github.com/mafredri/cdp v0.31.0
+	github.com/prometheus/client_golang v1.7.1
+	go.opentelemetry.io/otel v1.0.0
+	go.opentelemetry.io/otel/trace v1.0.0
+	gopkg.in/yaml.v3 v3.0.1
 )
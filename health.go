@@ -0,0 +1,47 @@
+package chrome
+
+import (
+	"context"
+	"errors"
+	"github.com/mafredri/cdp/devtool"
+	"log"
+)
+
+// ErrNotRunning is returned when the browser was never launched or its process has exited
+var ErrNotRunning = errors.New("go-chrome-framework: browser is not running")
+
+// Healthy verifies that the browser process is alive, its devtools endpoint responds and the browser answers a
+// protocol command. It is meant to back liveness and readiness probes
+func (c *chrome) Healthy(ctx context.Context) error {
+	// relaunching reassigns the process and the connection, check the ones of a single launch
+	c.launchMu.Lock()
+	exited, client, pipe, devtoolsAddr := c.exited, c.client, c.pipe, c.devtoolsAddr
+	c.launchMu.Unlock()
+
+	if exited == nil || client == nil {
+		return ErrNotRunning
+	}
+
+	select {
+	case <-exited:
+		return ErrNotRunning
+	default:
+	}
+
+	// browsers launched with a pipe have no devtools endpoint
+	if pipe == nil {
+		_, err := devtool.New("http://" + devtoolsAddr).Version(ctx)
+		if err != nil {
+			log.Println("go-chrome-framework error: devtools endpoint is not responding", err.Error())
+			return err
+		}
+	}
+
+	_, err := client.Target.GetTargets(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: browser is not responding to protocol commands", err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package chrome
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// imagesScript loads lazily loaded images eagerly, waits until every image has loaded and decoded or failed, and
+// returns the images which failed
+const imagesScript = `(async () => {
+	const images = Array.from(document.images);
+	for (const img of images) {
+		if (img.loading === 'lazy') {
+			img.loading = 'eager';
+		}
+	}
+	await Promise.all(images.map(async img => {
+		if (!img.complete) {
+			await new Promise(resolve => {
+				img.addEventListener('load', resolve, {once: true});
+				img.addEventListener('error', resolve, {once: true});
+			});
+		}
+		if (img.naturalWidth > 0) {
+			await img.decode().catch(() => {});
+		}
+	}));
+	return images
+		.filter(img => (img.currentSrc || img.src) && img.naturalWidth === 0)
+		.map(img => ({url: img.currentSrc || img.src, alt: img.alt}));
+})()`
+
+// FailedImage is an image of the document which failed to load or decode
+type FailedImage struct {
+	URL string `json:"url"`
+	Alt string `json:"alt"`
+}
+
+// WaitForImages waits until every image of the document has loaded and decoded, loading lazily loaded images right
+// away, and returns the images which failed to load. Images added to the document while waiting are not waited for
+func (t *tab) WaitForImages(timeout time.Duration) ([]FailedImage, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return nil, err
+	}
+
+	var failed []FailedImage
+	if err := t.evaluate(ctx, imagesScript, &failed); err != nil {
+		log.Println("go-chrome-framework error: unable to wait for images", err.Error())
+		return nil, err
+	}
+
+	return failed, nil
+}
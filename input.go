@@ -0,0 +1,45 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/dom"
+	"github.com/mafredri/cdp/protocol/input"
+)
+
+// nodeCenter scrolls the node into view and returns the center of its content box in viewport coordinates, suitable
+// for dispatching input events
+func (t *tab) nodeCenter(ctx context.Context, nodeID dom.NodeID) (float64, float64, error) {
+	var boxModel *dom.GetBoxModelReply
+	err := t.call(ctx, func(client *cdp.Client) (err error) {
+		err = client.DOM.ScrollIntoViewIfNeeded(ctx, dom.NewScrollIntoViewIfNeededArgs().SetNodeID(nodeID))
+		if err != nil {
+			return err
+		}
+
+		boxModel, err = client.DOM.GetBoxModel(ctx, dom.NewGetBoxModelArgs().SetNodeID(nodeID))
+		return err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	bounds := quadBounds(boxModel.Model.Content)
+	return bounds.X + bounds.Width/2, bounds.Y + bounds.Height/2, nil
+}
+
+// dispatchMouse dispatches a mouse event at the viewport coordinates, with the left button pressed for presses,
+// releases and moves while pressed
+func (t *tab) dispatchMouse(ctx context.Context, eventType string, x, y float64, pressed bool) error {
+	args := input.NewDispatchMouseEventArgs(eventType, x, y)
+	if pressed {
+		args.SetButton(input.MouseButtonLeft).SetButtons(1).SetClickCount(1)
+	}
+
+	client, err := t.currentClient()
+	if err != nil {
+		return err
+	}
+
+	return client.Input.DispatchMouseEvent(ctx, args)
+}
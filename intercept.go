@@ -0,0 +1,408 @@
+package chrome
+
+import (
+	"context"
+	"encoding/base64"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/fetch"
+	"github.com/mafredri/cdp/protocol/network"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Route is a request paused by the browser because its URL matched a pattern registered with Tab.Route or
+// Tab.RouteResponse. Exactly one of Fulfill, Continue or Abort should be called, requests which are left unhandled by
+// the handler are continued
+type Route struct {
+	tab     *tab
+	event   *fetch.RequestPausedReply
+	timeout time.Duration
+	handled bool
+}
+
+// RouteHandler handles a paused request
+type RouteHandler func(route *Route) error
+
+// RouteResponse is a response served to the page instead of hitting the network
+type RouteResponse struct {
+	// Status defaults to 200
+	Status int
+	// Headers are kept in order, a header may repeat, e.g. Set-Cookie
+	Headers []fetch.HeaderEntry
+	Body    []byte
+}
+
+// Fixture returns a RouteHandler which always serves the given response
+func Fixture(response RouteResponse) RouteHandler {
+	return func(route *Route) error {
+		return route.Fulfill(response)
+	}
+}
+
+func (r *Route) Request() network.Request {
+	return r.event.Request
+}
+
+func (r *Route) ResourceType() network.ResourceType {
+	return r.event.ResourceType
+}
+
+// Response returns the real response of a request intercepted with Tab.RouteResponse. It returns nil for requests
+// paused before they were sent
+func (r *Route) Response() (*RouteResponse, error) {
+	ctx, cancel := context.WithTimeout(r.tab.lifetime(), r.timeout)
+	defer cancel()
+
+	if r.event.ResponseStatusCode == nil {
+		return nil, nil
+	}
+
+	response := &RouteResponse{
+		Status:  *r.event.ResponseStatusCode,
+		Headers: append([]fetch.HeaderEntry(nil), r.event.ResponseHeaders...),
+	}
+
+	client, err := r.tab.currentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := client.Fetch.GetResponseBody(ctx, fetch.NewGetResponseBodyArgs(r.event.RequestID))
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to get response body", err.Error())
+		return nil, err
+	}
+
+	response.Body = []byte(body.Body)
+	if body.Base64Encoded {
+		response.Body, err = base64.StdEncoding.DecodeString(body.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+func (r *Route) Fulfill(response RouteResponse) error {
+	ctx, cancel := context.WithTimeout(r.tab.lifetime(), r.timeout)
+	defer cancel()
+
+	r.handled = true
+
+	if response.Status == 0 {
+		response.Status = 200
+	}
+
+	fulfillArgs := fetch.NewFulfillRequestArgs(r.event.RequestID, response.Status).
+		SetResponseHeaders(response.Headers).
+		SetBody(response.Body)
+
+	client, err := r.tab.currentClient()
+	if err != nil {
+		return err
+	}
+
+	err = client.Fetch.FulfillRequest(ctx, fulfillArgs)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to fulfill request", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r *Route) Continue() error {
+	ctx, cancel := context.WithTimeout(r.tab.lifetime(), r.timeout)
+	defer cancel()
+
+	r.handled = true
+
+	client, err := r.tab.currentClient()
+	if err != nil {
+		return err
+	}
+
+	err = client.Fetch.ContinueRequest(ctx, fetch.NewContinueRequestArgs(r.event.RequestID))
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to continue request", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r *Route) Abort(reason network.ErrorReason) error {
+	ctx, cancel := context.WithTimeout(r.tab.lifetime(), r.timeout)
+	defer cancel()
+
+	r.handled = true
+
+	client, err := r.tab.currentClient()
+	if err != nil {
+		return err
+	}
+
+	err = client.Fetch.FailRequest(ctx, fetch.NewFailRequestArgs(r.event.RequestID, reason))
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to abort request", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+type interceptRule struct {
+	pattern string
+	re      *regexp.Regexp
+	stage   fetch.RequestStage
+	handler RouteHandler
+	timeout time.Duration
+	// only requests for resources of the type match the rule, any type if empty
+	resourceType network.ResourceType
+	// rules of Tab.RewriteDocument are not removed by Unroute, a later call replaces them instead
+	rewriter bool
+}
+
+type interceptor struct {
+	// guards rules
+	mu    sync.Mutex
+	rules []*interceptRule
+	// cancels the request paused stream
+	cancel context.CancelFunc
+}
+
+func (t *tab) Route(pattern string, handler RouteHandler, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+
+	return t.intercept(&interceptRule{
+		pattern: pattern,
+		re:      compileURLPattern(pattern),
+		stage:   fetch.RequestStageRequest,
+		handler: handler,
+		timeout: timeout,
+	}, timeout)
+}
+
+// RouteResponse pauses the requests matching the pattern once their response headers are received, so that handlers
+// can read the real response with Route.Response and fulfill the request with a modified one
+func (t *tab) RouteResponse(pattern string, handler RouteHandler, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+
+	return t.intercept(&interceptRule{
+		pattern: pattern,
+		re:      compileURLPattern(pattern),
+		stage:   fetch.RequestStageResponse,
+		handler: handler,
+		timeout: timeout,
+	}, timeout)
+}
+
+// Unroute removes the routes and response routes registered with the pattern
+func (t *tab) Unroute(pattern string, timeout time.Duration) error {
+	return t.unintercept(t.resolveTimeout(timeout), func(rule *interceptRule) bool {
+		return !rule.rewriter && rule.pattern == pattern
+	})
+}
+
+// unintercept removes the rules matched by remove and makes the browser stop pausing the requests matching only them
+func (t *tab) unintercept(timeout time.Duration, remove func(rule *interceptRule) bool) error {
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	if t.interceptor == nil {
+		return nil
+	}
+
+	t.interceptor.mu.Lock()
+	t.interceptor.removeRules(remove)
+	t.interceptor.mu.Unlock()
+
+	return t.enableFetch(ctx)
+}
+
+// removeRules removes the rules matched by remove, the caller must hold mu
+func (i *interceptor) removeRules(remove func(rule *interceptRule) bool) {
+	rules := i.rules[:0]
+	for _, rule := range i.rules {
+		if !remove(rule) {
+			rules = append(rules, rule)
+		}
+	}
+	i.rules = rules
+}
+
+// intercept registers the rule and makes sure the browser pauses the requests matching it
+func (t *tab) intercept(rule *interceptRule, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if t.interceptor == nil {
+		interceptor := &interceptor{}
+		if err := t.openInterceptor(interceptor); err != nil {
+			return err
+		}
+		t.interceptor = interceptor
+	}
+
+	t.interceptor.mu.Lock()
+	if rule.rewriter {
+		t.interceptor.removeRules(func(rule *interceptRule) bool {
+			return rule.rewriter
+		})
+	}
+	t.interceptor.rules = append(t.interceptor.rules, rule)
+	t.interceptor.mu.Unlock()
+
+	return t.enableFetch(ctx)
+}
+
+// openInterceptor opens the request paused stream of the current connection and passes the paused requests to the rules
+// of the interceptor, see restoreFeatures
+func (t *tab) openInterceptor(interceptor *interceptor) error {
+	client, err := t.currentClient()
+	if err != nil {
+		return err
+	}
+
+	// requests are paused until interception is disabled, hence the stream is not bound to the timeout
+	streamCtx, streamCancel := context.WithCancel(t.lifetime())
+
+	requestPaused, err := client.Fetch.RequestPaused(streamCtx)
+	if err != nil {
+		streamCancel()
+		log.Println("go-chrome-framework error: unable to open request paused client", err.Error())
+		return err
+	}
+	interceptor.cancel = streamCancel
+
+	go func() {
+		defer closeRes(requestPaused)
+
+		for {
+			event, err := requestPaused.Recv()
+			if err != nil {
+				return
+			}
+
+			// handlers may take their time, do not hold up the other requests
+			go t.handlePaused(interceptor, event)
+		}
+	}()
+
+	return nil
+}
+
+// enableFetch updates the patterns of the requests paused by the browser to the registered rules
+func (t *tab) enableFetch(ctx context.Context) error {
+	t.interceptor.mu.Lock()
+	patterns := make([]fetch.RequestPattern, 0, len(t.interceptor.rules))
+	for _, rule := range t.interceptor.rules {
+		pattern := fetch.RequestPattern{
+			URLPattern:   String(rule.pattern),
+			RequestStage: rule.stage,
+		}
+		if rule.resourceType != "" {
+			resourceType := rule.resourceType
+			pattern.ResourceType = &resourceType
+		}
+		patterns = append(patterns, pattern)
+	}
+	t.interceptor.mu.Unlock()
+
+	if len(patterns) == 0 {
+		err := t.call(ctx, func(client *cdp.Client) error {
+			return client.Fetch.Disable(ctx)
+		})
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to disable request interception", err.Error())
+		}
+		return err
+	}
+
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.Fetch.Enable(ctx, fetch.NewEnableArgs().SetPatterns(patterns))
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to enable request interception", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// handlePaused passes the paused request to the most recently registered matching rule
+func (t *tab) handlePaused(interceptor *interceptor, event *fetch.RequestPausedReply) {
+	stage := fetch.RequestStageRequest
+	if event.ResponseStatusCode != nil || event.ResponseErrorReason != nil {
+		stage = fetch.RequestStageResponse
+	}
+
+	var match *interceptRule
+	interceptor.mu.Lock()
+	for i := len(interceptor.rules) - 1; i >= 0; i-- {
+		rule := interceptor.rules[i]
+		if rule.stage == stage && rule.re.MatchString(event.Request.URL) &&
+			(rule.resourceType == "" || rule.resourceType == event.ResourceType) {
+			match = rule
+			break
+		}
+	}
+	interceptor.mu.Unlock()
+
+	timeout := 30 * time.Second
+	if match != nil {
+		timeout = match.timeout
+	}
+
+	route := &Route{tab: t, event: event, timeout: timeout}
+	if match != nil {
+		if err := match.handler(route); err != nil {
+			log.Println("go-chrome-framework error: route handler failed", err.Error())
+		}
+	}
+
+	if !route.handled {
+		_ = route.Continue()
+	}
+}
+
+// compileURLPattern compiles a url pattern as understood by the browser, where * matches zero or more characters, ?
+// matches exactly one character and \ escapes the next character
+func compileURLPattern(pattern string) *regexp.Regexp {
+	var expr strings.Builder
+	expr.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	expr.WriteString("$")
+
+	return regexp.MustCompile(expr.String())
+}
@@ -0,0 +1,33 @@
+package chrome
+
+import "testing"
+
+func TestCompileURLPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		url     string
+		match   bool
+	}{
+		{"*", "https://example.com/", true},
+		{"https://example.com/*", "https://example.com/api/users", true},
+		{"https://example.com/*", "https://example.org/", false},
+		{"*.png", "https://example.com/logo.png", true},
+		{"*.png", "https://example.com/logo.png?size=2", false},
+		{"*.png*", "https://example.com/logo.png?size=2", true},
+		{"https://example.com/?", "https://example.com/a", true},
+		{"https://example.com/?", "https://example.com/", false},
+		{"https://example.com/?", "https://example.com/ab", false},
+		{`*\*`, "https://example.com/*", true},
+		{`*\*`, "https://example.com/a", false},
+		{`*\?q`, "https://example.com/?q", true},
+		{"https://example.com/a+b", "https://example.com/a+b", true},
+		{"https://example.com/a+b", "https://example.com/aab", false},
+		{"", "", true},
+	}
+
+	for _, test := range tests {
+		if match := compileURLPattern(test.pattern).MatchString(test.url); match != test.match {
+			t.Errorf("compileURLPattern(%q) matches %q = %v, want %v", test.pattern, test.url, match, test.match)
+		}
+	}
+}
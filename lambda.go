@@ -0,0 +1,88 @@
+package chrome
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// ErrChromiumNotFound is returned when no chromium binary is found at the locations of the common lambda layers
+var ErrChromiumNotFound = errors.New("go-chrome-framework: chromium binary not found")
+
+// lambdaChromiumPaths are where the common chromium lambda layers put the binary, layers are extracted to /opt and
+// compressed layers are decompressed to /tmp on the first invocation
+var lambdaChromiumPaths = []string{
+	"/opt/chromium",
+	"/opt/bin/chromium",
+	"/opt/headless-chromium",
+	"/opt/chrome/chrome",
+	"/opt/chrome-linux/chrome",
+	"/tmp/chromium",
+	"/tmp/headless-chromium",
+}
+
+// lambdaFontPaths are where fonts are looked for, in a layer or bundled with the function
+var lambdaFontPaths = []string{
+	"/opt/fonts",
+	"/opt/etc/fonts",
+	"/var/task/fonts",
+}
+
+// NewLambdaLaunchOpts returns launch options tuned for AWS Lambda and similar serverless runtimes: a single headless
+// process without zygote or gpu, all the state written to /tmp as the rest of the file system is read-only, no use of
+// the small /dev/shm, and fontconfig pointed at the fonts of a layer or of the function if any. The path is set to the
+// chromium binary of a layer when one is found, see LambdaChromiumPath
+func NewLambdaLaunchOpts() *LaunchOpts {
+	opts := NewLaunchOpts()
+
+	if path, err := LambdaChromiumPath(); err == nil {
+		opts.SetPath(path)
+	}
+
+	opts.SetArguments(
+		"--single-process",
+		"--no-zygote",
+		"--disable-dev-shm-usage",
+		"--disable-gpu",
+		"--disable-software-rasterizer",
+		"--use-gl=swiftshader",
+		"--in-process-gpu",
+		"--user-data-dir=/tmp/chrome-user-data",
+		"--data-path=/tmp/chrome-data",
+		"--disk-cache-dir=/tmp/chrome-cache",
+		"--homedir=/tmp",
+		"--window-size=1920,1080",
+	)
+
+	env := map[string]string{
+		// chrome and fontconfig write to the home directory
+		"HOME": "/tmp",
+	}
+	for _, path := range lambdaFontPaths {
+		if _, err := os.Stat(filepath.Join(path, "fonts.conf")); err == nil {
+			env["FONTCONFIG_PATH"] = path
+			break
+		}
+	}
+	opts.SetEnv(env)
+
+	return opts
+}
+
+// LambdaChromiumPath returns the path of the chromium binary provided by a lambda layer, or ErrChromiumNotFound. The
+// CHROME_PATH environment variable takes precedence over the common locations
+func LambdaChromiumPath() (string, error) {
+	paths := lambdaChromiumPaths
+	if path := os.Getenv("CHROME_PATH"); path != "" {
+		paths = append([]string{path}, paths...)
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+			return path, nil
+		}
+	}
+
+	return "", ErrChromiumNotFound
+}
@@ -0,0 +1,79 @@
+package chrome
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/url"
+)
+
+// errLauncherPipe is returned when launching with both a launcher and a pipe
+var errLauncherPipe = errors.New("go-chrome-framework: launchers do not support pipes")
+
+// Launcher starts the browser somewhere else than as a local process, e.g. in a container, see LaunchOpts.SetLauncher
+type Launcher interface {
+	// Start starts chrome with the configuration and returns once the browser is started, it need not be ready yet
+	Start(ctx context.Context, config LauncherConfig) (LaunchedBrowser, error)
+}
+
+// LauncherConfig is what a launcher starts chrome with
+type LauncherConfig struct {
+	// Arguments to run chrome with, including the --remote-debugging-port argument
+	Arguments []string
+	// Port chrome exposes the devtools protocol on
+	Port int
+	// Env holds the environment variables set with LaunchOpts.SetEnv
+	Env map[string]string
+	// Output receives the stdout and stderr of chrome
+	Output io.Writer
+}
+
+// LaunchedBrowser is a browser started by a launcher
+type LaunchedBrowser interface {
+	// Addr is the host and port the devtools protocol of the browser can be reached at, e.g. "127.0.0.1:32768"
+	Addr() string
+	// Wait waits for the browser to exit and returns the error it exited with
+	Wait() error
+	// Kill stops the browser and releases what the launcher allocated for it, e.g. its container
+	Kill() error
+}
+
+// startLauncher starts the browser with the launcher of the options
+func (c *chrome) startLauncher(ctx context.Context, opts *LaunchOpts, arguments []string, output io.Writer) error {
+	launched, err := opts.launcher.Start(ctx, LauncherConfig{
+		Arguments: arguments,
+		Port:      IntValue(c.port),
+		Env:       opts.env,
+		Output:    output,
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to launch chrome", err.Error())
+		return err
+	}
+
+	c.launched = launched
+	c.devtoolsAddr = launched.Addr()
+
+	// report the exit of the browser
+	c.exited = make(chan struct{})
+	go c.monitor(launched.Wait, c.exited)
+
+	return nil
+}
+
+// launchedURL points the websocket url the browser reports at the address of the browser, as a browser started by a
+// launcher reports the address it listens on, e.g. inside its container
+func (c *chrome) launchedURL(webSocketURL string) string {
+	if c.launched == nil {
+		return webSocketURL
+	}
+
+	u, err := url.Parse(webSocketURL)
+	if err != nil {
+		return webSocketURL
+	}
+	u.Host = c.devtoolsAddr
+
+	return u.String()
+}
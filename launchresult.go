@@ -0,0 +1,50 @@
+package chrome
+
+import (
+	"context"
+	"net"
+	"strconv"
+)
+
+// LaunchResult describes a launched browser, e.g. for other processes to attach to it or to correlate logs with its
+// process
+type LaunchResult struct {
+	// Tab is the first tab of the browser, as returned by Launch
+	Tab Tab
+	// WebSocketURL is the devtools endpoint of the browser, empty when launched with a pipe
+	WebSocketURL string
+	// Port the devtools protocol is exposed on, 0 when launched with a pipe
+	Port int
+	// PID of the browser process, 0 for browsers started by a Launcher
+	PID int
+	// UserDataDir is the profile directory set with --user-data-dir, empty when chrome uses a temporary profile
+	UserDataDir string
+}
+
+// LaunchWithResult launches the browser like LaunchContext and describes where it can be reached
+func (c *chrome) LaunchWithResult(ctx context.Context, opts *LaunchOpts) (*LaunchResult, error) {
+	tab, err := c.LaunchContext(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LaunchResult{
+		Tab:          tab,
+		WebSocketURL: c.browserURL,
+		UserDataDir:  c.userDataDir,
+	}
+
+	// the port of browsers started by a launcher may differ from the one they were launched with, e.g. when published
+	// by a container
+	if c.pipe == nil {
+		if _, port, err := net.SplitHostPort(c.devtoolsAddr); err == nil {
+			result.Port, _ = strconv.Atoi(port)
+		}
+	}
+
+	if c.command != nil && c.command.Process != nil {
+		result.PID = c.command.Process.Pid
+	}
+
+	return result, nil
+}
@@ -0,0 +1,261 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/target"
+	"log"
+	"time"
+)
+
+// TabCloseHandler is invoked once the page of a tab is closed
+type TabCloseHandler func()
+
+// watchTargets reports the tabs whose renderer crashed, including renderers killed for running out of memory, and
+// notifies the tabs whose target is destroyed. The browser events are published to the subscribers of Events
+func (c *chrome) watchTargets(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(c.lifetime(), timeout)
+	defer cancel()
+
+	// targets are watched until the browser is terminated, hence the streams are not bound to the timeout
+	streamCtx, streamCancel := context.WithCancel(context.Background())
+
+	targetCrashed, err := c.client.Target.TargetCrashed(streamCtx)
+	if err != nil {
+		streamCancel()
+		return err
+	}
+
+	targetDestroyed, err := c.client.Target.TargetDestroyed(streamCtx)
+	if err != nil {
+		streamCancel()
+		return err
+	}
+
+	if err = c.watchBrowserEvents(ctx, streamCtx); err != nil {
+		streamCancel()
+		return err
+	}
+
+	// target events are only sent while targets are being discovered
+	if err = c.client.Target.SetDiscoverTargets(ctx, target.NewSetDiscoverTargetsArgs(true)); err != nil {
+		streamCancel()
+		return err
+	}
+
+	c.cancelEvents = streamCancel
+
+	go func() {
+		defer closeRes(targetCrashed)
+
+		for {
+			reply, err := targetCrashed.Recv()
+			if err != nil {
+				return
+			}
+
+			log.Println("go-chrome-framework error: tab crashed", reply.TargetID, reply.Status)
+			c.publish(BrowserEvent{
+				Type:      EventTargetCrashed,
+				TargetID:  reply.TargetID,
+				Status:    reply.Status,
+				ErrorCode: reply.ErrorCode,
+			})
+			c.crashed(Crash{TargetID: reply.TargetID, Status: reply.Status})
+		}
+	}()
+
+	go func() {
+		defer closeRes(targetDestroyed)
+
+		for {
+			reply, err := targetDestroyed.Recv()
+			if err != nil {
+				return
+			}
+
+			c.publish(BrowserEvent{Type: EventTargetDestroyed, TargetID: reply.TargetID})
+
+			c.tabsMu.Lock()
+			tabs := c.tabs[reply.TargetID]
+			delete(c.tabs, reply.TargetID)
+			c.tabsMu.Unlock()
+
+			for _, tab := range tabs {
+				tab.markClosed()
+			}
+		}
+	}()
+
+	return nil
+}
+
+// closeTabs marks all the tabs of the browser closed
+func (c *chrome) closeTabs() {
+	markTabsClosed(c.takeTabs())
+}
+
+// takeTabs removes the tabs from the browser and returns them
+func (c *chrome) takeTabs() map[target.ID][]*tab {
+	c.tabsMu.Lock()
+	defer c.tabsMu.Unlock()
+
+	tabs := c.tabs
+	c.tabs = nil
+
+	return tabs
+}
+
+// markTabsClosed marks the tabs closed, invoking their close handlers
+func markTabsClosed(tabs map[target.ID][]*tab) {
+	for _, targetTabs := range tabs {
+		for _, tab := range targetTabs {
+			tab.markClosed()
+		}
+	}
+}
+
+// lifetime returns the context the calls to the tab are derived from, canceled once the tab or its browser is closed
+func (t *tab) lifetime() context.Context {
+	if t.ctx == nil {
+		return context.Background()
+	}
+
+	return t.ctx
+}
+
+// watchDetached marks the tab closed when the browser detaches the connection because the target was closed
+func (t *tab) watchDetached(client *cdp.Client) error {
+	// detached is reported at most once per connection, the stream is closed along with the connection
+	detached, err := client.Inspector.Detached(context.Background())
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer closeRes(detached)
+
+		reply, err := detached.Recv()
+		if err != nil {
+			return
+		}
+
+		if reply.Reason == "target_closed" {
+			t.markClosed()
+		}
+	}()
+
+	return nil
+}
+
+func (t *tab) OnClose(handler TabCloseHandler) {
+	t.lifecycleMu.Lock()
+	if !t.isClosed {
+		t.closeHandlers = append(t.closeHandlers, handler)
+		t.lifecycleMu.Unlock()
+		return
+	}
+	t.lifecycleMu.Unlock()
+
+	// the tab is closed already
+	handler()
+}
+
+func (t *tab) Closed() <-chan struct{} {
+	t.lifecycleMu.Lock()
+	defer t.lifecycleMu.Unlock()
+
+	if t.closed == nil {
+		t.closed = make(chan struct{})
+	}
+
+	return t.closed
+}
+
+// markClosed closes the channel returned by Closed and invokes the close handlers, once
+func (t *tab) markClosed() {
+	t.lifecycleMu.Lock()
+	if t.isClosed {
+		t.lifecycleMu.Unlock()
+		return
+	}
+
+	t.isClosed = true
+	if t.closed == nil {
+		t.closed = make(chan struct{})
+	}
+	close(t.closed)
+
+	// in-flight calls to the closed page are aborted rather than left to run until their timeouts expire
+	if t.cancel != nil {
+		t.cancel()
+	}
+
+	handlers := t.closeHandlers
+	t.closeHandlers = nil
+	t.lifecycleMu.Unlock()
+
+	t.metrics().TabClosed()
+
+	for _, handler := range handlers {
+		handler()
+	}
+}
+
+// closedState reports whether the page of the tab is closed
+func (t *tab) closedState() bool {
+	t.lifecycleMu.Lock()
+	defer t.lifecycleMu.Unlock()
+
+	return t.isClosed
+}
+
+// Close closes the page of the tab along with the connection to it. The close handlers are invoked once the page is
+// closed
+func (t *tab) Close(ctx context.Context) error {
+	var err error
+	if t.browser != nil && t.browser.client != nil {
+		_, err = t.browser.client.Target.CloseTarget(ctx, target.NewCloseTargetArgs(t.id))
+	} else if client, clientErr := t.currentClient(); clientErr == nil {
+		// without the browser the page closes itself
+		err = client.Page.Close(ctx)
+	}
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to close tab", err.Error())
+		return err
+	}
+
+	if err = t.disconnect(); err != nil {
+		log.Println("go-chrome-framework error: unable to close connection to tab", err.Error())
+	}
+
+	t.markClosed()
+
+	return nil
+}
+
+// Detach closes the connection to the tab, leaving its page running. Calling a method of the tab afterwards connects
+// to the page again
+func (t *tab) Detach(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	conn := t.conn
+	// the connection is no longer current, hence it is not re-established when it closes
+	t.conn = nil
+	t.client = nil
+	t.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+
+	if err := conn.Close(); err != nil {
+		log.Println("go-chrome-framework error: unable to detach from tab", err.Error())
+		return err
+	}
+
+	return nil
+}
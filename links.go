@@ -0,0 +1,173 @@
+package chrome
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// linksScript returns the links of the document with their resolved href, text and rel, and whether the meta robots
+// tag of the document asks for its links not to be followed
+const linksScript = `(() => {
+	const resolve = href => {
+		try {
+			return new URL(href, document.baseURI).href;
+		} catch (e) {
+			return '';
+		}
+	};
+	const robots = Array.from(document.querySelectorAll('meta[name="robots" i]'))
+		.map(meta => (meta.getAttribute('content') || '').toLowerCase());
+	return {
+		nofollow: robots.some(content => /\b(nofollow|none)\b/.test(content)),
+		links: Array.from(document.querySelectorAll(%v)).map(el => ({
+			href: resolve(el.getAttribute('href') || el.getAttribute('xlink:href') || ''),
+			text: (el.innerText || el.textContent || '').trim(),
+			rel: (el.getAttribute('rel') || '').toLowerCase().split(/\s+/).filter(rel => rel),
+		})),
+	};
+})()`
+
+// defaultLinkSelector matches the elements whose links are extracted unless LinkOpts.Selector is set
+const defaultLinkSelector = "a[href], area[href]"
+
+type Link struct {
+	// URL is the absolute url of the link, normalized with its fragment removed
+	URL string
+	// Text is the rendered text of the link
+	Text string
+	// Rel lists the relationships of the link, e.g. "nofollow" or "noopener"
+	Rel []string
+	// Nofollow is set when the link or the meta robots tag of the page asks for the link not to be followed
+	Nofollow bool
+}
+
+// ExtractLinks returns the http and https links of the page. The urls are resolved against the base url of the
+// document and normalized: the scheme and host are lowercased, default ports and fragments are removed. Every url is
+// returned once, with the metadata of its first link
+func (t *tab) ExtractLinks(opts LinkOpts, timeout time.Duration) ([]Link, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return nil, err
+	}
+
+	selector := opts.Selector
+	if selector == "" {
+		selector = defaultLinkSelector
+	}
+
+	var result struct {
+		Nofollow bool `json:"nofollow"`
+		Links    []struct {
+			Href string   `json:"href"`
+			Text string   `json:"text"`
+			Rel  []string `json:"rel"`
+		} `json:"links"`
+	}
+	if err := t.evaluate(ctx, fmt.Sprintf(linksScript, jsString(selector)), &result); err != nil {
+		log.Println("go-chrome-framework error: unable to extract links", err.Error())
+		return nil, err
+	}
+
+	var origin string
+	if opts.SameOrigin {
+		var location string
+		if err := t.evaluate(ctx, "location.href", &location); err != nil {
+			log.Println("go-chrome-framework error: unable to get url of page", err.Error())
+			return nil, err
+		}
+		origin = urlOrigin(location)
+	}
+
+	seen := make(map[string]bool)
+	links := make([]Link, 0, len(result.Links))
+	for _, l := range result.Links {
+		normalized, err := NormalizeURL(l.Href)
+		if err != nil || seen[normalized] {
+			continue
+		}
+
+		if opts.SameOrigin && urlOrigin(normalized) != origin {
+			continue
+		}
+
+		link := Link{
+			URL:      normalized,
+			Text:     l.Text,
+			Rel:      l.Rel,
+			Nofollow: result.Nofollow || hasRel(l.Rel, "nofollow"),
+		}
+		if link.Nofollow && opts.SkipNofollow {
+			continue
+		}
+
+		seen[normalized] = true
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+// NormalizeURL normalizes an absolute http or https url so that urls of the same resource compare equal: the scheme
+// and host are lowercased, default ports, fragments and empty queries are removed and an empty path becomes /
+func NormalizeURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return "", fmt.Errorf("go-chrome-framework: not an absolute http url: %v", rawURL)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if strings.Contains(host, ":") {
+		// ipv6 addresses are enclosed in brackets
+		host = "[" + host + "]"
+	}
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80" || u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	u.Host = host
+
+	u.Fragment = ""
+	u.ForceQuery = false
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	return u.String(), nil
+}
+
+// urlOrigin returns the normalized scheme and host of the url, empty if it is not an http url
+func urlOrigin(rawURL string) string {
+	normalized, err := NormalizeURL(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return ""
+	}
+
+	return u.Scheme + "://" + u.Host
+}
+
+// hasRel reports whether the relationships include rel
+func hasRel(rels []string, rel string) bool {
+	for _, r := range rels {
+		if r == rel {
+			return true
+		}
+	}
+
+	return false
+}
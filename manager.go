@@ -0,0 +1,314 @@
+package chrome
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrManagerClosed is returned when opening a tab with a closed manager
+var ErrManagerClosed = errors.New("go-chrome-framework: manager closed")
+
+type ManagerOpts struct {
+	// Browsers is the number of browsers run alongside each other, defaults to 1
+	Browsers int
+	// LaunchOpts returns the launch options of the browser in the slot, e.g. to give every browser its own port or
+	// --user-data-dir. Recycled browsers are relaunched with the options of their slot. Defaults to NewLaunchOpts
+	LaunchOpts func(slot int) *LaunchOpts
+	// MaxPages recycles a browser once this many tabs were opened in it, 0 disables recycling by page count
+	MaxPages int
+	// MaxAge recycles a browser once it has been running for this long, 0 disables recycling by age
+	MaxAge time.Duration
+	// MaxRSS recycles a browser once the resident set size in bytes of its processes exceeds it, 0 disables recycling
+	// by resident set size
+	MaxRSS int64
+	// MaxJSHeap recycles a browser once the used javascript heap in bytes of one of its tabs exceeds it, 0 disables
+	// recycling by javascript heap
+	MaxJSHeap int64
+	// MemoryInterval is how often the memory usage of the browsers is sampled when MaxRSS or MaxJSHeap is set, defaults
+	// to DefaultMemoryInterval
+	MemoryInterval time.Duration
+}
+
+// Manager supervises a fleet of browsers and opens tabs in the browser with the fewest open tabs. Browsers are
+// launched on demand and recycled after MaxPages tabs, MaxAge or once their memory usage exceeds MaxRSS or MaxJSHeap
+// to contain the memory growth of long running browsers. Browsers are recycled when the next tab is opened: a
+// recycled browser gets no new tabs and is terminated once its open tabs are closed, while a new browser takes over
+// its slot
+type Manager struct {
+	opts ManagerOpts
+	mu   sync.Mutex
+	// browsers receiving new tabs by slot, nil until launched
+	slots []*managedBrowser
+	// recycled browsers waiting for their tabs to be closed
+	retired []*managedBrowser
+	// closed is set once the manager is closed
+	closed bool
+}
+
+type managedBrowser struct {
+	browser Chrome
+	// tab the browser was launched with, handed out by the first OpenTab
+	first   Tab
+	started time.Time
+	// number of tabs opened in the browser
+	pages int
+	// number of tabs of the browser which are still open
+	open int
+	// set once the memory usage of the browser exceeded a threshold
+	pressured bool
+	// set once the browser process exited unexpectedly
+	crashed bool
+	// closed once the browser is launched, browser and first are set afterwards unless err is
+	ready chan struct{}
+	err   error
+}
+
+// NewManager returns a manager of browsers launched with the options
+func NewManager(opts ManagerOpts) *Manager {
+	if opts.Browsers < 1 {
+		opts.Browsers = 1
+	}
+
+	return &Manager{
+		opts:  opts,
+		slots: make([]*managedBrowser, opts.Browsers),
+	}
+}
+
+// OpenTab opens a tab in the browser with the fewest open tabs, launching a browser if its slot is empty. Browsers due
+// for recycling or which crashed are retired before. Tabs are given back by closing them
+func (m *Manager) OpenTab(timeout time.Duration) (Tab, error) {
+	tab, managed, idle, err := m.openTab(timeout)
+
+	// browsers are terminated without holding the lock as their tabs invoke the close handlers
+	m.terminate(idle)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tab.OnClose(func() {
+		m.tabClosed(managed)
+	})
+
+	return tab, nil
+}
+
+// openTab opens a tab and returns it along with its browser and the retired browsers which have no open tabs left.
+// Browsers are launched without holding the lock, their slot is reserved meanwhile
+func (m *Manager) openTab(timeout time.Duration) (Tab, *managedBrowser, []*managedBrowser, error) {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil, nil, nil, ErrManagerClosed
+	}
+
+	var idle []*managedBrowser
+	for slot, managed := range m.slots {
+		if managed == nil || !m.due(managed) {
+			continue
+		}
+
+		m.slots[slot] = nil
+		if managed.open == 0 {
+			idle = append(idle, managed)
+		} else {
+			m.retired = append(m.retired, managed)
+		}
+	}
+
+	slot := m.leastBusy()
+	managed := m.slots[slot]
+	launching := managed == nil
+	if launching {
+		managed = &managedBrowser{started: time.Now(), ready: make(chan struct{})}
+		m.slots[slot] = managed
+	}
+
+	// the tab is accounted for up front so that concurrent calls spread over the other slots
+	managed.pages++
+	managed.open++
+	m.mu.Unlock()
+
+	if launching {
+		m.launch(slot, managed)
+	}
+	<-managed.ready
+
+	m.mu.Lock()
+	if managed.err != nil {
+		if slot < len(m.slots) && m.slots[slot] == managed {
+			m.slots[slot] = nil
+		}
+		m.mu.Unlock()
+		return nil, nil, idle, managed.err
+	}
+
+	if m.closed {
+		m.mu.Unlock()
+		return nil, nil, idle, ErrManagerClosed
+	}
+
+	tab := managed.first
+	managed.first = nil
+	m.mu.Unlock()
+
+	if tab == nil {
+		var err error
+		tab, err = managed.browser.OpenNewTab(timeout)
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to open tab for manager", err.Error())
+			m.tabClosed(managed)
+			return nil, nil, idle, err
+		}
+	}
+
+	return tab, managed, idle, nil
+}
+
+// due reports whether the browser is to be recycled. Must be called with mu held
+func (m *Manager) due(managed *managedBrowser) bool {
+	if m.opts.MaxPages > 0 && managed.pages >= m.opts.MaxPages {
+		return true
+	}
+
+	if m.opts.MaxAge > 0 && time.Since(managed.started) >= m.opts.MaxAge {
+		return true
+	}
+
+	return managed.pressured || managed.crashed
+}
+
+// leastBusy returns the slot whose browser has the fewest open tabs, empty slots count as having none. Must be called
+// with mu held
+func (m *Manager) leastBusy() int {
+	least := 0
+	for slot := range m.slots {
+		if m.openTabs(slot) < m.openTabs(least) {
+			least = slot
+		}
+	}
+
+	return least
+}
+
+// openTabs returns the number of open tabs of the browser in the slot. Must be called with mu held
+func (m *Manager) openTabs(slot int) int {
+	if m.slots[slot] == nil {
+		return 0
+	}
+
+	return m.slots[slot].open
+}
+
+// launch launches the browser of the slot into the browser reserved for it and closes its ready channel, setting its
+// err if the launch failed. Must be called without mu held
+func (m *Manager) launch(slot int, managed *managedBrowser) {
+	defer close(managed.ready)
+
+	opts := NewLaunchOpts()
+	if m.opts.LaunchOpts != nil {
+		opts = m.opts.LaunchOpts(slot)
+	}
+
+	browser := NewChrome()
+	first, err := browser.Launch(opts)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to launch browser for manager", err.Error())
+		managed.err = err
+		return
+	}
+
+	managed.browser = browser
+	managed.first = first
+
+	// a crashed browser is recycled even when it was relaunched, its tabs are closed
+	browser.OnCrash(func(crash Crash) {
+		if crash.TargetID != "" {
+			return
+		}
+
+		m.mu.Lock()
+		managed.crashed = true
+		m.mu.Unlock()
+	})
+
+	// the monitor stops once the browser is terminated
+	if m.opts.MaxRSS > 0 || m.opts.MaxJSHeap > 0 {
+		browser.MonitorMemory(MemoryMonitorOpts{
+			Interval:  m.opts.MemoryInterval,
+			MaxRSS:    m.opts.MaxRSS,
+			MaxJSHeap: m.opts.MaxJSHeap,
+			OnPressure: func(usage *MemoryUsage) {
+				m.mu.Lock()
+				managed.pressured = true
+				m.mu.Unlock()
+			},
+		})
+	}
+}
+
+// tabClosed accounts for a closed tab of the browser, terminating the browser if it is retired and has no open tabs left
+func (m *Manager) tabClosed(managed *managedBrowser) {
+	m.mu.Lock()
+	managed.open--
+
+	var idle []*managedBrowser
+	if managed.open == 0 {
+		for i, retired := range m.retired {
+			if retired == managed {
+				m.retired = append(m.retired[:i], m.retired[i+1:]...)
+				idle = append(idle, managed)
+				break
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	m.terminate(idle)
+}
+
+// terminate terminates the browsers and returns the first error, browsers being launched are terminated once launched
+func (m *Manager) terminate(browsers []*managedBrowser) error {
+	var err error
+	for _, managed := range browsers {
+		<-managed.ready
+		if managed.err != nil {
+			continue
+		}
+
+		if terminateErr := managed.browser.Terminate(); terminateErr != nil {
+			log.Println("go-chrome-framework error: unable to terminate browser of manager", terminateErr.Error())
+			if err == nil {
+				err = terminateErr
+			}
+		}
+	}
+
+	return err
+}
+
+// Close terminates all the browsers of the manager, including the ones with open tabs. Opening tabs afterwards fails
+// with ErrManagerClosed
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil
+	}
+	m.closed = true
+
+	browsers := m.retired
+	for _, managed := range m.slots {
+		if managed != nil {
+			browsers = append(browsers, managed)
+		}
+	}
+	m.slots = nil
+	m.retired = nil
+	m.mu.Unlock()
+
+	return m.terminate(browsers)
+}
@@ -0,0 +1,143 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp/protocol/target"
+	"log"
+	"time"
+)
+
+// DefaultMemoryInterval is how often the memory usage is sampled unless MemoryMonitorOpts.Interval is set
+const DefaultMemoryInterval = 30 * time.Second
+
+type MemoryUsage struct {
+	// RSS is the resident set size in bytes of the browser process and its child processes. It is 0 when unknown, e.g.
+	// for browsers started by a Launcher or on platforms other than linux and windows
+	RSS int64
+	// Tabs is the javascript heap usage of the connected tabs of the browser
+	Tabs []TabMemoryUsage
+}
+
+type TabMemoryUsage struct {
+	TargetID target.ID
+	// JSHeapUsed is the size in bytes of the javascript objects of the page
+	JSHeapUsed int64
+	// JSHeapTotal is the size in bytes allocated for the javascript heap of the page
+	JSHeapTotal int64
+}
+
+// MemoryPressureHandler is invoked with the sampled memory usage once it exceeds a threshold of MemoryMonitorOpts
+type MemoryPressureHandler func(usage *MemoryUsage)
+
+type MemoryMonitorOpts struct {
+	// Interval between samples, defaults to DefaultMemoryInterval
+	Interval time.Duration
+	// MaxRSS is the resident set size in bytes of the browser processes above which OnPressure is invoked, 0 disables
+	// the threshold
+	MaxRSS int64
+	// MaxJSHeap is the used javascript heap in bytes of a tab above which OnPressure is invoked, 0 disables the threshold
+	MaxJSHeap int64
+	// OnPressure is invoked after every sample exceeding a threshold
+	OnPressure MemoryPressureHandler
+}
+
+// MemoryUsage samples the resident set size of the browser processes and the javascript heap of the connected tabs.
+// Tabs which are not connected are left out rather than connected to
+func (c *chrome) MemoryUsage(ctx context.Context) (*MemoryUsage, error) {
+	if c.exited == nil || c.processExited() {
+		return nil, ErrNotRunning
+	}
+
+	usage := new(MemoryUsage)
+
+	if c.processGroup != nil {
+		rss, err := c.processGroup.rss()
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to get memory usage of browser processes", err.Error())
+		}
+		usage.RSS = rss
+	}
+
+	c.tabsMu.Lock()
+	var tabs []*tab
+	for _, targetTabs := range c.tabs {
+		tabs = append(tabs, targetTabs...)
+	}
+	c.tabsMu.Unlock()
+
+	for _, t := range tabs {
+		client, err := t.currentClient()
+		if err != nil || t.closedState() {
+			continue
+		}
+
+		heap, err := client.Runtime.GetHeapUsage(ctx)
+		if err != nil {
+			// the tab may be closing, its usage is left out
+			log.Println("go-chrome-framework error: unable to get heap usage of tab", err.Error())
+			continue
+		}
+
+		usage.Tabs = append(usage.Tabs, TabMemoryUsage{
+			TargetID:    t.id,
+			JSHeapUsed:  int64(heap.UsedSize),
+			JSHeapTotal: int64(heap.TotalSize),
+		})
+	}
+
+	return usage, nil
+}
+
+// MonitorMemory samples the memory usage periodically and invokes OnPressure when a threshold is exceeded, e.g. to
+// recycle the browser before the operating system kills it for running out of memory. Monitoring stops when the
+// returned function is called or the browser is terminated
+func (c *chrome) MonitorMemory(opts MemoryMonitorOpts) func() {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultMemoryInterval
+	}
+
+	ctx, cancel := context.WithCancel(c.lifetime())
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				sampleCtx, sampleCancel := context.WithTimeout(ctx, DefaultTimeout)
+				usage, err := c.MemoryUsage(sampleCtx)
+				sampleCancel()
+				if err != nil {
+					continue
+				}
+
+				if opts.OnPressure != nil && opts.exceeded(usage) {
+					opts.OnPressure(usage)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return cancel
+}
+
+// exceeded reports whether the usage exceeds a threshold of the options
+func (m MemoryMonitorOpts) exceeded(usage *MemoryUsage) bool {
+	if m.MaxRSS > 0 && usage.RSS > m.MaxRSS {
+		return true
+	}
+
+	if m.MaxJSHeap > 0 {
+		for _, tab := range usage.Tabs {
+			if tab.JSHeapUsed > m.MaxJSHeap {
+				return true
+			}
+		}
+	}
+
+	return false
+}
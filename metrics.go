@@ -0,0 +1,64 @@
+package chrome
+
+import (
+	"time"
+)
+
+// Metrics receives measurements of the browser and its tabs, see LaunchOpts.SetMetrics. Implementations must be safe for
+// concurrent use, the metrics package provides one exporting them as prometheus metrics
+type Metrics interface {
+	// ObserveLaunch is invoked once a launch completes, err is nil if the browser launched and connected
+	ObserveLaunch(duration time.Duration, err error)
+	// TabOpened is invoked when a tab is opened, TabClosed once its page is closed
+	TabOpened()
+	TabClosed()
+	// ObserveNavigation is invoked once a navigation completes, err is nil if the navigation succeeded
+	ObserveNavigation(duration time.Duration, err error)
+	// ObserveScreenshot is invoked with the size in bytes of every captured screenshot
+	ObserveScreenshot(size int)
+	// ObserveCDPError is invoked for every error response of the browser, kind classifies the error, e.g.
+	// "invalid_params" or "server_error"
+	ObserveCDPError(kind string)
+}
+
+// nopMetrics discards the measurements of browsers launched without metrics
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveLaunch(time.Duration, error)     {}
+func (nopMetrics) TabOpened()                             {}
+func (nopMetrics) TabClosed()                             {}
+func (nopMetrics) ObserveNavigation(time.Duration, error) {}
+func (nopMetrics) ObserveScreenshot(int)                  {}
+func (nopMetrics) ObserveCDPError(string)                 {}
+
+// metrics returns the metrics the browser was launched with
+func (c *chrome) metrics() Metrics {
+	if c == nil || c.opts == nil || c.opts.metrics == nil {
+		return nopMetrics{}
+	}
+
+	return c.opts.metrics
+}
+
+// metrics returns the metrics of the browser the tab belongs to
+func (t *tab) metrics() Metrics {
+	return t.browser.metrics()
+}
+
+// cdpErrorKind classifies the json-rpc error code of an error response of the browser
+func cdpErrorKind(code int64) string {
+	switch code {
+	case -32700:
+		return "parse_error"
+	case -32600:
+		return "invalid_request"
+	case -32601:
+		return "method_not_found"
+	case -32602:
+		return "invalid_params"
+	case -32603:
+		return "internal_error"
+	default:
+		return "server_error"
+	}
+}
@@ -0,0 +1,187 @@
+// Package metrics exports the measurements of browsers and their tabs as prometheus metrics
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	chrome "go.ajitem.com/gcf/v3"
+	"time"
+)
+
+// Collector records the measurements of the browsers launched with it as prometheus metrics. It implements both
+// chrome.Metrics, to be passed to LaunchOpts.SetMetrics, and prometheus.Collector, to be registered with a registry.
+// A collector may be shared by several browsers
+type Collector struct {
+	launches           *prometheus.CounterVec
+	launchDuration     prometheus.Histogram
+	activeTabs         prometheus.Gauge
+	navigationDuration *prometheus.HistogramVec
+	screenshotSize     prometheus.Histogram
+	cdpErrors          *prometheus.CounterVec
+	networkRequests    *prometheus.CounterVec
+	networkResponses   prometheus.Counter
+	networkCacheHits   prometheus.Counter
+	networkFailed      prometheus.Counter
+	networkBytesSent   prometheus.Counter
+	networkBytesRecv   prometheus.Counter
+}
+
+// NewCollector returns a collector whose metrics are prefixed with the namespace, e.g. "renderer"
+func NewCollector(namespace string) *Collector {
+	return &Collector{
+		launches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "chrome",
+			Name:      "launches_total",
+			Help:      "Number of browser launches by result.",
+		}, []string{"result"}),
+		launchDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "chrome",
+			Name:      "launch_duration_seconds",
+			Help:      "Time taken to launch and connect to the browser.",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 10),
+		}),
+		activeTabs: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "chrome",
+			Name:      "active_tabs",
+			Help:      "Number of tabs whose page is open.",
+		}),
+		navigationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "chrome",
+			Name:      "navigation_duration_seconds",
+			Help:      "Time taken by navigations by result.",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+		}, []string{"result"}),
+		screenshotSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "chrome",
+			Name:      "screenshot_size_bytes",
+			Help:      "Size of the captured screenshots.",
+			Buckets:   prometheus.ExponentialBuckets(16*1024, 2, 10),
+		}),
+		cdpErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "chrome",
+			Name:      "cdp_errors_total",
+			Help:      "Number of error responses of the devtools protocol by kind.",
+		}, []string{"kind"}),
+		networkRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "chrome",
+			Name:      "network_requests_total",
+			Help:      "Number of requests sent by tabs by resource type.",
+		}, []string{"type"}),
+		networkResponses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "chrome",
+			Name:      "network_responses_total",
+			Help:      "Number of responses received by tabs.",
+		}),
+		networkCacheHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "chrome",
+			Name:      "network_cache_hits_total",
+			Help:      "Number of responses served from the cache.",
+		}),
+		networkFailed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "chrome",
+			Name:      "network_failed_requests_total",
+			Help:      "Number of requests which failed.",
+		}),
+		networkBytesSent: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "chrome",
+			Name:      "network_sent_bytes_total",
+			Help:      "Estimated number of bytes sent by tabs.",
+		}),
+		networkBytesRecv: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "chrome",
+			Name:      "network_received_bytes_total",
+			Help:      "Number of bytes received by tabs over the network.",
+		}),
+	}
+}
+
+func (c *Collector) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.launches,
+		c.launchDuration,
+		c.activeTabs,
+		c.navigationDuration,
+		c.screenshotSize,
+		c.cdpErrors,
+		c.networkRequests,
+		c.networkResponses,
+		c.networkCacheHits,
+		c.networkFailed,
+		c.networkBytesSent,
+		c.networkBytesRecv,
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, collector := range c.collectors() {
+		collector.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, collector := range c.collectors() {
+		collector.Collect(ch)
+	}
+}
+
+func (c *Collector) ObserveLaunch(duration time.Duration, err error) {
+	c.launches.WithLabelValues(result(err)).Inc()
+	if err == nil {
+		c.launchDuration.Observe(duration.Seconds())
+	}
+}
+
+func (c *Collector) TabOpened() {
+	c.activeTabs.Inc()
+}
+
+func (c *Collector) TabClosed() {
+	c.activeTabs.Dec()
+}
+
+func (c *Collector) ObserveNavigation(duration time.Duration, err error) {
+	c.navigationDuration.WithLabelValues(result(err)).Observe(duration.Seconds())
+}
+
+func (c *Collector) ObserveScreenshot(size int) {
+	c.screenshotSize.Observe(float64(size))
+}
+
+func (c *Collector) ObserveCDPError(kind string) {
+	c.cdpErrors.WithLabelValues(kind).Inc()
+}
+
+// ObserveNetworkStats adds the network stats of a tab to the network metrics, see Tab.NetworkStats. The stats should be
+// observed once per navigation, e.g. after the page has been rendered, as they are added up
+func (c *Collector) ObserveNetworkStats(stats chrome.NetworkStats) {
+	for resourceType, count := range stats.RequestsByType {
+		c.networkRequests.WithLabelValues(resourceType).Add(float64(count))
+	}
+	c.networkResponses.Add(float64(stats.Responses))
+	c.networkCacheHits.Add(float64(stats.CacheHits))
+	c.networkFailed.Add(float64(stats.Failed))
+	c.networkBytesSent.Add(float64(stats.BytesSent))
+	c.networkBytesRecv.Add(float64(stats.BytesReceived))
+}
+
+// result labels the outcome of an operation
+func result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+
+	return "success"
+}
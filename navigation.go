@@ -0,0 +1,60 @@
+package chrome
+
+import (
+	"errors"
+	"github.com/mafredri/cdp/protocol/network"
+	"github.com/mafredri/cdp/protocol/page"
+)
+
+// ErrBadStatus is returned when the status of the main frame document fails the NavigateOpts.FailOnStatus option
+var ErrBadStatus = errors.New("go-chrome-framework: bad navigation status")
+
+// NavigationResult describes the main frame document a navigation loaded
+type NavigationResult struct {
+	// URL of the document after following redirects
+	URL string
+	// StatusCode and StatusText of the response of the document, zero for documents without a http response, e.g.
+	// data urls
+	StatusCode int
+	StatusText string
+	MimeType   string
+	// RedirectChain lists the redirects followed to reach the document, in the order they were followed
+	RedirectChain []Redirect
+	// SecurityState of the response, e.g. "secure" or "insecure"
+	SecurityState string
+	// Certificate the document was received with, nil for documents not loaded over tls
+	Certificate *CertificateDetails
+	FrameID     page.FrameID
+	LoaderID    network.LoaderID
+}
+
+// Redirect is a redirect response followed during a navigation
+type Redirect struct {
+	URL        string
+	StatusCode int
+	// Location the redirect pointed to
+	Location string
+}
+
+// recordRequest records the redirect a request of the document carries, if any
+func (n *NavigationResult) recordRequest(ev *network.RequestWillBeSentReply) {
+	if ev.RedirectResponse == nil {
+		return
+	}
+
+	n.RedirectChain = append(n.RedirectChain, Redirect{
+		URL:        ev.RedirectResponse.URL,
+		StatusCode: ev.RedirectResponse.Status,
+		Location:   ev.Request.URL,
+	})
+}
+
+// recordResponse records the response of the document
+func (n *NavigationResult) recordResponse(ev *network.ResponseReceivedReply) {
+	n.URL = ev.Response.URL
+	n.StatusCode = ev.Response.Status
+	n.StatusText = ev.Response.StatusText
+	n.MimeType = ev.Response.MimeType
+	n.SecurityState = string(ev.Response.SecurityState)
+	n.Certificate = newCertificateDetails(ev.Response.SecurityDetails)
+}
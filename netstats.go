@@ -0,0 +1,253 @@
+package chrome
+
+import (
+	"encoding/json"
+	"github.com/mafredri/cdp/protocol/network"
+	"log"
+	"sync"
+	"time"
+)
+
+// NetworkStats accounts for the network traffic of a tab since its last main frame navigation
+type NetworkStats struct {
+	// Requests sent, in total and by resource type, e.g. "Document", "Image" or "Script"
+	Requests       int
+	RequestsByType map[string]int
+	// Responses received, CacheHits of which were served from the memory, disk or prefetch cache
+	Responses int
+	CacheHits int
+	// Failed requests
+	Failed int
+	// BytesSent is an estimate from the request lines, headers and bodies
+	BytesSent int64
+	// BytesReceived over the network, including headers
+	BytesReceived int64
+	// Since is the time of the main frame navigation the stats were reset at
+	Since time.Time
+}
+
+// CacheHitRatio is the ratio of responses served from the cache
+func (n NetworkStats) CacheHitRatio() float64 {
+	if n.Responses == 0 {
+		return 0
+	}
+
+	return float64(n.CacheHits) / float64(n.Responses)
+}
+
+// networkStats accumulates the network stats of a tab
+type networkStats struct {
+	mu    sync.Mutex
+	stats NetworkStats
+}
+
+func (n *networkStats) reset() {
+	n.stats = NetworkStats{RequestsByType: make(map[string]int), Since: time.Now()}
+}
+
+// EnableNetworkStats starts accounting for the network traffic of the tab, see NetworkStats. The stats are reset on
+// every main frame navigation
+func (t *tab) EnableNetworkStats(timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if t.networkStats != nil {
+		return nil
+	}
+
+	stats := &networkStats{}
+	stats.reset()
+	if err := t.openNetworkStats(stats); err != nil {
+		return err
+	}
+	t.networkStats = stats
+
+	return nil
+}
+
+// openNetworkStats opens the request streams of the current connection and accounts their traffic to stats, see
+// restoreFeatures
+func (t *tab) openNetworkStats(stats *networkStats) error {
+	// traffic is accounted for the lifetime of the tab, hence the streams are not bound to the timeout
+	client, err := t.currentClient()
+	if err != nil {
+		return err
+	}
+
+	requestWillBeSent, err := client.Network.RequestWillBeSent(t.lifetime())
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to open request will be sent client", err.Error())
+		return err
+	}
+
+	responseReceived, err := client.Network.ResponseReceived(t.lifetime())
+	if err != nil {
+		closeRes(requestWillBeSent)
+		log.Println("go-chrome-framework error: unable to open response received client", err.Error())
+		return err
+	}
+
+	servedFromCache, err := client.Network.RequestServedFromCache(t.lifetime())
+	if err != nil {
+		closeRes(requestWillBeSent)
+		closeRes(responseReceived)
+		log.Println("go-chrome-framework error: unable to open request served from cache client", err.Error())
+		return err
+	}
+
+	loadingFinished, err := client.Network.LoadingFinished(t.lifetime())
+	if err != nil {
+		closeRes(requestWillBeSent)
+		closeRes(responseReceived)
+		closeRes(servedFromCache)
+		log.Println("go-chrome-framework error: unable to open loading finished client", err.Error())
+		return err
+	}
+
+	loadingFailed, err := client.Network.LoadingFailed(t.lifetime())
+	if err != nil {
+		closeRes(requestWillBeSent)
+		closeRes(responseReceived)
+		closeRes(servedFromCache)
+		closeRes(loadingFinished)
+		log.Println("go-chrome-framework error: unable to open loading failed client", err.Error())
+		return err
+	}
+
+	closeAll := func() {
+		closeRes(requestWillBeSent)
+		closeRes(responseReceived)
+		closeRes(servedFromCache)
+		closeRes(loadingFinished)
+		closeRes(loadingFailed)
+	}
+
+	go func() {
+		defer closeAll()
+
+		defer t.streamsEnded(func() {
+			if t.networkStats == stats {
+				t.networkStats = nil
+			}
+		})
+
+		// requests served from the memory cache are reported without a response
+		cached := make(map[network.RequestID]bool)
+
+		for {
+			select {
+			case <-requestWillBeSent.Ready():
+				ev, err := requestWillBeSent.Recv()
+				if err != nil {
+					return
+				}
+
+				stats.mu.Lock()
+				// the main frame has the id of the target, its document request has the id of its loader
+				if ev.Type == network.ResourceTypeDocument && ev.FrameID != nil &&
+					string(*ev.FrameID) == string(t.id) && string(ev.RequestID) == string(ev.LoaderID) && ev.RedirectResponse == nil {
+					stats.reset()
+					cached = make(map[network.RequestID]bool)
+				}
+				stats.stats.Requests++
+				if ev.Type != "" {
+					stats.stats.RequestsByType[string(ev.Type)]++
+				}
+				stats.stats.BytesSent += requestSize(ev.Request)
+				stats.mu.Unlock()
+			case <-servedFromCache.Ready():
+				ev, err := servedFromCache.Recv()
+				if err != nil {
+					return
+				}
+
+				cached[ev.RequestID] = true
+			case <-responseReceived.Ready():
+				ev, err := responseReceived.Recv()
+				if err != nil {
+					return
+				}
+
+				response := ev.Response
+				hit := cached[ev.RequestID] || (response.FromDiskCache != nil && *response.FromDiskCache) ||
+					(response.FromPrefetchCache != nil && *response.FromPrefetchCache)
+				delete(cached, ev.RequestID)
+
+				stats.mu.Lock()
+				stats.stats.Responses++
+				if hit {
+					stats.stats.CacheHits++
+				}
+				stats.mu.Unlock()
+			case <-loadingFinished.Ready():
+				ev, err := loadingFinished.Recv()
+				if err != nil {
+					return
+				}
+
+				stats.mu.Lock()
+				stats.stats.BytesReceived += int64(ev.EncodedDataLength)
+				stats.mu.Unlock()
+			case <-loadingFailed.Ready():
+				ev, err := loadingFailed.Recv()
+				if err != nil {
+					return
+				}
+				delete(cached, ev.RequestID)
+
+				stats.mu.Lock()
+				stats.stats.Failed++
+				stats.mu.Unlock()
+			}
+		}
+	}()
+
+	return nil
+}
+
+// NetworkStats returns the network stats of the tab since its last main frame navigation, the stats are empty unless
+// EnableNetworkStats was called
+func (t *tab) NetworkStats() NetworkStats {
+	t.featureMu.Lock()
+	stats := t.networkStats
+	t.featureMu.Unlock()
+
+	if stats == nil {
+		return NetworkStats{RequestsByType: make(map[string]int)}
+	}
+
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	snapshot := stats.stats
+	snapshot.RequestsByType = make(map[string]int, len(stats.stats.RequestsByType))
+	for resourceType, count := range stats.stats.RequestsByType {
+		snapshot.RequestsByType[resourceType] = count
+	}
+
+	return snapshot
+}
+
+// requestSize estimates the size of the request line, headers and body of the request
+func requestSize(request network.Request) int64 {
+	size := len(request.Method) + len(request.URL) + len(" HTTP/1.1\r\n")
+
+	var headers map[string]string
+	if err := json.Unmarshal(request.Headers, &headers); err == nil {
+		for name, value := range headers {
+			size += len(name) + len(": \r\n") + len(value)
+		}
+	}
+
+	if request.PostData != nil {
+		size += len(*request.PostData)
+	}
+
+	return int64(size)
+}
@@ -1,10 +1,63 @@
 package chrome
 
+import (
+	"github.com/mafredri/cdp/protocol/page"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"time"
+)
+
 type LaunchOpts struct {
-	path string
-	port *int
+	path      string
+	port      *int
 	arguments []string
-	headless bool
+	headless  bool
+	// relaunch the browser with the same options if it exits unexpectedly
+	relaunchOnCrash bool
+	// talk to the browser over a pipe instead of a devtools port
+	pipe bool
+	// keep chrome sandbox enabled instead of launching with --no-sandbox
+	enableSandbox bool
+	// validate certificates instead of launching with --ignore-certificate-errors
+	strictTLS bool
+	// default arguments not to launch the browser with
+	removedArguments []string
+	// arguments to launch the browser with instead of DefaultArguments
+	defaultArguments []string
+	// paths of the unpacked extensions to load
+	extensions []string
+	// receives the stdout and stderr of the browser process
+	output io.Writer
+	// environment variables set for the browser process
+	env map[string]string
+	// working directory of the browser process
+	dir string
+	// filter list applied to the tabs opened by the browser
+	filterList *FilterList
+	// receives measurements of the browser and its tabs
+	metrics Metrics
+	// provides the tracer recording the spans of the browser and its tabs
+	tracerProvider trace.TracerProvider
+	// policy of retrying to connect to the browser and its tabs, DefaultRetryOpts if nil
+	retryOpts *RetryOpts
+	// detect readiness from the devtools active port file and the output of chrome instead of the http endpoint
+	activePortReadiness bool
+	// starts the browser instead of running the chrome binary at path
+	launcher Launcher
+	// run headful chrome on a virtual display when the host has none
+	virtualDisplay bool
+	// records the protocol messages exchanged with the browser
+	recorder *recorder
+	// directory failure artifacts of tabs are saved to
+	failureArtifacts string
+	// allow pages loaded over https to run scripts loaded over http
+	allowMixedContent bool
+	// time the browser has to start up and accept the connection, the budget of the retry options if 0
+	connectTimeout time.Duration
+	// whether Launch returns the tab chrome launches with or a new one
+	firstTabPolicy FirstTabPolicy
+	// url the first tab is navigated to by Launch, none if empty
+	startURL string
 }
 
 func NewLaunchOpts() *LaunchOpts {
@@ -17,10 +70,14 @@ func (l *LaunchOpts) SetPath(path string) {
 	l.path = path
 }
 
+// SetPort sets the port chrome listens on for the devtools protocol. If the port is not set or is 0 a free port is
+// picked when launching
 func (l *LaunchOpts) SetPort(port int) {
 	l.port = &port
 }
 
+// SetArguments adds arguments to launch the browser with. An argument overrides the default argument of the same flag,
+// e.g. --disable-features=TranslateUI replaces the default --disable-features argument
 func (l *LaunchOpts) SetArguments(arguments ...string) {
 	l.arguments = append(l.arguments, arguments...)
 }
@@ -29,9 +86,355 @@ func (l *LaunchOpts) SetHeadless(headless bool) {
 	l.headless = headless
 }
 
+// SetPipe launches the browser with --remote-debugging-pipe so that the devtools protocol is not exposed on a port.
+// Tabs are then controlled through sessions over the pipe. Not supported on windows
+func (l *LaunchOpts) SetPipe(pipe bool) {
+	l.pipe = pipe
+}
+
+// RemoveDefaultArgument stops the browser from being launched with the default argument of the given flag, e.g.
+// --disable-extensions or --disable-gpu
+func (l *LaunchOpts) RemoveDefaultArgument(flag string) {
+	l.removedArguments = append(l.removedArguments, flag)
+}
+
+// ReplaceDefaultArguments launches the browser with the given arguments instead of DefaultArguments
+func (l *LaunchOpts) ReplaceDefaultArguments(arguments []string) {
+	l.defaultArguments = append([]string{}, arguments...)
+}
+
+// SetExtensions loads the unpacked extensions at the given paths. Extensions are not supported by the old headless
+// mode, launch with SetHeadless(false) or --headless=new
+func (l *LaunchOpts) SetExtensions(paths ...string) {
+	l.extensions = append(l.extensions, paths...)
+}
+
+// SetOutput forwards the stdout and stderr of the browser process to w, in addition to retaining the most recent
+// output for Chrome.ProcessOutput
+func (l *LaunchOpts) SetOutput(w io.Writer) {
+	l.output = w
+}
+
+// SetEnv sets environment variables for the browser process, e.g. TZ, LANG, DISPLAY or HOME. They are added to the
+// environment of the current process, overriding variables of the same name
+func (l *LaunchOpts) SetEnv(env map[string]string) {
+	if l.env == nil {
+		l.env = make(map[string]string, len(env))
+	}
+
+	for name, value := range env {
+		l.env[name] = value
+	}
+}
+
+// SetDir sets the working directory of the browser process, defaults to the working directory of the current process
+func (l *LaunchOpts) SetDir(dir string) {
+	l.dir = dir
+}
+
+// SetEnableSandbox keeps the chrome sandbox enabled by not launching with --no-sandbox. The sandbox may require
+// additional privileges or kernel support when running inside containers
+func (l *LaunchOpts) SetEnableSandbox(enableSandbox bool) {
+	l.enableSandbox = enableSandbox
+}
+
+// SetStrictTLS makes the browser reject invalid certificates by not launching with --ignore-certificate-errors
+func (l *LaunchOpts) SetStrictTLS(strictTLS bool) {
+	l.strictTLS = strictTLS
+}
+
+// SetRelaunchOnCrash relaunches the browser with the same options if its process exits unexpectedly. The first tab of
+// the relaunched browser is passed to the crash handlers
+func (l *LaunchOpts) SetRelaunchOnCrash(relaunch bool) {
+	l.relaunchOnCrash = relaunch
+}
+
+// SetFilterList blocks the requests matching the filter list in the first tab and the tabs opened with OpenNewTab,
+// see Tab.SetFilterList
+func (l *LaunchOpts) SetFilterList(list *FilterList) {
+	l.filterList = list
+}
+
+// SetMetrics records launches, tabs, navigations, screenshots and error responses of the browser in metrics, see the
+// metrics package for prometheus metrics
+func (l *LaunchOpts) SetMetrics(metrics Metrics) {
+	l.metrics = metrics
+}
+
+// SetTracerProvider records spans of launching and connecting to the browser, and of navigating, executing javascript,
+// capturing screenshots and printing pdfs in its tabs, see Tab.SetTraceContext
+func (l *LaunchOpts) SetTracerProvider(provider trace.TracerProvider) {
+	l.tracerProvider = provider
+}
+
+// SetRetryOpts sets the policy of retrying to connect to the browser while it starts up, and to its tabs when
+// connecting fails transiently. Options which are not set take their value from DefaultRetryOpts
+func (l *LaunchOpts) SetRetryOpts(opts RetryOpts) {
+	l.retryOpts = &opts
+}
+
+// SetActivePortReadiness detects that the browser is ready from the DevToolsActivePort file written to the user data
+// dir, when launched with --user-data-dir, and the "DevTools listening on" line chrome prints to stderr, instead of
+// polling the /json/version endpoint. This is faster and more reliable when the host is under heavy load. Has no
+// effect with SetPipe
+func (l *LaunchOpts) SetActivePortReadiness(activePortReadiness bool) {
+	l.activePortReadiness = activePortReadiness
+}
+
+// SetLauncher starts the browser with the launcher instead of running the chrome binary at the path of the options, e.g.
+// a DockerLauncher running chrome in a container. The arguments, port, environment and output of the options are
+// passed to the launcher
+func (l *LaunchOpts) SetLauncher(launcher Launcher) {
+	l.launcher = launcher
+}
+
+// SetVirtualDisplay runs headful chrome on an Xvfb display started with the browser and stopped once it exits, when
+// launched with SetHeadless(false) on a linux host without a DISPLAY. Without Xvfb installed, chrome is run with
+// --ozone-platform=headless instead
+func (l *LaunchOpts) SetVirtualDisplay(virtualDisplay bool) {
+	l.virtualDisplay = virtualDisplay
+}
+
+// SetRecorder records every protocol message exchanged with the browser and its tabs to w, as lines of json, see
+// ReadRecording. The recording continues across relaunches after a crash. Recordings contain the html, screenshots and
+// cookies of the pages visited
+func (l *LaunchOpts) SetRecorder(w io.Writer) {
+	l.recorder = newRecorder(w)
+}
+
+// SetFailureArtifacts saves a screenshot, the html and the url of the page to a directory within dir whenever a method
+// of a tab of the browser returns an error, see Tab.DebugDump. The directory is named after a correlation id which is
+// logged along with the failure, error.txt in it names the method and the error
+func (l *LaunchOpts) SetFailureArtifacts(dir string) {
+	l.failureArtifacts = dir
+}
+
+// SetAllowMixedContent lets pages loaded over https run scripts, styles and frames loaded over http instead of blocking
+// them, by launching with --allow-running-insecure-content. Chrome only allows this for the whole browser, not for a
+// browser context
+func (l *LaunchOpts) SetAllowMixedContent(allow bool) {
+	l.allowMixedContent = allow
+}
+
+// SetConnectTimeout sets the time Launch waits for the browser to start up and accept the connection, including the
+// retries. It takes precedence over the budget of the retry options and defaults to 120s
+func (l *LaunchOpts) SetConnectTimeout(timeout time.Duration) {
+	l.connectTimeout = timeout
+}
+
+// SetFirstTabPolicy sets whether Launch returns the tab chrome launches with or opens a new one, closing the initial
+// tab. Defaults to FirstTabReuse
+func (l *LaunchOpts) SetFirstTabPolicy(policy FirstTabPolicy) {
+	l.firstTabPolicy = policy
+}
+
+// SetStartURL navigates the first tab to the url before Launch returns it. The filter list is applied to the tab
+// before it navigates
+func (l *LaunchOpts) SetStartURL(url string) {
+	l.startURL = url
+}
+
 type ScreenshotOpts struct {
 	Width             int
 	Height            int
 	DeviceScaleFactor float64
 	Mobile            bool
-}
\ No newline at end of file
+	// Format of the image, either "png" or "jpeg", defaults to "png"
+	Format string
+	// Quality of jpeg images from 0 to 100, defaults to 80
+	Quality int
+}
+
+type ElementScreenshotOpts struct {
+	// ScrollIntoView scrolls the element into view before capturing it, needed for lazily rendered content
+	ScrollIntoView bool
+	// Padding in pixels captured around the element
+	Padding float64
+	// Format of the image, either "png" or "jpeg", defaults to "png"
+	Format string
+	// Quality of jpeg images from 0 to 100, defaults to 80
+	Quality int
+}
+
+type ContextOpts struct {
+	proxyServer     string
+	proxyBypassList string
+	// whether the tabs of the browser context ignore certificate errors, nil leaves the browser default
+	ignoreCertificateErrors *bool
+}
+
+func NewContextOpts() *ContextOpts {
+	return &ContextOpts{}
+}
+
+// SetProxyServer sets the proxy server used by all the tabs of the browser context, e.g. "socks5://127.0.0.1:1080"
+func (c *ContextOpts) SetProxyServer(proxyServer string) {
+	c.proxyServer = proxyServer
+}
+
+// SetProxyBypassList sets a comma separated list of hosts which bypass the proxy server
+func (c *ContextOpts) SetProxyBypassList(proxyBypassList string) {
+	c.proxyBypassList = proxyBypassList
+}
+
+// SetIgnoreCertificateErrors makes the tabs opened in the browser context ignore certificate errors, or report them when
+// false, see Tab.SetIgnoreCertificateErrors
+func (c *ContextOpts) SetIgnoreCertificateErrors(ignore bool) {
+	c.ignoreCertificateErrors = &ignore
+}
+
+type AccessibilitySnapshotOpts struct {
+	// IncludeIgnored includes nodes which are ignored by assistive technologies, e.g. generic containers
+	IncludeIgnored bool
+}
+
+type DOMSnapshotOpts struct {
+	// ComputedStyles is the list of computed style properties to capture for every layout box, e.g. "display", "color"
+	ComputedStyles []string
+}
+
+type ScreencastOpts struct {
+	// Format of the frames, either "jpeg" or "png", defaults to "jpeg"
+	Format string
+	// Quality of jpeg frames from 0 to 100
+	Quality       int
+	MaxWidth      int
+	MaxHeight     int
+	EveryNthFrame int
+	// Encoder receives the frames, see NewFFmpegEncoder to record a video
+	Encoder ScreencastEncoder
+}
+
+type AutoScrollOpts struct {
+	// Step in pixels to scroll by, defaults to the height of the viewport
+	Step float64
+	// Delay after every step, defaults to 250ms
+	Delay time.Duration
+	// NetworkIdle is how long no requests must be in flight after a step, defaults to 500ms
+	NetworkIdle time.Duration
+	// StableSteps is the number of consecutive steps without the document growing after which scrolling stops,
+	// defaults to 3
+	StableSteps int
+	// MaxIterations limits the number of steps, 0 means no limit other than the timeout
+	MaxIterations int
+	// OnStep is invoked after every step with the number of the step and the height of the document. Returning an
+	// error stops scrolling and is returned by AutoScroll
+	OnStep func(iteration int, height float64) error
+}
+
+type LinkOpts struct {
+	// Selector matches the elements whose links are extracted, defaults to "a[href], area[href]"
+	Selector string
+	// SameOrigin leaves out the links to other origins than the one of the page
+	SameOrigin bool
+	// SkipNofollow leaves out the links which are not to be followed
+	SkipNofollow bool
+}
+
+type TableOpts struct {
+	// HeaderRow is the index of the row holding the column headers, rows before it are ignored
+	HeaderRow int
+	// Strict fails the extraction when a column has no matching struct field
+	Strict bool
+}
+
+type CloseTabOpts struct {
+	// RunBeforeUnload runs the beforeunload handlers of the page before closing it. By default the tab is closed
+	// without running them, as CloseTab does
+	RunBeforeUnload bool
+	// OnBeforeUnload is invoked when a beforeunload handler asks for confirmation, defaults to leaving the page
+	OnBeforeUnload BeforeUnloadHandler
+}
+
+type NavigateOpts struct {
+	// fail navigations whose document responds with a status of at least this, 0 disables the check
+	failOnStatus int
+	// disable or enable the cache of the tab before navigating, nil leaves it as is
+	cacheDisabled *bool
+	// clear the browser cache before navigating
+	clearCache bool
+	// referrer sent with the document request, none if empty
+	referrer string
+	// transition type the navigation is reported with, e.g. to the history of the browser
+	transitionType page.TransitionType
+	// frame to navigate instead of the main frame
+	frameID page.FrameID
+	// virtual time the page is fast-forwarded by after navigating
+	virtualTimeBudget time.Duration
+}
+
+func NewNavigateOpts() *NavigateOpts {
+	return &NavigateOpts{}
+}
+
+// FailOnStatus makes the navigation fail with ErrBadStatus when the main frame document responds with a status of at
+// least min, e.g. 400 to fail on client and server errors
+func (n *NavigateOpts) FailOnStatus(min int) {
+	n.failOnStatus = min
+}
+
+type PDFOpts struct {
+	Landscape       bool
+	PrintBackground bool
+	// Scale of the rendering, defaults to 1
+	Scale float64
+	// PaperWidth and PaperHeight in inches, default to letter size
+	PaperWidth  float64
+	PaperHeight float64
+	// Margins in inches, default to about 0.4 inches when nil
+	MarginTop    *float64
+	MarginBottom *float64
+	MarginLeft   *float64
+	MarginRight  *float64
+	// PageRanges to print, e.g. "1-5, 8", defaults to all pages
+	PageRanges string
+	// HeaderTemplate and FooterTemplate are html templates printed on every page, either enables the header and footer
+	HeaderTemplate string
+	FooterTemplate string
+	// PreferCSSPageSize prefers the page size defined by css over the paper size
+	PreferCSSPageSize bool
+}
+
+// SetCacheDisabled disables or enables the cache of the tab before navigating, see Tab.SetCacheDisabled. The setting
+// remains in effect for later navigations
+func (n *NavigateOpts) SetCacheDisabled(disabled bool) {
+	n.cacheDisabled = &disabled
+}
+
+// SetClearCache clears the browser cache before navigating
+func (n *NavigateOpts) SetClearCache(clearCache bool) {
+	n.clearCache = clearCache
+}
+
+// SetReferrer sends the referrer with the request of the document, e.g. for content only served to visitors coming from
+// a certain page
+func (n *NavigateOpts) SetReferrer(referrer string) {
+	n.referrer = referrer
+}
+
+// SetTransitionType sets how the navigation was initiated, e.g. page.TransitionTypeLink for following a link or
+// page.TransitionTypeTyped for typing the url, defaults to the browser default
+func (n *NavigateOpts) SetTransitionType(transitionType page.TransitionType) {
+	n.transitionType = transitionType
+}
+
+// VirtualTimeBudget fast-forwards the timers of the page by the budget once the document is committed, instead of
+// waiting out delayed rendering in real time. Virtual time is paused while requests are pending, so the page sees as
+// much time pass as it would have in real time. The navigation completes once the budget is used up, and time passes
+// in real time afterwards
+func (n *NavigateOpts) VirtualTimeBudget(budget time.Duration) {
+	n.virtualTimeBudget = budget
+}
+
+// SetFrameID navigates the frame instead of the main frame of the tab, the navigation completes once the frame stopped
+// loading. The NavigationResult describes the document of the frame
+func (n *NavigateOpts) SetFrameID(frameID page.FrameID) {
+	n.frameID = frameID
+}
+
+type DragAndDropOpts struct {
+	// Steps is the number of mouse moves from the source to the target, defaults to 10
+	Steps int
+	// Delay after every mouse move, needed by libraries which throttle their handlers
+	Delay time.Duration
+}
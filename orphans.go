@@ -0,0 +1,204 @@
+package chrome
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// tempProfilePrefixes are the prefixes of the profiles chrome creates in the temporary directory when launched without
+// a user data dir
+var tempProfilePrefixes = []string{".org.chromium.Chromium.", ".com.google.Chrome."}
+
+// singletonFiles are the files chrome locks its user data dir with, a stale lock keeps the next browser from using it
+var singletonFiles = []string{"SingletonLock", "SingletonSocket", "SingletonCookie"}
+
+// KillOrphans kills the chrome processes left behind by previous runs which use the user data dir or the devtools port
+// of the launch options, e.g. after the program crashed before terminating its browsers. Launching afterwards would
+// otherwise fail as the port is in use or the profile is locked. Only processes whose parent is gone are killed, i.e.
+// which were reparented to init or whose parent exited, browsers of running programs, the current one included, are
+// left alone. The stale locks of the user data dir are removed, as are the temporary profiles of browsers which are no
+// longer running. Call it before Launch
+func KillOrphans(opts *LaunchOpts) error {
+	dir := userDataDir(opts.arguments)
+	port := ""
+	if IntValue(opts.port) != 0 {
+		port = strconv.Itoa(IntValue(opts.port))
+	}
+
+	if dir != "" || port != "" {
+		processes, err := listProcesses()
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to list processes", err.Error())
+			return err
+		}
+
+		for pid, process := range processes {
+			if pid == os.Getpid() || !orphanOf(process.commandLine, dir, port) || !parentGone(process.ppid) {
+				continue
+			}
+
+			if err = killProcess(pid); err != nil {
+				log.Println("go-chrome-framework error: unable to kill orphaned browser process", err.Error())
+				return err
+			}
+		}
+	}
+
+	if dir != "" {
+		for _, name := range append(singletonFiles, "DevToolsActivePort") {
+			if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+				log.Println("go-chrome-framework error: unable to remove stale lock of user data dir", err.Error())
+				return err
+			}
+		}
+	}
+
+	return removeStaleTempProfiles()
+}
+
+// parentGone reports whether the parent of a process exited, in which case the process was reparented to init or its
+// parent pid refers to no running process
+func parentGone(ppid int) bool {
+	return ppid <= 1 || !processAlive(ppid)
+}
+
+// orphanOf reports whether the command line is the one of a chrome process using the user data dir or the port. The
+// flags are looked up in the command line as is since the user data dir may contain spaces
+func orphanOf(commandLine, dir, port string) bool {
+	if dir != "" {
+		for _, value := range []string{dir, filepath.Clean(dir), filepath.Clean(dir) + string(filepath.Separator)} {
+			if hasFlagValue(commandLine, "--user-data-dir", value) {
+				return true
+			}
+		}
+	}
+
+	return port != "" && hasFlagValue(commandLine, "--remote-debugging-port", port)
+}
+
+// hasFlagValue reports whether the command line sets the flag to the value, which may be quoted
+func hasFlagValue(commandLine, flag, value string) bool {
+	for rest := commandLine; ; {
+		i := strings.Index(rest, flag+"=")
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(flag)+1:]
+
+		quoted := strings.TrimPrefix(rest, `"`)
+		if strings.HasPrefix(quoted, value) {
+			// the value ends with the command line, a space or a closing quote
+			end := quoted[len(value):]
+			if end == "" || end[0] == ' ' || end[0] == '\t' || end[0] == '"' {
+				return true
+			}
+		}
+	}
+}
+
+// userDataDir returns the user data dir the browser is launched with, if the arguments set one
+func userDataDir(arguments []string) string {
+	for _, argument := range arguments {
+		if flagName(argument) == "--user-data-dir" {
+			return strings.Trim(strings.TrimPrefix(argument, "--user-data-dir="), `"`)
+		}
+	}
+
+	return ""
+}
+
+// removeStaleTempProfiles removes the temporary profiles locked by browsers of this host which are no longer running.
+// Profiles without a lock are left alone as it cannot be told whether they are in use
+func removeStaleTempProfiles() error {
+	entries, err := os.ReadDir(os.TempDir())
+	if err != nil {
+		return err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !hasTempProfilePrefix(entry.Name()) {
+			continue
+		}
+
+		profile := filepath.Join(os.TempDir(), entry.Name())
+
+		// the lock links to the host and pid of the browser using the profile, e.g. host-1234
+		lock, err := os.Readlink(filepath.Join(profile, "SingletonLock"))
+		if err != nil {
+			continue
+		}
+
+		i := strings.LastIndex(lock, "-")
+		if i < 0 || lock[:i] != hostname {
+			continue
+		}
+
+		pid, err := strconv.Atoi(lock[i+1:])
+		if err != nil || processAlive(pid) {
+			continue
+		}
+
+		if err = os.RemoveAll(profile); err != nil {
+			log.Println("go-chrome-framework error: unable to remove stale temporary profile", err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hasTempProfilePrefix reports whether the name is the one of a temporary profile of chrome
+func hasTempProfilePrefix(name string) bool {
+	for _, prefix := range tempProfilePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// process is a running process as listed by listProcesses
+type process struct {
+	ppid        int
+	commandLine string
+}
+
+// parseProcessList parses lines of a pid and the pid of the parent followed by the command line of the process
+func parseProcessList(output []byte) map[int]process {
+	processes := make(map[int]process)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+
+		ppid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+
+		// the command line is kept as is, its arguments may be separated by more than a space
+		commandLine := strings.TrimSpace(line)
+		for _, field := range fields[:2] {
+			commandLine = strings.TrimSpace(strings.TrimPrefix(commandLine, field))
+		}
+
+		processes[pid] = process{ppid: ppid, commandLine: commandLine}
+	}
+
+	return processes
+}
@@ -0,0 +1,130 @@
+package chrome
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseProcessList(t *testing.T) {
+	output := []byte(`  PID  PPID COMMAND
+    1     0 /sbin/init
+  123     1 /opt/google/chrome/chrome --user-data-dir=/home/user/My Profile --remote-debugging-port=9222
+	456	123	chrome.exe "--user-data-dir=C:\Users\user\App Data"
+
+ 789   1
+abc 1 not a pid
+ 790 abc not a ppid
+`)
+
+	want := map[int]process{
+		1: {ppid: 0, commandLine: "/sbin/init"},
+		123: {
+			ppid:        1,
+			commandLine: "/opt/google/chrome/chrome --user-data-dir=/home/user/My Profile --remote-debugging-port=9222",
+		},
+		456: {ppid: 123, commandLine: `chrome.exe "--user-data-dir=C:\Users\user\App Data"`},
+	}
+
+	if processes := parseProcessList(output); !reflect.DeepEqual(processes, want) {
+		t.Errorf("parseProcessList() = %+v, want %+v", processes, want)
+	}
+}
+
+func TestParentGone(t *testing.T) {
+	tests := []struct {
+		name string
+		ppid int
+		gone bool
+	}{
+		{"reparented to init", 1, true},
+		{"no parent", 0, true},
+		{"running parent", os.Getpid(), false},
+	}
+
+	for _, test := range tests {
+		if gone := parentGone(test.ppid); gone != test.gone {
+			t.Errorf("%v: parentGone(%v) = %v, want %v", test.name, test.ppid, gone, test.gone)
+		}
+	}
+}
+
+func TestOrphanOf(t *testing.T) {
+	tests := []struct {
+		name        string
+		commandLine string
+		dir         string
+		port        string
+		orphan      bool
+	}{
+		{
+			name:        "user data dir",
+			commandLine: "chrome --headless --user-data-dir=/tmp/profile --no-first-run",
+			dir:         "/tmp/profile",
+			orphan:      true,
+		},
+		{
+			name:        "user data dir with spaces",
+			commandLine: "chrome --user-data-dir=/home/user/My Profile --remote-debugging-port=9222",
+			dir:         "/home/user/My Profile",
+			orphan:      true,
+		},
+		{
+			name:        "quoted user data dir",
+			commandLine: `chrome.exe --user-data-dir="/home/user/My Profile" --headless`,
+			dir:         "/home/user/My Profile",
+			orphan:      true,
+		},
+		{
+			name:        "quoted flag",
+			commandLine: `chrome.exe "--user-data-dir=/home/user/My Profile"`,
+			dir:         "/home/user/My Profile",
+			orphan:      true,
+		},
+		{
+			name:        "unclean user data dir",
+			commandLine: "chrome --user-data-dir=/tmp/profile/",
+			dir:         "/tmp/./profile",
+			orphan:      true,
+		},
+		{
+			name:        "other user data dir with the same prefix",
+			commandLine: "chrome --user-data-dir=/tmp/profile-2",
+			dir:         "/tmp/profile",
+		},
+		{
+			name:        "user data dir at the end",
+			commandLine: "chrome --headless --user-data-dir=/tmp/profile",
+			dir:         "/tmp/profile",
+			orphan:      true,
+		},
+		{
+			name:        "port",
+			commandLine: "chrome --remote-debugging-port=9222 --headless",
+			port:        "9222",
+			orphan:      true,
+		},
+		{
+			name:        "other port with the same prefix",
+			commandLine: "chrome --remote-debugging-port=92223",
+			port:        "9222",
+		},
+		{
+			name:        "neither",
+			commandLine: "chrome --user-data-dir=/tmp/other --remote-debugging-port=9333",
+			dir:         "/tmp/profile",
+			port:        "9222",
+		},
+		{
+			name:        "nothing to match",
+			commandLine: "chrome --user-data-dir= --remote-debugging-port=",
+		},
+	}
+
+	for _, test := range tests {
+		if orphan := orphanOf(test.commandLine, test.dir, test.port); orphan != test.orphan {
+			t.Errorf("%v: orphanOf(%q, %q, %q) = %v, want %v", test.name, test.commandLine, test.dir, test.port,
+				orphan, test.orphan)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package chrome
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the instrumentation library reported along with the spans
+const tracerName = "go.ajitem.com/gcf/v3"
+
+// tracer returns the tracer of the tracer provider the browser was launched with, spans are not recorded without one
+func (c *chrome) tracer() trace.Tracer {
+	if c == nil || c.opts == nil || c.opts.tracerProvider == nil {
+		return trace.NewNoopTracerProvider().Tracer(tracerName)
+	}
+
+	return c.opts.tracerProvider.Tracer(tracerName)
+}
+
+// SetTraceContext makes the spans of the tab children of the span of ctx, so that the calls made on the tab show up in
+// the trace of the caller. Only the span is taken from ctx, its cancellation and deadline are ignored
+func (t *tab) SetTraceContext(ctx context.Context) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.traceContext = trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx))
+}
+
+// startSpan starts a span of the tab as a child of its trace context
+func (t *tab) startSpan(name string, attributes ...attribute.KeyValue) trace.Span {
+	t.mu.Lock()
+	ctx := t.traceContext
+	t.mu.Unlock()
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	_, span := t.browser.tracer().Start(ctx, name, trace.WithAttributes(append(attributes,
+		attribute.String("chrome.target_id", string(t.id)),
+	)...))
+
+	return span
+}
+
+// endSpan ends the span, recording the error the operation failed with if any
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,54 @@
+package chrome
+
+import (
+	"sync"
+)
+
+// processOutputSize is the number of most recent bytes of output of the chrome process that are retained
+const processOutputSize = 64 * 1024
+
+// ringBuffer retains the most recent bytes written to it
+type ringBuffer struct {
+	mu   sync.Mutex
+	data []byte
+	size int
+}
+
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{size: size}
+}
+
+func (r *ringBuffer) Write(b []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := len(b)
+	if n >= r.size {
+		r.data = append(r.data[:0], b[n-r.size:]...)
+		return n, nil
+	}
+
+	if overflow := len(r.data) + n - r.size; overflow > 0 {
+		r.data = append(r.data[:0], r.data[overflow:]...)
+	}
+	r.data = append(r.data, b...)
+
+	return n, nil
+}
+
+func (r *ringBuffer) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return string(r.data)
+}
+
+// ProcessOutput returns the most recent output written by the chrome process to its stdout and stderr, useful to
+// diagnose launch failures and crashes
+func (c *chrome) ProcessOutput() string {
+	if c.output == nil {
+		return ""
+	}
+
+	return c.output.String()
+}
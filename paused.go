@@ -0,0 +1,109 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/target"
+	"log"
+	"time"
+)
+
+// PausedTab is a tab whose page has not started loading its url yet, see Chrome.OpenNewTabPaused
+type PausedTab interface {
+	Tab
+	// Resume lets the page start loading its url
+	Resume(timeout time.Duration) error
+}
+
+type pausedTab struct {
+	Tab
+	tab *tab
+	// session the browser attached to the target while creating it, the target waits for it to resume
+	session target.SessionID
+	browser *chrome
+}
+
+// OpenNewTabPaused opens a new tab loading the url, which is paused before the page makes its first request until the
+// tab is resumed. Init scripts, routes, emulation and other settings of the tab are in effect for the very first
+// request and script of the page when configured before calling Resume
+func (c *chrome) OpenNewTabPaused(url string, timeout time.Duration) (PausedTab, error) {
+	ctx, cancel := context.WithTimeout(c.lifetime(), timeout)
+	defer cancel()
+
+	// targets are only created paused while the browser auto attaches to them
+	c.pauseMu.Lock()
+	defer c.pauseMu.Unlock()
+
+	attached, err := c.client.Target.AttachedToTarget(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to open attached to target client", err.Error())
+		return nil, err
+	}
+	defer closeRes(attached)
+
+	err = c.client.Target.SetAutoAttach(ctx, target.NewSetAutoAttachArgs(true, true).SetFlatten(true))
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to auto attach to new targets", err.Error())
+		return nil, err
+	}
+
+	createTarget, createErr := c.client.Target.CreateTarget(ctx, target.NewCreateTargetArgs(url))
+
+	if err = c.client.Target.SetAutoAttach(ctx, target.NewSetAutoAttachArgs(false, false)); err != nil {
+		log.Println("go-chrome-framework error: unable to stop auto attaching to new targets", err.Error())
+	}
+
+	if createErr != nil {
+		log.Println("go-chrome-framework error: unable to create new tab", createErr.Error())
+		return nil, createErr
+	}
+
+	for {
+		ev, err := attached.Recv()
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to get attached to target event", err.Error())
+			return nil, err
+		}
+
+		// other targets created meanwhile, e.g. by other tabs, must not stay paused
+		if ev.TargetInfo.TargetID != createTarget.TargetID {
+			if ev.WaitingForDebugger {
+				go c.resumeSession(ev.SessionID, timeout)
+			}
+			continue
+		}
+
+		tab := c.newTab(createTarget.TargetID)
+		if err = c.applyFilterList(tab, timeout); err != nil {
+			go c.resumeSession(ev.SessionID, timeout)
+			return nil, err
+		}
+
+		return &pausedTab{Tab: c.publicTab(tab), tab: tab, session: ev.SessionID, browser: c}, nil
+	}
+}
+
+func (p *pausedTab) Resume(timeout time.Duration) error {
+	timeout = p.tab.resolveTimeout(timeout)
+
+	if err := p.browser.resumeSession(p.session, timeout); err != nil {
+		log.Println("go-chrome-framework error: unable to resume tab", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// resumeSession lets the target of the auto attached session run and detaches from it
+func (c *chrome) resumeSession(sessionID target.SessionID, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(c.lifetime(), timeout)
+	defer cancel()
+
+	conn, err := c.sessions.connect(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	defer closeRes(conn)
+
+	return cdp.NewClient(conn).Runtime.RunIfWaitingForDebugger(ctx)
+}
@@ -0,0 +1,163 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/page"
+	"log"
+	"sync"
+	"time"
+)
+
+// PrintToPDF prints the page as a pdf document
+func (t *tab) PrintToPDF(opts PDFOpts, timeout time.Duration) ([]byte, error) {
+	span := t.startSpan("tab.PrintToPDF")
+	pdf, err := t.printToPDF(opts, timeout)
+	endSpan(span, err)
+
+	return pdf, err
+}
+
+func (t *tab) printToPDF(opts PDFOpts, timeout time.Duration) ([]byte, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return nil, err
+	}
+
+	if err := t.waitForFonts(ctx); err != nil {
+		return nil, err
+	}
+
+	var pdf *page.PrintToPDFReply
+	err := t.call(ctx, func(client *cdp.Client) (err error) {
+		pdf, err = client.Page.PrintToPDF(ctx, printToPDFArgs(opts))
+		return err
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to print to pdf", err.Error())
+		return nil, err
+	}
+
+	return pdf.Data, nil
+}
+
+// printToPDFArgs maps the options onto the arguments of Page.printToPDF, leaving unset options at the defaults of the
+// browser
+func printToPDFArgs(opts PDFOpts) *page.PrintToPDFArgs {
+	args := page.NewPrintToPDFArgs().
+		SetLandscape(opts.Landscape).
+		SetPrintBackground(opts.PrintBackground).
+		SetPreferCSSPageSize(opts.PreferCSSPageSize)
+
+	if opts.Scale != 0 {
+		args.SetScale(opts.Scale)
+	}
+	if opts.PaperWidth != 0 {
+		args.SetPaperWidth(opts.PaperWidth)
+	}
+	if opts.PaperHeight != 0 {
+		args.SetPaperHeight(opts.PaperHeight)
+	}
+	if opts.MarginTop != nil {
+		args.SetMarginTop(*opts.MarginTop)
+	}
+	if opts.MarginBottom != nil {
+		args.SetMarginBottom(*opts.MarginBottom)
+	}
+	if opts.MarginLeft != nil {
+		args.SetMarginLeft(*opts.MarginLeft)
+	}
+	if opts.MarginRight != nil {
+		args.SetMarginRight(*opts.MarginRight)
+	}
+	if opts.PageRanges != "" {
+		args.SetPageRanges(opts.PageRanges)
+	}
+	if opts.HeaderTemplate != "" || opts.FooterTemplate != "" {
+		args.SetDisplayHeaderFooter(true).
+			SetHeaderTemplate(opts.HeaderTemplate).
+			SetFooterTemplate(opts.FooterTemplate)
+	}
+
+	return args
+}
+
+// RenderRequest is a document to render as a pdf by RenderPDFs, either a url to navigate to or html content
+type RenderRequest struct {
+	URL  string
+	HTML string
+	// Options applied when printing the document
+	Options PDFOpts
+}
+
+// RenderResult is the pdf rendered for a RenderRequest, or the error rendering it failed with
+type RenderResult struct {
+	Request RenderRequest
+	PDF     []byte
+	Err     error
+}
+
+// RenderPDFs renders the requests as pdf documents using the tabs of the pool concurrently. The results are returned in
+// the order of the requests, a request which failed carries its error in the result without affecting the others.
+// Requests which have not been rendered when the context is done fail with the error of the context
+func RenderPDFs(ctx context.Context, pool *TabPool, requests []RenderRequest) []RenderResult {
+	results := make([]RenderResult, len(requests))
+
+	var wg sync.WaitGroup
+	for i := range requests {
+		results[i].Request = requests[i]
+
+		tab, err := pool.Acquire(ctx)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		wg.Add(1)
+		go func(result *RenderResult, tab Tab) {
+			defer wg.Done()
+			defer pool.Release(tab)
+
+			result.PDF, result.Err = renderPDF(ctx, tab, result.Request)
+		}(&results[i], tab)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// renderPDF loads the document of the request into the tab and prints it
+func renderPDF(ctx context.Context, tab Tab, request RenderRequest) ([]byte, error) {
+	timeout := contextTimeout(ctx)
+
+	var err error
+	if request.URL != "" {
+		_, err = tab.Navigate(request.URL, timeout)
+	} else {
+		err = tab.SetContent(request.HTML, timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return tab.PrintToPDF(request.Options, contextTimeout(ctx))
+}
+
+// contextTimeout returns the time left until the deadline of the context, or zero for the default timeout of a tab when
+// the context has no deadline
+func contextTimeout(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0
+	}
+
+	// a timeout of zero would fall back to the default timeout instead of failing
+	if remaining := time.Until(deadline); remaining > 0 {
+		return remaining
+	}
+
+	return time.Nanosecond
+}
@@ -0,0 +1,115 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/performance"
+	"log"
+	"time"
+)
+
+// PerformanceMetrics is a snapshot of the run-time metrics of a tab along with the navigation timing of the current page.
+// Timings are relative to the start of the navigation and are zero when the page has not reached them
+type PerformanceMetrics struct {
+	// Metrics reported by the browser, e.g. JSHeapUsedSize, Nodes, LayoutCount, ScriptDuration
+	Metrics map[string]float64
+	// TimeToFirstByte is the time at which the first byte of the main document was received
+	TimeToFirstByte time.Duration
+	// FirstContentfulPaint is the time at which the first text or image was painted
+	FirstContentfulPaint time.Duration
+	// LargestContentfulPaint is the time at which the largest text or image in the viewport was painted
+	LargestContentfulPaint time.Duration
+	// DOMContentLoaded is the time at which the DOMContentLoaded event handlers completed
+	DOMContentLoaded time.Duration
+	// Load is the time at which the load event handlers completed
+	Load time.Duration
+}
+
+// navigationTimingScript collects the navigation timing of the page in milliseconds. Largest contentful paint is only
+// available through a buffered PerformanceObserver
+const navigationTimingScript = `new Promise(resolve => {
+	const timing = {};
+	const navigation = performance.getEntriesByType('navigation')[0];
+	if (navigation) {
+		timing.ttfb = navigation.responseStart;
+		timing.domContentLoaded = navigation.domContentLoadedEventEnd;
+		timing.load = navigation.loadEventEnd;
+	}
+	const paint = performance.getEntriesByName('first-contentful-paint')[0];
+	if (paint) {
+		timing.fcp = paint.startTime;
+	}
+	const record = entries => {
+		if (entries.length) {
+			timing.lcp = entries[entries.length - 1].startTime;
+		}
+	};
+	try {
+		const observer = new PerformanceObserver(list => record(list.getEntries()));
+		observer.observe({type: 'largest-contentful-paint', buffered: true});
+		record(observer.takeRecords());
+		setTimeout(() => { observer.disconnect(); resolve(timing); }, 0);
+	} catch (e) {
+		resolve(timing);
+	}
+})`
+
+func (t *tab) Metrics(timeout time.Duration) (*PerformanceMetrics, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return nil, err
+	}
+
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.Performance.Enable(ctx, nil)
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to enable performance domain", err.Error())
+		return nil, err
+	}
+
+	var reply *performance.GetMetricsReply
+	err = t.call(ctx, func(client *cdp.Client) (err error) {
+		reply, err = client.Performance.GetMetrics(ctx)
+		return err
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to get performance metrics", err.Error())
+		return nil, err
+	}
+
+	metrics := &PerformanceMetrics{
+		Metrics: make(map[string]float64, len(reply.Metrics)),
+	}
+	for _, metric := range reply.Metrics {
+		metrics.Metrics[metric.Name] = metric.Value
+	}
+
+	var timing struct {
+		TTFB             float64 `json:"ttfb"`
+		FCP              float64 `json:"fcp"`
+		LCP              float64 `json:"lcp"`
+		DOMContentLoaded float64 `json:"domContentLoaded"`
+		Load             float64 `json:"load"`
+	}
+	if err = t.evaluate(ctx, navigationTimingScript, &timing); err != nil {
+		log.Println("go-chrome-framework error: unable to get navigation timing", err.Error())
+		return nil, err
+	}
+
+	metrics.TimeToFirstByte = milliseconds(timing.TTFB)
+	metrics.FirstContentfulPaint = milliseconds(timing.FCP)
+	metrics.LargestContentfulPaint = milliseconds(timing.LCP)
+	metrics.DOMContentLoaded = milliseconds(timing.DOMContentLoaded)
+	metrics.Load = milliseconds(timing.Load)
+
+	return metrics, nil
+}
+
+// milliseconds converts a DOMHighResTimeStamp to a duration
+func milliseconds(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
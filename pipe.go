@@ -0,0 +1,75 @@
+package chrome
+
+import (
+	"bufio"
+	"io"
+	"os"
+)
+
+// pipe is the connection to a browser launched with --remote-debugging-pipe. The browser reads commands from file
+// descriptor 3 and writes responses and events to file descriptor 4
+type pipe struct {
+	// read end of the pipe the browser writes to
+	r *os.File
+	// write end of the pipe the browser reads from
+	w *os.File
+}
+
+// newPipe creates the pipes to communicate with the browser and returns them along with the files to be passed to the
+// browser process as file descriptors 3 and 4
+func newPipe() (*pipe, []*os.File, error) {
+	browserIn, w, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r, browserOut, err := os.Pipe()
+	if err != nil {
+		closeRes(browserIn)
+		closeRes(w)
+		return nil, nil, err
+	}
+
+	return &pipe{r: r, w: w}, []*os.File{browserIn, browserOut}, nil
+}
+
+func (p *pipe) Read(b []byte) (int, error) {
+	return p.r.Read(b)
+}
+
+func (p *pipe) Write(b []byte) (int, error) {
+	return p.w.Write(b)
+}
+
+func (p *pipe) Close() error {
+	err := p.w.Close()
+	if rErr := p.r.Close(); err == nil {
+		err = rErr
+	}
+
+	return err
+}
+
+// pipeFraming frames the messages exchanged over the pipe as null terminated JSON
+type pipeFraming struct {
+	w io.Writer
+	r *bufio.Reader
+}
+
+func newPipeFraming(conn io.ReadWriter) framing {
+	return &pipeFraming{w: conn, r: bufio.NewReader(conn)}
+}
+
+func (p *pipeFraming) readMessage() ([]byte, error) {
+	message, err := p.r.ReadBytes(0)
+	if err != nil {
+		return nil, err
+	}
+
+	return message[:len(message)-1], nil
+}
+
+func (p *pipeFraming) writeMessage(message []byte) error {
+	_, err := p.w.Write(append(message, 0))
+	return err
+}
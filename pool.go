@@ -0,0 +1,110 @@
+package chrome
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+)
+
+// ErrPoolClosed is returned when acquiring a tab from a closed pool
+var ErrPoolClosed = errors.New("go-chrome-framework: tab pool closed")
+
+// TabPool hands out a bounded number of tabs of a browser for reuse. Tabs are opened on demand the first time they are
+// needed and closed when the pool is closed
+type TabPool struct {
+	browser Chrome
+	// holds a token for every tab which may be acquired
+	slots chan struct{}
+	mu    sync.Mutex
+	idle  []Tab
+	all   []Tab
+	// closed is set once the pool is closed
+	closed bool
+}
+
+// NewTabPool returns a pool of up to size tabs of the browser
+func NewTabPool(browser Chrome, size int) *TabPool {
+	if size < 1 {
+		size = 1
+	}
+
+	pool := &TabPool{
+		browser: browser,
+		slots:   make(chan struct{}, size),
+	}
+	for i := 0; i < size; i++ {
+		pool.slots <- struct{}{}
+	}
+
+	return pool
+}
+
+// Acquire returns an idle tab of the pool, opening a new tab if none is idle. It blocks until a tab is released when all
+// the tabs are in use, or until the context is done. Acquired tabs must be given back with Release
+func (p *TabPool) Acquire(ctx context.Context) (Tab, error) {
+	select {
+	case <-p.slots:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		p.slots <- struct{}{}
+		return nil, ErrPoolClosed
+	}
+
+	if n := len(p.idle); n > 0 {
+		tab := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		return tab, nil
+	}
+
+	timeout := contextTimeout(ctx)
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	tab, err := p.browser.OpenNewTab(timeout)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to open tab for pool", err.Error())
+		p.slots <- struct{}{}
+		return nil, err
+	}
+	p.all = append(p.all, tab)
+
+	return tab, nil
+}
+
+// Release gives a tab acquired from the pool back to it
+func (p *TabPool) Release(tab Tab) {
+	p.mu.Lock()
+	p.idle = append(p.idle, tab)
+	p.mu.Unlock()
+
+	p.slots <- struct{}{}
+}
+
+// Close closes all the tabs opened by the pool, tabs which are still acquired are closed as well
+func (p *TabPool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+
+	var err error
+	for _, tab := range p.all {
+		if closeErr := p.browser.CloseTab(tab, DefaultTimeout); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	p.idle, p.all = nil, nil
+
+	return err
+}
@@ -0,0 +1,164 @@
+//go:build !windows
+// +build !windows
+
+package chrome
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// processGroup is the process group chrome process and all of its renderer, gpu and utility processes belong to
+type processGroup struct {
+	pgid int
+}
+
+// prepareProcessGroup makes chrome process the leader of a new process group, which its child processes join
+func prepareProcessGroup(command *exec.Cmd) {
+	if command.SysProcAttr == nil {
+		command.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	command.SysProcAttr.Setpgid = true
+}
+
+// newProcessGroup returns the process group of the started chrome process
+func newProcessGroup(command *exec.Cmd) (*processGroup, error) {
+	return &processGroup{pgid: command.Process.Pid}, nil
+}
+
+// kill kills every process of the group
+func (p *processGroup) kill() error {
+	err := syscall.Kill(-p.pgid, syscall.SIGKILL)
+	if err == syscall.ESRCH {
+		// the group is already gone
+		return nil
+	}
+
+	return err
+}
+
+// rss returns the resident set size in bytes of the processes of the group. It is read from /proc, hence it is only
+// available on linux and 0 elsewhere
+func (p *processGroup) rss() (int64, error) {
+	entries, err := os.ReadDir("/proc")
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var pages int64
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+
+		stat, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "stat"))
+		if err != nil {
+			// the process exited in the meantime
+			continue
+		}
+
+		// the fields following the command name, which may contain spaces, start with the state of the process
+		i := bytes.LastIndexByte(stat, ')')
+		if i < 0 {
+			continue
+		}
+		fields := strings.Fields(string(stat[i+1:]))
+		if len(fields) < 22 || fields[2] != strconv.Itoa(p.pgid) {
+			continue
+		}
+
+		rss, err := strconv.ParseInt(fields[21], 10, 64)
+		if err != nil {
+			continue
+		}
+		pages += rss
+	}
+
+	return pages * int64(os.Getpagesize()), nil
+}
+
+// listProcesses returns the running processes by their pid, the arguments of their command lines separated by spaces.
+// They are read from /proc when available and listed with ps otherwise, e.g. on macOS
+func listProcesses() (map[int]process, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		output, err := exec.Command("ps", "-axo", "pid=,ppid=,command=").Output()
+		if err != nil {
+			return nil, err
+		}
+
+		return parseProcessList(output), nil
+	}
+
+	processes := make(map[int]process)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		cmdline, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "cmdline"))
+		if err != nil || len(cmdline) == 0 {
+			// the process exited in the meantime or is a kernel thread
+			continue
+		}
+
+		stat, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+
+		// the parent pid follows the command name, which may contain spaces, and the state of the process
+		i := bytes.LastIndexByte(stat, ')')
+		if i < 0 {
+			continue
+		}
+		fields := strings.Fields(string(stat[i+1:]))
+		if len(fields) < 2 {
+			continue
+		}
+		ppid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+
+		processes[pid] = process{
+			ppid:        ppid,
+			commandLine: strings.Replace(strings.TrimRight(string(cmdline), "\x00"), "\x00", " ", -1),
+		}
+	}
+
+	return processes, nil
+}
+
+// killProcess kills the process along with its process group if it leads one, as chrome processes launched by the
+// framework do
+func killProcess(pid int) error {
+	if pgid, err := syscall.Getpgid(pid); err == nil && pgid == pid {
+		if err = syscall.Kill(-pid, syscall.SIGKILL); err == nil {
+			return nil
+		}
+	}
+
+	err := syscall.Kill(pid, syscall.SIGKILL)
+	if err == syscall.ESRCH {
+		// the process is already gone
+		return nil
+	}
+
+	return err
+}
+
+// processAlive reports whether a process with the pid is running
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
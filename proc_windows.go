@@ -0,0 +1,211 @@
+//go:build windows
+// +build windows
+
+package chrome
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32                      = syscall.NewLazyDLL("kernel32.dll")
+	procCreateJobObjectW          = kernel32.NewProc("CreateJobObjectW")
+	procSetInformationJobObject   = kernel32.NewProc("SetInformationJobObject")
+	procAssignProcessToJobObject  = kernel32.NewProc("AssignProcessToJobObject")
+	procTerminateJobObject        = kernel32.NewProc("TerminateJobObject")
+	procQueryInformationJobObject = kernel32.NewProc("QueryInformationJobObject")
+	procGetProcessMemoryInfo      = kernel32.NewProc("K32GetProcessMemoryInfo")
+)
+
+const (
+	jobObjectBasicProcessIDListClass       = 3
+	jobObjectExtendedLimitInformationClass = 9
+	jobObjectLimitKillOnJobClose           = 0x00002000
+	processSetQuota                        = 0x0100
+	processTerminate                       = 0x0001
+	processQueryLimitedInformation         = 0x1000
+	// exit code of processes which are still running
+	stillActive = 259
+	// maximum number of processes of the job whose memory is accounted for
+	maxJobProcesses = 1024
+)
+
+type jobObjectBasicLimitInformation struct {
+	PerProcessUserTimeLimit int64
+	PerJobUserTimeLimit     int64
+	LimitFlags              uint32
+	MinimumWorkingSetSize   uintptr
+	MaximumWorkingSetSize   uintptr
+	ActiveProcessLimit      uint32
+	Affinity                uintptr
+	PriorityClass           uint32
+	SchedulingClass         uint32
+}
+
+type ioCounters struct {
+	ReadOperationCount  uint64
+	WriteOperationCount uint64
+	OtherOperationCount uint64
+	ReadTransferCount   uint64
+	WriteTransferCount  uint64
+	OtherTransferCount  uint64
+}
+
+type jobObjectExtendedLimitInformation struct {
+	BasicLimitInformation jobObjectBasicLimitInformation
+	IoInfo                ioCounters
+	ProcessMemoryLimit    uintptr
+	JobMemoryLimit        uintptr
+	PeakProcessMemoryUsed uintptr
+	PeakJobMemoryUsed     uintptr
+}
+
+type jobObjectBasicProcessIDList struct {
+	NumberOfAssignedProcesses uint32
+	NumberOfProcessIdsInList  uint32
+	ProcessIDList             [maxJobProcesses]uintptr
+}
+
+type processMemoryCounters struct {
+	Cb                         uint32
+	PageFaultCount             uint32
+	PeakWorkingSetSize         uintptr
+	WorkingSetSize             uintptr
+	QuotaPeakPagedPoolUsage    uintptr
+	QuotaPagedPoolUsage        uintptr
+	QuotaPeakNonPagedPoolUsage uintptr
+	QuotaNonPagedPoolUsage     uintptr
+	PagefileUsage              uintptr
+	PeakPagefileUsage          uintptr
+}
+
+// processGroup is the job object chrome process is assigned to. The renderer, gpu and utility processes spawned by
+// chrome are assigned to the same job, and the whole job is killed when its handle is closed
+type processGroup struct {
+	job syscall.Handle
+}
+
+// prepareProcessGroup does nothing on windows, chrome process is assigned to a job object once started
+func prepareProcessGroup(command *exec.Cmd) {}
+
+// newProcessGroup creates a job object which kills its processes once closed and assigns chrome process to it
+func newProcessGroup(command *exec.Cmd) (*processGroup, error) {
+	job, _, err := procCreateJobObjectW.Call(0, 0)
+	if job == 0 {
+		return nil, err
+	}
+
+	info := jobObjectExtendedLimitInformation{}
+	info.BasicLimitInformation.LimitFlags = jobObjectLimitKillOnJobClose
+	ok, _, err := procSetInformationJobObject.Call(
+		job,
+		jobObjectExtendedLimitInformationClass,
+		uintptr(unsafe.Pointer(&info)),
+		unsafe.Sizeof(info),
+	)
+	if ok == 0 {
+		_ = syscall.CloseHandle(syscall.Handle(job))
+		return nil, err
+	}
+
+	process, err := syscall.OpenProcess(processSetQuota|processTerminate, false, uint32(command.Process.Pid))
+	if err != nil {
+		_ = syscall.CloseHandle(syscall.Handle(job))
+		return nil, err
+	}
+	defer syscall.CloseHandle(process)
+
+	ok, _, err = procAssignProcessToJobObject.Call(job, uintptr(process))
+	if ok == 0 {
+		_ = syscall.CloseHandle(syscall.Handle(job))
+		return nil, err
+	}
+
+	return &processGroup{job: syscall.Handle(job)}, nil
+}
+
+// kill terminates every process of the job and releases the job
+func (p *processGroup) kill() error {
+	ok, _, err := procTerminateJobObject.Call(uintptr(p.job), 1)
+	closeErr := syscall.CloseHandle(p.job)
+	if ok == 0 {
+		return err
+	}
+
+	return closeErr
+}
+
+// rss returns the sum of the working sets in bytes of the processes of the job
+func (p *processGroup) rss() (int64, error) {
+	list := jobObjectBasicProcessIDList{}
+	ok, _, err := procQueryInformationJobObject.Call(
+		uintptr(p.job),
+		jobObjectBasicProcessIDListClass,
+		uintptr(unsafe.Pointer(&list)),
+		unsafe.Sizeof(list),
+		0,
+	)
+	if ok == 0 {
+		return 0, err
+	}
+
+	var rss int64
+	for _, pid := range list.ProcessIDList[:list.NumberOfProcessIdsInList] {
+		process, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+		if err != nil {
+			// the process exited in the meantime
+			continue
+		}
+
+		counters := processMemoryCounters{}
+		counters.Cb = uint32(unsafe.Sizeof(counters))
+		ok, _, _ := procGetProcessMemoryInfo.Call(uintptr(process), uintptr(unsafe.Pointer(&counters)), uintptr(counters.Cb))
+		_ = syscall.CloseHandle(process)
+		if ok != 0 {
+			rss += int64(counters.WorkingSetSize)
+		}
+	}
+
+	return rss, nil
+}
+
+// listProcesses returns the running processes by their pid, as listed by powershell
+func listProcesses() (map[int]process, error) {
+	script := `Get-CimInstance Win32_Process | ForEach-Object { "$($_.ProcessId) $($_.ParentProcessId) $($_.CommandLine)" }`
+	output, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseProcessList(output), nil
+}
+
+// killProcess terminates the process
+func killProcess(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		// the process is already gone
+		return nil
+	}
+
+	return process.Kill()
+}
+
+// processAlive reports whether a process with the pid is running
+func processAlive(pid int) bool {
+	process, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer syscall.CloseHandle(process)
+
+	var code uint32
+	if err = syscall.GetExitCodeProcess(process, &code); err != nil {
+		return false
+	}
+
+	return code == stillActive
+}
@@ -0,0 +1,39 @@
+package chrome
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/dom"
+	"log"
+)
+
+// ErrNodeNotFound is returned when no node matches a selector
+var ErrNodeNotFound = errors.New("go-chrome-framework: node not found")
+
+// querySelector returns the id of the first node matching the css selector in the document
+func (t *tab) querySelector(ctx context.Context, selector string) (dom.NodeID, error) {
+	var node *dom.QuerySelectorReply
+	err := t.call(ctx, func(client *cdp.Client) error {
+		doc, err := client.DOM.GetDocument(ctx, nil)
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to get DOM root node", err.Error())
+			return err
+		}
+
+		node, err = client.DOM.QuerySelector(ctx, dom.NewQuerySelectorArgs(doc.Root.NodeID, selector))
+		return err
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to query selector", err.Error())
+		return 0, err
+	}
+
+	// a node id of 0 means there is no such node
+	if node.NodeID == 0 {
+		return 0, fmt.Errorf("%w: %v", ErrNodeNotFound, selector)
+	}
+
+	return node.NodeID, nil
+}
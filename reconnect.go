@@ -0,0 +1,217 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp/rpcc"
+	"log"
+	"time"
+)
+
+const (
+	// number of attempts to reconnect to a tab whose connection dropped
+	reconnectAttempts = 8
+	// delay before the first reconnection attempt, doubled after every failed attempt
+	reconnectInitialDelay = 100 * time.Millisecond
+	// upper bound of the delay between reconnection attempts
+	reconnectMaxDelay = 10 * time.Second
+	// timeout of a single reconnection attempt
+	reconnectTimeout = 30 * time.Second
+)
+
+// reconnectRetryOpts is the policy of reconnecting to a tab whose connection dropped
+var reconnectRetryOpts = RetryOpts{
+	Attempts:       reconnectAttempts,
+	InitialBackoff: reconnectInitialDelay,
+	MaxBackoff:     reconnectMaxDelay,
+	Jitter:         0.5,
+}
+
+type ConnectionState int
+
+const (
+	// ConnectionStateConnected is reported once a dropped connection is established again
+	ConnectionStateConnected ConnectionState = iota
+	// ConnectionStateReconnecting is reported when the connection drops and reconnection is being attempted
+	ConnectionStateReconnecting
+	// ConnectionStateDisconnected is reported when all the reconnection attempts fail, the next call on the tab will
+	// attempt to connect again
+	ConnectionStateDisconnected
+)
+
+func (c ConnectionState) String() string {
+	switch c {
+	case ConnectionStateConnected:
+		return "connected"
+	case ConnectionStateReconnecting:
+		return "reconnecting"
+	case ConnectionStateDisconnected:
+		return "disconnected"
+	default:
+		return "unknown"
+	}
+}
+
+// ConnectionStateHandler is invoked whenever the connection of a tab with the browser changes its state
+type ConnectionStateHandler func(state ConnectionState)
+
+func (t *tab) OnConnectionStateChanged(handler ConnectionStateHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.connectionStateHandlers = append(t.connectionStateHandlers, handler)
+}
+
+func (t *tab) setConnectionState(state ConnectionState) {
+	t.mu.Lock()
+	handlers := t.connectionStateHandlers
+	t.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(state)
+	}
+}
+
+// watch reconnects to the tab with exponential backoff if the connection drops without being closed by us. Hooks are
+// executed again on the new connection and the event streams of the features are re-opened, so that the domains,
+// handlers and routes set up before the connection dropped are restored
+func (t *tab) watch(conn *rpcc.Conn) {
+	<-conn.Context().Done()
+
+	// the connection was closed deliberately, replaced already or the page is gone
+	t.mu.Lock()
+	dropped := !t.closing && t.conn == conn
+	t.mu.Unlock()
+	if !dropped || t.closedState() {
+		return
+	}
+
+	log.Println("go-chrome-framework: connection to tab dropped, reconnecting", t.id)
+	t.setConnectionState(ConnectionStateReconnecting)
+
+	err := reconnectRetryOpts.run(context.Background(), retryAlways, func(context.Context) error {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		if t.closing || t.conn != conn {
+			return nil
+		}
+
+		return t.connect(reconnectTimeout)
+	})
+
+	t.mu.Lock()
+	reconnected := err == nil && !t.closing
+	if !reconnected && t.conn == conn {
+		// the next call on the tab connects lazily
+		t.conn = nil
+		t.client = nil
+	}
+	t.mu.Unlock()
+
+	if !reconnected {
+		log.Println("go-chrome-framework error: unable to reconnect to tab", t.id)
+		t.resetFeatures()
+		t.setConnectionState(ConnectionStateDisconnected)
+
+		return
+	}
+
+	t.restoreFeatures()
+	t.setConnectionState(ConnectionStateConnected)
+}
+
+// streamsEnded is deferred by the goroutines reading the event streams of a feature, drop removes the feature from the
+// tab. The feature is kept when the streams ended because the connection dropped, watch re-opens its streams on the
+// new connection then
+func (t *tab) streamsEnded(drop func()) {
+	t.mu.Lock()
+	closing := t.closing
+	t.mu.Unlock()
+
+	if !closing && t.lifetime().Err() == nil {
+		return
+	}
+
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	drop()
+}
+
+// restoreFeatures re-opens the event streams of the features on the re-established connection, along with the request
+// interception of the routes. Features which cannot be restored are dropped
+func (t *tab) restoreFeatures() {
+	ctx, cancel := context.WithTimeout(t.lifetime(), reconnectTimeout)
+	defer cancel()
+
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	if t.webSockets != nil {
+		if err := t.openWebSockets(t.webSockets); err != nil {
+			log.Println("go-chrome-framework error: unable to restore websocket frame handlers", err.Error())
+			t.webSockets = nil
+		}
+	}
+
+	if t.eventSources != nil {
+		if err := t.openEventSources(t.eventSources); err != nil {
+			log.Println("go-chrome-framework error: unable to restore event source message handlers", err.Error())
+			t.eventSources = nil
+		}
+	}
+
+	if t.requestFailures != nil {
+		if err := t.openRequestFailures(t.requestFailures); err != nil {
+			log.Println("go-chrome-framework error: unable to restore request failed handlers", err.Error())
+			t.requestFailures = nil
+		}
+	}
+
+	if t.routeChanges != nil {
+		if err := t.openRouteChanges(ctx, t.routeChanges); err != nil {
+			log.Println("go-chrome-framework error: unable to restore route change handlers", err.Error())
+			t.routeChanges = nil
+		}
+	}
+
+	if t.networkStats != nil {
+		if err := t.openNetworkStats(t.networkStats); err != nil {
+			log.Println("go-chrome-framework error: unable to restore network stats", err.Error())
+			t.networkStats = nil
+		}
+	}
+
+	// the browser forgets the patterns of the paused requests along with the connection
+	if t.interceptor != nil {
+		t.interceptor.cancel()
+
+		err := t.openInterceptor(t.interceptor)
+		if err == nil {
+			err = t.enableFetch(ctx)
+		}
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to restore routes", err.Error())
+			t.interceptor.cancel()
+			t.interceptor = nil
+		}
+	}
+}
+
+// resetFeatures drops the features whose event streams ended with a connection which could not be re-established, the
+// next call registering a handler or a route opens them again
+func (t *tab) resetFeatures() {
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	t.webSockets = nil
+	t.eventSources = nil
+	t.requestFailures = nil
+	t.routeChanges = nil
+	t.networkStats = nil
+
+	if t.interceptor != nil {
+		t.interceptor.cancel()
+		t.interceptor = nil
+	}
+}
@@ -0,0 +1,76 @@
+package chrome
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+// RecordedMessage is a protocol message exchanged with the browser, recorded by LaunchOpts.SetRecorder. Messages of
+// tabs carry the id of their session
+type RecordedMessage struct {
+	// Sent is true for requests sent to the browser and false for responses and events received from it
+	Sent bool `json:"sent"`
+	// Time since the recording started
+	Time    time.Duration   `json:"time"`
+	Message json.RawMessage `json:"message"`
+}
+
+// ReadRecording reads the messages written by LaunchOpts.SetRecorder, see chrometest.NewReplayTab to replay them
+func ReadRecording(r io.Reader) ([]RecordedMessage, error) {
+	var messages []RecordedMessage
+
+	decoder := json.NewDecoder(r)
+	for {
+		var message RecordedMessage
+		if err := decoder.Decode(&message); err == io.EOF {
+			return messages, nil
+		} else if err != nil {
+			return messages, err
+		}
+
+		messages = append(messages, message)
+	}
+}
+
+// recorder writes the messages exchanged with the browser as lines of json
+type recorder struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	start   time.Time
+	// set once writing failed, so that the failure is only logged once
+	failed bool
+}
+
+func newRecorder(w io.Writer) *recorder {
+	return &recorder{encoder: json.NewEncoder(w), start: time.Now()}
+}
+
+func (r *recorder) record(sent bool, message []byte) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.failed {
+		return
+	}
+
+	err := r.encoder.Encode(RecordedMessage{Sent: sent, Time: time.Since(r.start), Message: message})
+	if err != nil {
+		r.failed = true
+		log.Println("go-chrome-framework error: unable to record protocol message", err.Error())
+	}
+}
+
+func (c *chrome) recorder() *recorder {
+	if c.opts == nil {
+		return nil
+	}
+
+	return c.opts.recorder
+}
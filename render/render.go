@@ -0,0 +1,110 @@
+// Package render renders html documents as pdf documents or png images without having to manage the browser. Every
+// call launches a headless browser, loads the html, waits for its assets and tears the browser down again
+package render
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	chrome "go.ajitem.com/gcf/v3"
+	"html/template"
+	"time"
+)
+
+// Format of the rendered output
+type Format int
+
+const (
+	PDF Format = iota
+	PNG
+)
+
+// defaultTimeout bounds rendering when the context has no deadline
+const defaultTimeout = time.Minute
+
+// assetsScript resolves once the fonts have loaded and every image has either loaded or failed
+const assetsScript = `Promise.all([
+	document.fonts ? document.fonts.ready : Promise.resolve(),
+	...Array.from(document.images).filter(img => !img.complete).map(img => new Promise(resolve => {
+		img.addEventListener('load', resolve);
+		img.addEventListener('error', resolve);
+	})),
+])`
+
+type Opts struct {
+	// Format of the output, defaults to PDF
+	Format Format
+	// PDF options applied when rendering a pdf document
+	PDF chrome.PDFOpts
+	// Screenshot options applied when rendering a png image
+	Screenshot chrome.ScreenshotOpts
+	// LaunchOpts used to launch the browser, defaults to chrome.NewLaunchOpts
+	LaunchOpts *chrome.LaunchOpts
+}
+
+// Render renders the html in the given format
+func Render(ctx context.Context, html string, opts Opts) ([]byte, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultTimeout)
+		defer cancel()
+	}
+
+	launchOpts := opts.LaunchOpts
+	if launchOpts == nil {
+		launchOpts = chrome.NewLaunchOpts()
+	}
+
+	browser := chrome.NewChrome()
+	tab, err := browser.Launch(launchOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer browser.Terminate()
+
+	if err = tab.SetContent(html, timeout(ctx)); err != nil {
+		return nil, err
+	}
+
+	reply, err := tab.Exec(assetsScript, timeout(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if reply.ExceptionDetails != nil {
+		return nil, &chrome.JavaScriptError{Details: reply.ExceptionDetails}
+	}
+
+	switch opts.Format {
+	case PDF:
+		return tab.PrintToPDF(opts.PDF, timeout(ctx))
+	case PNG:
+		var buf bytes.Buffer
+		opts.Screenshot.Format = "png"
+		if err = tab.CaptureScreenshotTo(&buf, opts.Screenshot, timeout(ctx)); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("go-chrome-framework: unknown render format %v", opts.Format)
+	}
+}
+
+// RenderTemplate executes the template with the data and renders the resulting html in the given format
+func RenderTemplate(ctx context.Context, tmpl *template.Template, data interface{}, opts Opts) ([]byte, error) {
+	var html bytes.Buffer
+	if err := tmpl.Execute(&html, data); err != nil {
+		return nil, err
+	}
+
+	return Render(ctx, html.String(), opts)
+}
+
+// timeout returns the time left until the deadline of the context
+func timeout(ctx context.Context) time.Duration {
+	deadline, _ := ctx.Deadline()
+	if remaining := time.Until(deadline); remaining > 0 {
+		return remaining
+	}
+
+	return time.Nanosecond
+}
@@ -0,0 +1,201 @@
+package chrome
+
+import (
+	"github.com/mafredri/cdp/protocol/network"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FailureReason classifies why a request failed
+type FailureReason string
+
+const (
+	FailureBlocked     FailureReason = "blocked"
+	FailureDNS         FailureReason = "dns"
+	FailureTimeout     FailureReason = "timeout"
+	FailureCORS        FailureReason = "cors"
+	FailureCertificate FailureReason = "certificate"
+	FailureConnection  FailureReason = "connection"
+	FailureCanceled    FailureReason = "canceled"
+	FailureOther       FailureReason = "other"
+)
+
+// RequestFailure is a request of the page which failed to load
+type RequestFailure struct {
+	RequestID network.RequestID
+	URL       string
+	// ResourceType of the request, e.g. "Document", "Image" or "Script"
+	ResourceType string
+	Reason       FailureReason
+	// ErrorText reported by the browser, e.g. "net::ERR_NAME_NOT_RESOLVED"
+	ErrorText string
+	// BlockedReason reported by the browser for blocked requests, e.g. "mixed-content"
+	BlockedReason string
+}
+
+// RequestFailedHandler is invoked for every request of a tab which fails
+type RequestFailedHandler func(RequestFailure)
+
+// requestFailures dispatches the failed requests of a tab to the handlers
+type requestFailures struct {
+	// guards handlers and urls
+	mu       sync.Mutex
+	handlers []RequestFailedHandler
+	// urls of the requests in flight by their request id
+	urls map[network.RequestID]string
+}
+
+// OnRequestFailed invokes the handler for every request of the tab which fails to load, including requests blocked by
+// the browser or by routes. Handlers are invoked one at a time in the order the requests fail, a failed document
+// request can be retried by navigating again once the handler reported it
+func (t *tab) OnRequestFailed(handler RequestFailedHandler, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if t.requestFailures != nil {
+		t.requestFailures.mu.Lock()
+		t.requestFailures.handlers = append(t.requestFailures.handlers, handler)
+		t.requestFailures.mu.Unlock()
+
+		return nil
+	}
+
+	failures := &requestFailures{
+		handlers: []RequestFailedHandler{handler},
+		urls:     make(map[network.RequestID]string),
+	}
+	if err := t.openRequestFailures(failures); err != nil {
+		return err
+	}
+	t.requestFailures = failures
+
+	return nil
+}
+
+// openRequestFailures opens the request streams of the current connection and dispatches the failed requests to the
+// handlers, see restoreFeatures
+func (t *tab) openRequestFailures(failures *requestFailures) error {
+	// failures are reported until the tab closes, the streams outlive the timeout of the call
+	client, err := t.currentClient()
+	if err != nil {
+		return err
+	}
+
+	requestWillBeSent, err := client.Network.RequestWillBeSent(t.lifetime())
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to open request will be sent client", err.Error())
+		return err
+	}
+
+	loadingFinished, err := client.Network.LoadingFinished(t.lifetime())
+	if err != nil {
+		closeRes(requestWillBeSent)
+		log.Println("go-chrome-framework error: unable to open loading finished client", err.Error())
+		return err
+	}
+
+	loadingFailed, err := client.Network.LoadingFailed(t.lifetime())
+	if err != nil {
+		closeRes(requestWillBeSent)
+		closeRes(loadingFinished)
+		log.Println("go-chrome-framework error: unable to open loading failed client", err.Error())
+		return err
+	}
+
+	go func() {
+		defer closeRes(requestWillBeSent)
+		defer closeRes(loadingFinished)
+		defer closeRes(loadingFailed)
+
+		defer t.streamsEnded(func() {
+			if t.requestFailures == failures {
+				t.requestFailures = nil
+			}
+		})
+
+		for {
+			select {
+			case <-requestWillBeSent.Ready():
+				ev, err := requestWillBeSent.Recv()
+				if err != nil {
+					return
+				}
+
+				failures.mu.Lock()
+				failures.urls[ev.RequestID] = ev.Request.URL
+				failures.mu.Unlock()
+			case <-loadingFinished.Ready():
+				ev, err := loadingFinished.Recv()
+				if err != nil {
+					return
+				}
+
+				failures.mu.Lock()
+				delete(failures.urls, ev.RequestID)
+				failures.mu.Unlock()
+			case <-loadingFailed.Ready():
+				ev, err := loadingFailed.Recv()
+				if err != nil {
+					return
+				}
+
+				failure := RequestFailure{
+					RequestID:     ev.RequestID,
+					ResourceType:  string(ev.Type),
+					Reason:        failureReason(ev),
+					ErrorText:     ev.ErrorText,
+					BlockedReason: string(ev.BlockedReason),
+				}
+
+				failures.mu.Lock()
+				failure.URL = failures.urls[ev.RequestID]
+				delete(failures.urls, ev.RequestID)
+				handlers := append([]RequestFailedHandler{}, failures.handlers...)
+				failures.mu.Unlock()
+
+				for _, handler := range handlers {
+					handler(failure)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// failureReason classifies the failure from the details the browser reported and its network error
+func failureReason(ev *network.LoadingFailedReply) FailureReason {
+	switch {
+	case ev.Canceled != nil && *ev.Canceled:
+		return FailureCanceled
+	case ev.CORSErrorStatus != nil:
+		return FailureCORS
+	case ev.BlockedReason != "":
+		return FailureBlocked
+	}
+
+	switch text := ev.ErrorText; {
+	case strings.Contains(text, "NAME_NOT_RESOLVED"), strings.Contains(text, "NAME_RESOLUTION_FAILED"):
+		return FailureDNS
+	case strings.Contains(text, "TIMED_OUT"):
+		return FailureTimeout
+	case strings.Contains(text, "CERT"), strings.Contains(text, "SSL"):
+		return FailureCertificate
+	case strings.Contains(text, "BLOCKED"):
+		return FailureBlocked
+	case strings.Contains(text, "ABORTED"):
+		return FailureCanceled
+	case strings.Contains(text, "CONNECTION"), strings.Contains(text, "ADDRESS_UNREACHABLE"), strings.Contains(text, "INTERNET_DISCONNECTED"):
+		return FailureConnection
+	}
+
+	return FailureOther
+}
@@ -0,0 +1,156 @@
+package chrome
+
+import (
+	"context"
+	"errors"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/rpcc"
+	"math/rand"
+	"time"
+)
+
+// RetryOpts configures how connecting to the browser and to its tabs, and calls to the tabs which are safe to repeat,
+// are retried when they fail
+type RetryOpts struct {
+	// Attempts is the maximum number of attempts, defaults to 5
+	Attempts int
+	// InitialBackoff is the delay after the first failed attempt, doubled after every further failed attempt up to
+	// MaxBackoff. Default to 100ms and 1s
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Jitter is the fraction of the delay which is randomized, from 0 for a fixed delay to 1 for a delay anywhere
+	// between 0 and the backoff
+	Jitter float64
+	// Budget is the total time all the attempts to connect to the browser may take, defaults to 120s. Connecting to
+	// tabs is bound by the timeout of the call instead
+	Budget time.Duration
+}
+
+// DefaultRetryOpts returns the retry policy used unless set with LaunchOpts.SetRetryOpts
+func DefaultRetryOpts() RetryOpts {
+	return RetryOpts{
+		Attempts:       5,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Jitter:         0.5,
+		Budget:         120 * time.Second,
+	}
+}
+
+// withDefaults fills in the defaults of the options which are not set
+func (r RetryOpts) withDefaults() RetryOpts {
+	defaults := DefaultRetryOpts()
+
+	if r.Attempts <= 0 {
+		r.Attempts = defaults.Attempts
+	}
+	if r.InitialBackoff <= 0 {
+		r.InitialBackoff = defaults.InitialBackoff
+	}
+	if r.MaxBackoff <= 0 {
+		r.MaxBackoff = defaults.MaxBackoff
+	}
+	if r.Jitter < 0 {
+		r.Jitter = 0
+	} else if r.Jitter > 1 {
+		r.Jitter = 1
+	}
+	if r.Budget <= 0 {
+		r.Budget = defaults.Budget
+	}
+
+	return r
+}
+
+// backoff returns the delay after the given number of failed attempts
+func (r RetryOpts) backoff(failed int) time.Duration {
+	backoff := r.InitialBackoff
+	for i := 1; i < failed && backoff < r.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > r.MaxBackoff {
+		backoff = r.MaxBackoff
+	}
+
+	return backoff - time.Duration(r.Jitter*rand.Float64()*float64(backoff))
+}
+
+// run calls fn until it succeeds, fails with an error which retry deems permanent, the attempts are used up or ctx is
+// done. The error of the last attempt is returned
+func (r RetryOpts) run(ctx context.Context, retry func(error) bool, fn func(ctx context.Context) error) error {
+	for failed := 1; ; failed++ {
+		err := fn(ctx)
+		if err == nil || failed >= r.Attempts || !retry(err) {
+			return err
+		}
+
+		timer := time.NewTimer(r.backoff(failed))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// retryAlways retries every error, e.g. while the browser is starting up and refuses connections
+func retryAlways(error) bool {
+	return true
+}
+
+// retryNever retries no error, e.g. when a failed attempt cannot be repeated
+func retryNever(error) bool {
+	return false
+}
+
+// isTransient reports whether a failed call may succeed when retried. Errors returned by the browser are permanent, so
+// are the errors of calls which were cancelled or timed out
+func isTransient(err error) bool {
+	cause := cdp.ErrorCause(err)
+	for _, err := range []error{err, cause} {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+	}
+
+	var responseErr *rpcc.ResponseError
+	return !errors.As(cause, &responseErr)
+}
+
+// call calls fn with the client of the current connection of the tab. Transient failures are retried with the retry
+// policy of the browser until ctx is done, e.g. calls failing because the connection dropped are retried once it is
+// re-established. Only calls which are safe to repeat are made through call
+func (t *tab) call(ctx context.Context, fn func(client *cdp.Client) error) error {
+	return t.browser.retryOpts().run(ctx, t.retryable, func(ctx context.Context) error {
+		if err := t.ensureConnected(contextTimeout(ctx)); err != nil {
+			return err
+		}
+
+		client, err := t.currentClient()
+		if err != nil {
+			return err
+		}
+
+		return fn(client)
+	})
+}
+
+// retryOpts returns the retry policy the browser was launched with
+func (c *chrome) retryOpts() RetryOpts {
+	if c == nil || c.opts == nil || c.opts.retryOpts == nil {
+		return DefaultRetryOpts()
+	}
+
+	return c.opts.retryOpts.withDefaults()
+}
+
+// connectTimeout returns the time the browser has to start up and accept the connection, see
+// LaunchOpts.SetConnectTimeout
+func (c *chrome) connectTimeout() time.Duration {
+	if c != nil && c.opts != nil && c.opts.connectTimeout > 0 {
+		return c.opts.connectTimeout
+	}
+
+	return c.retryOpts().Budget
+}
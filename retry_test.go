@@ -0,0 +1,168 @@
+package chrome
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mafredri/cdp/rpcc"
+	"testing"
+	"time"
+)
+
+func TestRetryOptsBackoff(t *testing.T) {
+	opts := RetryOpts{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	tests := []struct {
+		failed  int
+		backoff time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{50, time.Second},
+	}
+
+	for _, test := range tests {
+		if backoff := opts.backoff(test.failed); backoff != test.backoff {
+			t.Errorf("backoff(%v) = %v, want %v", test.failed, backoff, test.backoff)
+		}
+	}
+}
+
+func TestRetryOptsBackoffJitter(t *testing.T) {
+	opts := RetryOpts{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		if backoff := opts.backoff(3); backoff < 200*time.Millisecond || backoff > 400*time.Millisecond {
+			t.Fatalf("backoff(3) = %v, want between 200ms and 400ms", backoff)
+		}
+	}
+}
+
+func TestRetryOptsWithDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		opts RetryOpts
+		want RetryOpts
+	}{
+		{
+			name: "unset",
+			want: RetryOpts{Attempts: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second,
+				Budget: 120 * time.Second},
+		},
+		{
+			name: "set",
+			opts: RetryOpts{Attempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Minute, Jitter: 0.2,
+				Budget: time.Second},
+			want: RetryOpts{Attempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Minute, Jitter: 0.2,
+				Budget: time.Second},
+		},
+		{
+			name: "jitter clamped",
+			opts: RetryOpts{Jitter: 3},
+			want: RetryOpts{Attempts: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Jitter: 1,
+				Budget: 120 * time.Second},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if opts := test.opts.withDefaults(); opts != test.want {
+				t.Errorf("withDefaults() = %+v, want %+v", opts, test.want)
+			}
+		})
+	}
+}
+
+func TestRetryOptsRun(t *testing.T) {
+	errTransient := errors.New("transient")
+	errPermanent := errors.New("permanent")
+	retry := func(err error) bool {
+		return err == errTransient
+	}
+
+	tests := []struct {
+		name string
+		// errors of the attempts in turn, the attempts succeed once they are used up
+		errs  []error
+		err   error
+		calls int
+	}{
+		{name: "success", calls: 1},
+		{name: "transient failures", errs: []error{errTransient, errTransient}, calls: 3},
+		{name: "permanent failure", errs: []error{errTransient, errPermanent}, err: errPermanent, calls: 2},
+		{name: "attempts used up", errs: []error{errTransient, errTransient, errTransient, errTransient},
+			err: errTransient, calls: 3},
+	}
+
+	opts := RetryOpts{Attempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			err := opts.run(context.Background(), retry, func(ctx context.Context) error {
+				calls++
+				if calls <= len(test.errs) {
+					return test.errs[calls-1]
+				}
+				return nil
+			})
+
+			if err != test.err {
+				t.Errorf("run() = %v, want %v", err, test.err)
+			}
+			if calls != test.calls {
+				t.Errorf("run() made %v calls, want %v", calls, test.calls)
+			}
+		})
+	}
+}
+
+func TestRetryOptsRunBudget(t *testing.T) {
+	opts := RetryOpts{Attempts: 1000, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+	errTransient := errors.New("transient")
+
+	// the budget of the attempts is the deadline of the context
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	start := time.Now()
+	err := opts.run(ctx, retryAlways, func(ctx context.Context) error {
+		calls++
+		return errTransient
+	})
+
+	if err != errTransient {
+		t.Errorf("run() = %v, want %v", err, errTransient)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("run() took %v, want it to stop at the deadline", elapsed)
+	}
+	if calls < 2 || calls >= opts.Attempts {
+		t.Errorf("run() made %v calls, want it to retry until the deadline", calls)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{"connection error", errors.New("connection reset"), true},
+		{"not connected", ErrNotConnected, true},
+		{"canceled", context.Canceled, false},
+		{"deadline exceeded", context.DeadlineExceeded, false},
+		{"wrapped deadline exceeded", fmt.Errorf("call failed: %w", context.DeadlineExceeded), false},
+		{"browser error", &rpcc.ResponseError{Code: -32000, Message: "Node not found"}, false},
+		{"wrapped browser error", fmt.Errorf("call failed: %w", &rpcc.ResponseError{Code: -32000}), false},
+	}
+
+	for _, test := range tests {
+		if transient := isTransient(test.err); transient != test.transient {
+			t.Errorf("isTransient(%v) = %v, want %v", test.name, transient, test.transient)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package chrome
+
+import (
+	"github.com/mafredri/cdp/protocol/fetch"
+	"github.com/mafredri/cdp/protocol/network"
+	"html"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DocumentRewriter rewrites the html of a document before the browser parses it
+type DocumentRewriter func(url string, html string) (string, error)
+
+var (
+	headTag = regexp.MustCompile(`(?i)<head(\s[^>]*)?>`)
+	cspMeta = regexp.MustCompile(`(?is)<meta\s[^>]*http-equiv\s*=\s*["']?content-security-policy(-report-only)?["']?[^>]*>`)
+)
+
+// insertIntoHead inserts the markup at the start of the head of the document, or at the start of the document if it has
+// no head tag
+func insertIntoHead(document, markup string) string {
+	if location := headTag.FindStringIndex(document); location != nil {
+		return document[:location[1]] + markup + document[location[1]:]
+	}
+
+	return markup + document
+}
+
+// InjectBase returns a rewriter inserting a base element, so that relative urls resolve against href, e.g. for
+// documents served from a different location than their assets
+func InjectBase(href string) DocumentRewriter {
+	return func(url string, document string) (string, error) {
+		return insertIntoHead(document, `<base href="`+html.EscapeString(href)+`">`), nil
+	}
+}
+
+// StripCSPMeta returns a rewriter removing the content security policies declared by meta elements, see SetBypassCSP
+// for the policies sent as headers
+func StripCSPMeta() DocumentRewriter {
+	return func(url string, document string) (string, error) {
+		return cspMeta.ReplaceAllString(document, ""), nil
+	}
+}
+
+// InjectStyle returns a rewriter adding a style element with the css to the head of the document, e.g. print styles
+// fixing the layout of a pdf
+func InjectStyle(css string) DocumentRewriter {
+	return func(url string, document string) (string, error) {
+		return insertIntoHead(document, "<style>"+css+"</style>"), nil
+	}
+}
+
+// RewriteDocument intercepts the html responses of the documents of the main frame and serves the html rewritten by the
+// rewriters, in order, before the browser parses it. Responses of other frames and other resources are left as is. A
+// later call replaces the rewriters of an earlier one, a call without rewriters removes them. Routes are independent of
+// the rewriters, Unroute does not remove them
+func (t *tab) RewriteDocument(timeout time.Duration, rewriters ...DocumentRewriter) error {
+	timeout = t.resolveTimeout(timeout)
+
+	if len(rewriters) == 0 {
+		return t.unintercept(timeout, func(rule *interceptRule) bool {
+			return rule.rewriter
+		})
+	}
+
+	return t.intercept(&interceptRule{
+		pattern:      "*",
+		re:           compileURLPattern("*"),
+		resourceType: network.ResourceTypeDocument,
+		stage:        fetch.RequestStageResponse,
+		handler: func(route *Route) error {
+			return t.rewriteDocument(route, rewriters)
+		},
+		timeout:  timeout,
+		rewriter: true,
+	}, timeout)
+}
+
+func (t *tab) rewriteDocument(route *Route, rewriters []DocumentRewriter) error {
+	// redirects have no body to rewrite, the document they redirect to is paused again
+	status := route.event.ResponseStatusCode
+	if string(route.event.FrameID) != string(t.id) || status == nil || *status >= 300 && *status < 400 {
+		return route.Continue()
+	}
+
+	response, err := route.Response()
+	if err != nil {
+		return err
+	}
+
+	var contentType string
+	for _, header := range response.Headers {
+		if strings.EqualFold(header.Name, "content-type") {
+			contentType = header.Value
+		}
+	}
+	if contentType != "" && !strings.Contains(strings.ToLower(contentType), "html") {
+		return route.Continue()
+	}
+
+	document := string(response.Body)
+	for _, rewrite := range rewriters {
+		if document, err = rewrite(route.Request().URL, document); err != nil {
+			log.Println("go-chrome-framework error: unable to rewrite document", err.Error())
+			return route.Continue()
+		}
+	}
+
+	// the body is served decoded, hence the encoding and length of the original body do not apply
+	headers := response.Headers[:0]
+	for _, header := range response.Headers {
+		if !strings.EqualFold(header.Name, "content-encoding") && !strings.EqualFold(header.Name, "content-length") {
+			headers = append(headers, header)
+		}
+	}
+	response.Headers = headers
+	response.Body = []byte(document)
+
+	return route.Fulfill(*response)
+}
@@ -0,0 +1,210 @@
+package chrome
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/mafredri/cdp/protocol/page"
+	"github.com/mafredri/cdp/protocol/runtime"
+	"log"
+	"sync"
+	"time"
+)
+
+// RouteChangeKind is how the url of a page changed
+type RouteChangeKind string
+
+const (
+	RouteChangePushState    RouteChangeKind = "pushState"
+	RouteChangeReplaceState RouteChangeKind = "replaceState"
+	RouteChangePopState     RouteChangeKind = "popstate"
+	RouteChangeHashChange   RouteChangeKind = "hashchange"
+	// RouteChangeNavigation is a navigation loading a new document
+	RouteChangeNavigation RouteChangeKind = "navigation"
+)
+
+// RouteChange is a change of the url of the main frame of a tab
+type RouteChange struct {
+	URL  string
+	Kind RouteChangeKind
+	Time time.Time
+}
+
+// RouteChangeHandler is invoked for every route change of a tab
+type RouteChangeHandler func(RouteChange)
+
+// routeChangeBinding is the binding the injected listeners report route changes through
+const routeChangeBinding = "__gcfRouteChange"
+
+// routeChangeScript reports calls of the history api and hash changes of the main frame to the binding
+const routeChangeScript = `(() => {
+	if (window !== window.top || window.__gcfRouteChangeInstalled) {
+		return;
+	}
+	window.__gcfRouteChangeInstalled = true;
+	const report = kind => {
+		try {
+			window.` + routeChangeBinding + `(JSON.stringify({kind, url: location.href}));
+		} catch (e) {
+		}
+	};
+	for (const method of ['pushState', 'replaceState']) {
+		const original = history[method];
+		history[method] = function (...args) {
+			const result = original.apply(this, args);
+			report(method);
+			return result;
+		};
+	}
+	addEventListener('popstate', () => report('popstate'));
+	addEventListener('hashchange', () => report('hashchange'));
+})()`
+
+// routeChanges dispatches the route changes of a tab to the handlers
+type routeChanges struct {
+	mu       sync.Mutex
+	handlers []RouteChangeHandler
+}
+
+// OnRouteChange invokes the handler whenever the url of the main frame changes, either by navigating to a new document
+// or, as single-page applications do, by the history api or a change of the hash, which fire no load events. Listeners
+// are injected into the current and every later document of the tab. Handlers are invoked one at a time in the order
+// the changes happen. WaitForURL awaits a route of a single-page application like a navigation
+func (t *tab) OnRouteChange(handler RouteChangeHandler, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if t.routeChanges != nil {
+		t.routeChanges.mu.Lock()
+		t.routeChanges.handlers = append(t.routeChanges.handlers, handler)
+		t.routeChanges.mu.Unlock()
+
+		return nil
+	}
+
+	changes := &routeChanges{handlers: []RouteChangeHandler{handler}}
+	if err := t.openRouteChanges(ctx, changes); err != nil {
+		return err
+	}
+	t.routeChanges = changes
+
+	return nil
+}
+
+// openRouteChanges injects the route change listeners into the page of the current connection and dispatches the
+// changes to the handlers, see restoreFeatures
+func (t *tab) openRouteChanges(ctx context.Context, changes *routeChanges) error {
+	// route changes are reported for the lifetime of the tab, hence the streams are not bound to the timeout
+	client, err := t.currentClient()
+	if err != nil {
+		return err
+	}
+
+	bindingCalled, err := client.Runtime.BindingCalled(t.lifetime())
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to open binding called client", err.Error())
+		return err
+	}
+
+	frameNavigated, err := client.Page.FrameNavigated(t.lifetime())
+	if err != nil {
+		closeRes(bindingCalled)
+		log.Println("go-chrome-framework error: unable to open frame navigated client", err.Error())
+		return err
+	}
+
+	closeAll := func() {
+		closeRes(bindingCalled)
+		closeRes(frameNavigated)
+	}
+
+	if err = client.Runtime.AddBinding(ctx, runtime.NewAddBindingArgs(routeChangeBinding)); err != nil {
+		closeAll()
+		log.Println("go-chrome-framework error: unable to add route change binding", err.Error())
+		return err
+	}
+
+	if err = client.Page.Enable(ctx); err != nil {
+		closeAll()
+		log.Println("go-chrome-framework error: unable to enable page domain", err.Error())
+		return err
+	}
+
+	_, err = client.Page.AddScriptToEvaluateOnNewDocument(ctx, page.NewAddScriptToEvaluateOnNewDocumentArgs(routeChangeScript))
+	if err != nil {
+		closeAll()
+		log.Println("go-chrome-framework error: unable to inject route change listeners", err.Error())
+		return err
+	}
+
+	if err = t.evaluate(ctx, routeChangeScript, nil); err != nil {
+		closeAll()
+		log.Println("go-chrome-framework error: unable to inject route change listeners", err.Error())
+		return err
+	}
+
+	go func() {
+		defer closeAll()
+
+		defer t.streamsEnded(func() {
+			if t.routeChanges == changes {
+				t.routeChanges = nil
+			}
+		})
+
+		for {
+			var change RouteChange
+
+			// the streams are read in the order the events arrived over the connection
+			select {
+			case <-bindingCalled.Ready():
+				ev, err := bindingCalled.Recv()
+				if err != nil {
+					return
+				}
+				if ev.Name != routeChangeBinding {
+					continue
+				}
+
+				var payload struct {
+					Kind RouteChangeKind `json:"kind"`
+					URL  string          `json:"url"`
+				}
+				if err = json.Unmarshal([]byte(ev.Payload), &payload); err != nil {
+					log.Println("go-chrome-framework error: unable to decode route change", err.Error())
+					continue
+				}
+
+				change = RouteChange{URL: payload.URL, Kind: payload.Kind}
+			case <-frameNavigated.Ready():
+				ev, err := frameNavigated.Recv()
+				if err != nil {
+					return
+				}
+				if ev.Frame.ParentID != nil {
+					continue
+				}
+
+				change = RouteChange{URL: frameURL(ev.Frame), Kind: RouteChangeNavigation}
+			}
+			change.Time = time.Now()
+
+			changes.mu.Lock()
+			handlers := append([]RouteChangeHandler{}, changes.handlers...)
+			changes.mu.Unlock()
+
+			for _, handler := range handlers {
+				handler(change)
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package chrome
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// evictEvery is the number of reservations after which the idle hosts are evicted, so that the schedules are not
+// scanned on every reservation
+const evictEvery = 256
+
+// SchedulerOpts configures the spacing of navigations, see NewNavigationScheduler
+type SchedulerOpts struct {
+	// Delay is the politeness delay, the minimum time between the starts of two navigations to the same host
+	Delay time.Duration
+	// Rate limits the navigations to the same host per second, 0 disables the limit
+	Rate float64
+	// Burst is the number of navigations to the same host which may start at once within Rate, defaults to 1
+	Burst int
+	// HostDelay returns the politeness delay of the host, e.g. the crawl delay of its robots.txt. Delay is used for the
+	// hosts it returns 0 for
+	HostDelay func(host string) time.Duration
+}
+
+// NavigationScheduler spaces out the navigations to the same host, so that tabs navigating concurrently, e.g. the tabs
+// of a TabPool, respect the crawl delays of the sites. Navigations to different hosts do not wait for each other, and
+// navigations to the same host start in the order they were scheduled
+type NavigationScheduler struct {
+	opts  SchedulerOpts
+	mu    sync.Mutex
+	hosts map[string]*hostSchedule
+	// reservations since the idle hosts were evicted
+	reservations int
+}
+
+// hostSchedule is the token bucket and politeness delay of a host
+type hostSchedule struct {
+	// earliest time the next navigation may start at
+	next time.Time
+	// tokens left in the bucket at last
+	tokens float64
+	last   time.Time
+}
+
+// NewNavigationScheduler returns a scheduler spacing out navigations according to the options
+func NewNavigationScheduler(opts SchedulerOpts) *NavigationScheduler {
+	if opts.Burst < 1 {
+		opts.Burst = 1
+	}
+
+	return &NavigationScheduler{
+		opts:  opts,
+		hosts: make(map[string]*hostSchedule),
+	}
+}
+
+// Wait blocks until a navigation to the url may start or the context is done. The turn of the navigation is taken
+// even when the context is done before, hence the navigations scheduled after it still wait for it
+func (s *NavigationScheduler) Wait(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	delay := time.Until(s.reserve(u.Hostname()))
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Navigate waits for the turn of the navigation and navigates the tab to the url, opts may be nil. The navigation is
+// bound by the deadline of the context, if any
+func (s *NavigationScheduler) Navigate(ctx context.Context, tab Tab, url string, opts *NavigateOpts) (*NavigationResult, error) {
+	if err := s.Wait(ctx, url); err != nil {
+		return nil, err
+	}
+
+	return tab.NavigateWithOpts(url, opts, contextTimeout(ctx))
+}
+
+// reserve takes the next turn of the host and returns the time the navigation may start at
+func (s *NavigationScheduler) reserve(host string) time.Time {
+	// the delay is looked up without holding the lock, e.g. as fetching robots.txt takes a while
+	delay := s.delay(host)
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reservations++
+	if s.reservations >= evictEvery {
+		s.reservations = 0
+		s.evict(now)
+	}
+
+	schedule, ok := s.hosts[host]
+	if !ok {
+		schedule = &hostSchedule{tokens: float64(s.opts.Burst), last: now}
+		s.hosts[host] = schedule
+	}
+
+	at := now
+	if schedule.next.After(at) {
+		at = schedule.next
+	}
+
+	if s.opts.Rate > 0 {
+		tokens := schedule.tokens + at.Sub(schedule.last).Seconds()*s.opts.Rate
+		if tokens > float64(s.opts.Burst) {
+			tokens = float64(s.opts.Burst)
+		}
+
+		// wait for the bucket to refill with a token
+		if tokens < 1 {
+			at = at.Add(time.Duration((1 - tokens) / s.opts.Rate * float64(time.Second)))
+			tokens = 1
+		}
+
+		schedule.tokens = tokens - 1
+		schedule.last = at
+	}
+
+	schedule.next = at.Add(delay)
+
+	return at
+}
+
+// evict forgets the hosts whose next navigation may start right away with a full bucket, as a new schedule of the host
+// behaves the same, so that crawling many hosts does not grow the scheduler. Must be called with mu held
+func (s *NavigationScheduler) evict(now time.Time) {
+	for host, schedule := range s.hosts {
+		if schedule.next.After(now) {
+			continue
+		}
+
+		if s.opts.Rate > 0 && schedule.tokens+now.Sub(schedule.last).Seconds()*s.opts.Rate < float64(s.opts.Burst) {
+			continue
+		}
+
+		delete(s.hosts, host)
+	}
+}
+
+// delay returns the politeness delay of the host
+func (s *NavigationScheduler) delay(host string) time.Duration {
+	if s.opts.HostDelay != nil {
+		if delay := s.opts.HostDelay(host); delay > 0 {
+			return delay
+		}
+	}
+
+	return s.opts.Delay
+}
@@ -0,0 +1,90 @@
+package chrome
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNavigationSchedulerReserve(t *testing.T) {
+	tests := []struct {
+		name string
+		opts SchedulerOpts
+		// hosts reserved in turn
+		hosts []string
+		// start times of the reservations relative to the first one
+		offsets []time.Duration
+	}{
+		{
+			name:    "no limits",
+			hosts:   []string{"a", "a", "a"},
+			offsets: []time.Duration{0, 0, 0},
+		},
+		{
+			name:    "delay",
+			opts:    SchedulerOpts{Delay: time.Second},
+			hosts:   []string{"a", "a", "a"},
+			offsets: []time.Duration{0, time.Second, 2 * time.Second},
+		},
+		{
+			name:    "delay per host",
+			opts:    SchedulerOpts{Delay: time.Second},
+			hosts:   []string{"a", "b", "a", "b"},
+			offsets: []time.Duration{0, 0, time.Second, time.Second},
+		},
+		{
+			name: "host delay",
+			opts: SchedulerOpts{Delay: time.Second, HostDelay: func(host string) time.Duration {
+				if host == "slow" {
+					return 3 * time.Second
+				}
+				return 0
+			}},
+			hosts:   []string{"slow", "fast", "slow", "fast"},
+			offsets: []time.Duration{0, 0, 3 * time.Second, time.Second},
+		},
+		{
+			name:    "rate",
+			opts:    SchedulerOpts{Rate: 2},
+			hosts:   []string{"a", "a", "a"},
+			offsets: []time.Duration{0, 500 * time.Millisecond, time.Second},
+		},
+		{
+			name:    "burst",
+			opts:    SchedulerOpts{Rate: 1, Burst: 2},
+			hosts:   []string{"a", "a", "a", "a"},
+			offsets: []time.Duration{0, 0, time.Second, 2 * time.Second},
+		},
+		{
+			name:    "delay longer than the rate",
+			opts:    SchedulerOpts{Rate: 10, Delay: time.Second},
+			hosts:   []string{"a", "a", "a"},
+			offsets: []time.Duration{0, time.Second, 2 * time.Second},
+		},
+		{
+			name:    "rate slower than the delay",
+			opts:    SchedulerOpts{Rate: 0.5, Delay: time.Second},
+			hosts:   []string{"a", "a", "a"},
+			offsets: []time.Duration{0, 2 * time.Second, 4 * time.Second},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scheduler := NewNavigationScheduler(test.opts)
+
+			var start time.Time
+			for i, host := range test.hosts {
+				at := scheduler.reserve(host)
+				if i == 0 {
+					start = at
+				}
+
+				// the reservations are made a few microseconds apart, and the bucket refills in floating point
+				offset := at.Sub(start)
+				if offset < test.offsets[i]-time.Millisecond || offset > test.offsets[i]+50*time.Millisecond {
+					t.Errorf("reservation %v for %v starts after %v, want %v", i+1, host, offset, test.offsets[i])
+				}
+			}
+		})
+	}
+}
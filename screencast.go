@@ -0,0 +1,286 @@
+package chrome
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/page"
+	"io"
+	"log"
+	"os/exec"
+	"time"
+)
+
+var (
+	// ErrScreencastStarted is returned when a screencast is started on a tab which is already being recorded
+	ErrScreencastStarted = errors.New("go-chrome-framework: screencast already started")
+	// ErrScreencastNotStarted is returned when a screencast is stopped on a tab which is not being recorded
+	ErrScreencastNotStarted = errors.New("go-chrome-framework: screencast not started")
+)
+
+// ScreencastFrame is a single compressed image of the tab in the format requested in ScreencastOpts
+type ScreencastFrame struct {
+	Data     []byte
+	Metadata page.ScreencastFrameMetadata
+}
+
+// ScreencastEncoder receives the frames of a screencast. Encode is called sequentially for every frame and Close is
+// called once the screencast is stopped
+type ScreencastEncoder interface {
+	Encode(frame ScreencastFrame) error
+	Close() error
+}
+
+// ScreencastEncoderFunc adapts a function to a ScreencastEncoder which does nothing on Close
+type ScreencastEncoderFunc func(frame ScreencastFrame) error
+
+func (f ScreencastEncoderFunc) Encode(frame ScreencastFrame) error {
+	return f(frame)
+}
+
+func (f ScreencastEncoderFunc) Close() error {
+	return nil
+}
+
+type screencast struct {
+	// cancels the frame stream
+	cancel context.CancelFunc
+	// receives the first encoding error, if any, once the frame stream is drained
+	done chan error
+	// encoder receiving the frames
+	encoder ScreencastEncoder
+}
+
+func (t *tab) StartScreencast(opts ScreencastOpts, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if t.screencast != nil {
+		return ErrScreencastStarted
+	}
+
+	if opts.Encoder == nil {
+		return errors.New("go-chrome-framework: screencast encoder is required")
+	}
+
+	if opts.Format == "" {
+		opts.Format = "jpeg"
+	}
+
+	// frames are received until the screencast is stopped, hence the stream is not bound to the timeout
+	client, err := t.currentClient()
+	if err != nil {
+		return err
+	}
+
+	streamCtx, streamCancel := context.WithCancel(context.Background())
+
+	frames, err := client.Page.ScreencastFrame(streamCtx)
+	if err != nil {
+		streamCancel()
+		log.Println("go-chrome-framework error: unable to open screencast frame client", err.Error())
+		return err
+	}
+
+	if err = client.Page.Enable(ctx); err != nil {
+		streamCancel()
+		log.Println("go-chrome-framework error: unable to enable page domain", err.Error())
+		return err
+	}
+
+	startArgs := page.NewStartScreencastArgs().SetFormat(opts.Format)
+	if opts.Quality > 0 {
+		startArgs.SetQuality(opts.Quality)
+	}
+	if opts.MaxWidth > 0 {
+		startArgs.SetMaxWidth(opts.MaxWidth)
+	}
+	if opts.MaxHeight > 0 {
+		startArgs.SetMaxHeight(opts.MaxHeight)
+	}
+	if opts.EveryNthFrame > 0 {
+		startArgs.SetEveryNthFrame(opts.EveryNthFrame)
+	}
+
+	if err = client.Page.StartScreencast(ctx, startArgs); err != nil {
+		streamCancel()
+		log.Println("go-chrome-framework error: unable to start screencast", err.Error())
+		return err
+	}
+
+	t.screencast = &screencast{
+		cancel:  streamCancel,
+		done:    make(chan error, 1),
+		encoder: opts.Encoder,
+	}
+
+	go func(done chan<- error, encoder ScreencastEncoder) {
+		defer closeRes(frames)
+
+		var encodeErr error
+		for {
+			frame, err := frames.Recv()
+			if err != nil {
+				done <- encodeErr
+				return
+			}
+
+			// chrome stops sending frames until the previous one is acknowledged
+			err = t.call(ctx, func(client *cdp.Client) error {
+				return client.Page.ScreencastFrameAck(streamCtx, page.NewScreencastFrameAckArgs(frame.SessionID))
+			})
+			if err != nil {
+				log.Println("go-chrome-framework error: unable to acknowledge screencast frame", err.Error())
+			}
+
+			if encodeErr == nil {
+				encodeErr = encoder.Encode(ScreencastFrame{Data: frame.Data, Metadata: frame.Metadata})
+			}
+		}
+	}(t.screencast.done, opts.Encoder)
+
+	return nil
+}
+
+func (t *tab) StopScreencast(timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	if t.screencast == nil {
+		return ErrScreencastNotStarted
+	}
+
+	screencast := t.screencast
+	t.screencast = nil
+
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.Page.StopScreencast(ctx)
+	})
+
+	// drain the frame stream before closing the encoder
+	screencast.cancel()
+	encodeErr := <-screencast.done
+	closeErr := screencast.encoder.Close()
+
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to stop screencast", err.Error())
+		return err
+	}
+	if encodeErr != nil {
+		return encodeErr
+	}
+
+	return closeErr
+}
+
+type ffmpegEncoder struct {
+	command   *exec.Cmd
+	stdin     io.WriteCloser
+	framerate int
+	// timestamp of the first frame, the frames are placed on the timeline of the video relative to it
+	start time.Time
+	// most recent frame, written until the timestamp of the next frame is reached
+	last []byte
+	// number of frames written to ffmpeg
+	written int
+}
+
+// NewFFmpegEncoder returns a ScreencastEncoder which pipes the frames into ffmpeg to produce a video at output. The
+// container and codec are picked by ffmpeg based on the extension of output, e.g. .webm or .mp4. If path is empty
+// ffmpeg is looked up in PATH. Chrome only sends frames when the page changes, hence frames are repeated or dropped
+// according to their timestamps so that the video plays at the pace the page was recorded at
+func NewFFmpegEncoder(path string, output string, framerate int) (ScreencastEncoder, error) {
+	if path == "" {
+		path = "ffmpeg"
+	}
+
+	if framerate <= 0 {
+		framerate = 25
+	}
+
+	command := exec.Command(path,
+		"-y",
+		"-f", "image2pipe",
+		"-framerate", fmt.Sprintf("%v", framerate),
+		"-i", "-",
+		"-an",
+		output,
+	)
+
+	stdin, err := command.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = command.Start(); err != nil {
+		log.Println("go-chrome-framework error: unable to launch ffmpeg", err.Error())
+		return nil, err
+	}
+
+	return &ffmpegEncoder{command: command, stdin: stdin, framerate: framerate}, nil
+}
+
+func (f *ffmpegEncoder) Encode(frame ScreencastFrame) error {
+	timestamp := time.Now()
+	if frame.Metadata.Timestamp > 0 {
+		timestamp = frame.Metadata.Timestamp.Time()
+	}
+
+	if f.last == nil {
+		f.start = timestamp
+	} else if err := f.fill(timestamp); err != nil {
+		return err
+	}
+
+	// frames sharing the slot of the video of an earlier frame replace it
+	f.last = frame.Data
+
+	return nil
+}
+
+// fill writes the most recent frame until the video reaches the timestamp
+func (f *ffmpegEncoder) fill(timestamp time.Time) error {
+	slot := int(timestamp.Sub(f.start).Seconds() * float64(f.framerate))
+	for ; f.written < slot; f.written++ {
+		if _, err := f.stdin.Write(f.last); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *ffmpegEncoder) Close() error {
+	// the last frame is shown until the screencast is stopped, and at least once
+	if f.last != nil {
+		err := f.fill(time.Now())
+		if err == nil && f.written == 0 {
+			_, err = f.stdin.Write(f.last)
+		}
+		if err != nil {
+			_ = f.stdin.Close()
+			_ = f.command.Wait()
+			return err
+		}
+	}
+
+	// closing stdin signals the end of the input to ffmpeg
+	if err := f.stdin.Close(); err != nil {
+		return err
+	}
+
+	return f.command.Wait()
+}
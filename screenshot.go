@@ -0,0 +1,244 @@
+package chrome
+
+import (
+	"context"
+	"fmt"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/dom"
+	"github.com/mafredri/cdp/protocol/emulation"
+	"github.com/mafredri/cdp/protocol/page"
+	"io"
+	"log"
+	"math"
+	"os"
+	"time"
+)
+
+func (t *tab) CaptureScreenshotTo(w io.Writer, opts ScreenshotOpts, timeout time.Duration) error {
+	span := t.startSpan("tab.CaptureScreenshot")
+	err := t.captureScreenshotTo(w, opts, timeout)
+	endSpan(span, err)
+
+	return err
+}
+
+func (t *tab) captureScreenshotTo(w io.Writer, opts ScreenshotOpts, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	data, err := t.captureScreenshot(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+func (t *tab) CaptureScreenshotFile(path string, opts ScreenshotOpts, timeout time.Duration) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = t.CaptureScreenshotTo(file, opts, timeout)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// captureScreenshot captures the full page and returns the decoded image
+func (t *tab) captureScreenshot(ctx context.Context, opts ScreenshotOpts) ([]byte, error) {
+	if err := t.waitForFonts(ctx); err != nil {
+		return nil, err
+	}
+
+	client, err := t.currentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the document root node. We can pass nil here
+	// since this method only takes optional arguments.
+	doc, err := client.DOM.GetDocument(ctx, nil)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to get DOM root node", err.Error())
+		return nil, err
+	}
+
+	querySelectorArgs := dom.NewQuerySelectorArgs(doc.Root.NodeID, "body")
+	bodyNode, err := client.DOM.QuerySelector(ctx, querySelectorArgs)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to get DOM root node", err.Error())
+		return nil, err
+	}
+
+	getBoxModelArgs := dom.NewGetBoxModelArgs().SetNodeID(bodyNode.NodeID)
+	bodyBoxModel, err := client.DOM.GetBoxModel(ctx, getBoxModelArgs)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to get DOM root node", err.Error())
+		return nil, err
+	}
+
+	if opts.Width == 0 {
+		opts.Width = 800
+	}
+
+	if opts.Height == 0 {
+		opts.Height = bodyBoxModel.Model.Height
+	}
+
+	if opts.DeviceScaleFactor == 0 {
+		opts.DeviceScaleFactor = 1.0
+	}
+
+	deviceMetricsOverrideArgs := emulation.NewSetDeviceMetricsOverrideArgs(opts.Width, opts.Height, opts.DeviceScaleFactor, opts.Mobile)
+	if err = client.Emulation.SetDeviceMetricsOverride(ctx, deviceMetricsOverrideArgs); err != nil {
+		log.Println("go-chrome-framework error: unable to set device metrics", err.Error())
+		return nil, err
+	}
+
+	// leave the viewport the way it was before the screenshot
+	defer func() {
+		if err := t.restoreViewport(ctx); err != nil {
+			log.Println("go-chrome-framework error: unable to restore viewport", err.Error())
+		}
+	}()
+
+	screenshotArgs := page.NewCaptureScreenshotArgs().SetFormat(screenshotFormat(opts))
+	if screenshotFormat(opts) == "jpeg" {
+		if opts.Quality == 0 {
+			opts.Quality = 80
+		}
+		screenshotArgs.SetQuality(opts.Quality)
+	}
+	if t.browser != nil && t.browser.supports(ctx, captureBeyondViewportVersion) {
+		screenshotArgs.SetCaptureBeyondViewport(true)
+	}
+	var screenshot *page.CaptureScreenshotReply
+	err = t.call(ctx, func(client *cdp.Client) (err error) {
+		screenshot, err = client.Page.CaptureScreenshot(ctx, screenshotArgs)
+		return err
+	})
+	if err != nil {
+		// error
+		return nil, err
+	}
+
+	t.metrics().ObserveScreenshot(len(screenshot.Data))
+
+	return screenshot.Data, nil
+}
+
+// screenshotFormat returns the image format of the screenshot, png unless jpeg is requested
+func screenshotFormat(opts ScreenshotOpts) string {
+	if opts.Format == "jpeg" {
+		return "jpeg"
+	}
+
+	return "png"
+}
+
+func (t *tab) ScreenshotElement(selector string, opts ElementScreenshotOpts, timeout time.Duration) ([]byte, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return nil, err
+	}
+
+	nodeID, err := t.querySelector(ctx, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = t.waitForFonts(ctx); err != nil {
+		return nil, err
+	}
+
+	client, err := t.currentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.ScrollIntoView {
+		err = client.DOM.ScrollIntoViewIfNeeded(ctx, dom.NewScrollIntoViewIfNeededArgs().SetNodeID(nodeID))
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to scroll element into view", err.Error())
+			return nil, err
+		}
+	}
+
+	boxModel, err := client.DOM.GetBoxModel(ctx, dom.NewGetBoxModelArgs().SetNodeID(nodeID))
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to get element box model", err.Error())
+		return nil, err
+	}
+
+	// box model is relative to the viewport while the clip is relative to the page
+	layoutMetrics, err := client.Page.GetLayoutMetrics(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to get layout metrics", err.Error())
+		return nil, err
+	}
+
+	clip := quadBounds(boxModel.Model.Border)
+	clip.X += float64(layoutMetrics.LayoutViewport.PageX) - opts.Padding
+	clip.Y += float64(layoutMetrics.LayoutViewport.PageY) - opts.Padding
+	clip.Width += 2 * opts.Padding
+	clip.Height += 2 * opts.Padding
+	clip.Scale = 1
+
+	if clip.Width <= 0 || clip.Height <= 0 {
+		return nil, fmt.Errorf("go-chrome-framework: element %v is not visible", selector)
+	}
+
+	format := screenshotFormat(ScreenshotOpts{Format: opts.Format})
+	screenshotArgs := page.NewCaptureScreenshotArgs().SetFormat(format).SetClip(clip)
+	if format == "jpeg" {
+		if opts.Quality == 0 {
+			opts.Quality = 80
+		}
+		screenshotArgs.SetQuality(opts.Quality)
+	}
+	if t.browser != nil && t.browser.supports(ctx, captureBeyondViewportVersion) {
+		screenshotArgs.SetCaptureBeyondViewport(true)
+	}
+
+	var screenshot *page.CaptureScreenshotReply
+	err = t.call(ctx, func(client *cdp.Client) (err error) {
+		screenshot, err = client.Page.CaptureScreenshot(ctx, screenshotArgs)
+		return err
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to capture element screenshot", err.Error())
+		return nil, err
+	}
+
+	t.metrics().ObserveScreenshot(len(screenshot.Data))
+
+	return screenshot.Data, nil
+}
+
+// quadBounds returns the bounding rectangle of a quad of four x, y points
+func quadBounds(quad dom.Quad) page.Viewport {
+	if len(quad) < 8 {
+		return page.Viewport{}
+	}
+
+	minX, minY, maxX, maxY := quad[0], quad[1], quad[0], quad[1]
+	for i := 2; i+1 < len(quad); i += 2 {
+		minX, maxX = math.Min(minX, quad[i]), math.Max(maxX, quad[i])
+		minY, maxY = math.Min(minY, quad[i+1]), math.Max(maxY, quad[i+1])
+	}
+
+	return page.Viewport{X: minX, Y: minY, Width: maxX - minX, Height: maxY - minY}
+}
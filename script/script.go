@@ -0,0 +1,334 @@
+// Package script runs declarative automation scripts written in yaml or json, so that scraping and rendering jobs can
+// be authored without writing go. A script is a list of steps run in order on a tab, e.g.
+//
+//	timeout: 30s
+//	steps:
+//	  - navigate: https://example.com/login
+//	  - wait: "#user"
+//	  - fill: {"#user": alice, "#password": secret}
+//	  - click: "#submit"
+//	  - waitForNav: true
+//	  - extract: {name: greeting, selector: h1}
+//	  - screenshot: {path: dashboard.png}
+//
+// Every step sets exactly one of its fields
+package script
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	chrome "go.ajitem.com/gcf/v3"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidScript is wrapped by the errors of scripts which cannot be run
+var ErrInvalidScript = errors.New("go-chrome-framework: invalid script")
+
+type Script struct {
+	// Timeout of the whole script as a duration, e.g. "30s", defaults to the default timeout of the tab
+	Timeout string `json:"timeout" yaml:"timeout"`
+	Steps   []Step `json:"steps" yaml:"steps"`
+}
+
+type Step struct {
+	// Navigate navigates to the url
+	Navigate string `json:"navigate,omitempty" yaml:"navigate,omitempty"`
+	// Wait waits until an element matches the selector
+	Wait string `json:"wait,omitempty" yaml:"wait,omitempty"`
+	// Fill sets the values of the form fields by their selectors, see chrome.Tab.FillForm
+	Fill map[string]string `json:"fill,omitempty" yaml:"fill,omitempty"`
+	// Type types text into the element matching a selector
+	Type *TypeStep `json:"type,omitempty" yaml:"type,omitempty"`
+	// Click clicks the element matching the selector
+	Click string `json:"click,omitempty" yaml:"click,omitempty"`
+	// WaitForNav waits until the navigation triggered by the previous step has loaded
+	WaitForNav bool `json:"waitForNav,omitempty" yaml:"waitForNav,omitempty"`
+	// Sleep pauses for the duration, e.g. "500ms"
+	Sleep string `json:"sleep,omitempty" yaml:"sleep,omitempty"`
+	// Screenshot captures a screenshot to a file
+	Screenshot *ScreenshotStep `json:"screenshot,omitempty" yaml:"screenshot,omitempty"`
+	// PDF prints the page to a pdf file
+	PDF *PDFStep `json:"pdf,omitempty" yaml:"pdf,omitempty"`
+	// Extract stores the text, html or an attribute of an element in the result
+	Extract *ExtractStep `json:"extract,omitempty" yaml:"extract,omitempty"`
+}
+
+type TypeStep struct {
+	Selector string `json:"selector" yaml:"selector"`
+	Text     string `json:"text" yaml:"text"`
+}
+
+type ScreenshotStep struct {
+	// Path of the image, relative to the output directory of the runner
+	Path string `json:"path" yaml:"path"`
+	// Format of the image, either "png" or "jpeg", defaults to "png"
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+	// Quality of jpeg images from 0 to 100
+	Quality int `json:"quality,omitempty" yaml:"quality,omitempty"`
+	Width   int `json:"width,omitempty" yaml:"width,omitempty"`
+	Height  int `json:"height,omitempty" yaml:"height,omitempty"`
+}
+
+type PDFStep struct {
+	// Path of the pdf, relative to the output directory of the runner
+	Path            string `json:"path" yaml:"path"`
+	Landscape       bool   `json:"landscape,omitempty" yaml:"landscape,omitempty"`
+	PrintBackground bool   `json:"printBackground,omitempty" yaml:"printBackground,omitempty"`
+}
+
+type ExtractStep struct {
+	// Name the value is stored under in the result
+	Name     string `json:"name" yaml:"name"`
+	Selector string `json:"selector" yaml:"selector"`
+	// Attribute extracts the attribute of the element instead of its text
+	Attribute string `json:"attribute,omitempty" yaml:"attribute,omitempty"`
+	// HTML extracts the inner html of the element instead of its text
+	HTML bool `json:"html,omitempty" yaml:"html,omitempty"`
+}
+
+// Result of running a script
+type Result struct {
+	// Extracted values by the names of the extract steps
+	Extracted map[string]string
+	// Files written by the screenshot and pdf steps
+	Files []string
+}
+
+// Parse parses a yaml or json script, unknown fields are rejected. Scripts starting with { are parsed as json
+func Parse(data []byte) (*Script, error) {
+	script := new(Script)
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(script); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidScript, err)
+		}
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(script); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidScript, err)
+		}
+	}
+
+	if err := script.Validate(); err != nil {
+		return nil, err
+	}
+
+	return script, nil
+}
+
+// Load reads and parses the script file
+func Load(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data)
+}
+
+// Validate checks that every step sets exactly one field and that the durations parse
+func (s *Script) Validate() error {
+	if s.Timeout != "" {
+		if _, err := time.ParseDuration(s.Timeout); err != nil {
+			return fmt.Errorf("%w: timeout: %v", ErrInvalidScript, err)
+		}
+	}
+
+	for i, step := range s.Steps {
+		kinds := step.kinds()
+		if len(kinds) != 1 {
+			return fmt.Errorf("%w: step %v sets %v fields instead of one: %v", ErrInvalidScript, i+1, len(kinds),
+				strings.Join(kinds, ", "))
+		}
+
+		if step.Sleep != "" {
+			if _, err := time.ParseDuration(step.Sleep); err != nil {
+				return fmt.Errorf("%w: step %v: %v", ErrInvalidScript, i+1, err)
+			}
+		}
+
+		if step.Extract != nil && (step.Extract.Name == "" || step.Extract.Selector == "") {
+			return fmt.Errorf("%w: step %v: extract needs a name and a selector", ErrInvalidScript, i+1)
+		}
+
+		if step.Screenshot != nil && step.Screenshot.Path == "" || step.PDF != nil && step.PDF.Path == "" {
+			return fmt.Errorf("%w: step %v: missing path", ErrInvalidScript, i+1)
+		}
+	}
+
+	return nil
+}
+
+// kinds returns the names of the fields the step sets
+func (s Step) kinds() []string {
+	var kinds []string
+	for name, set := range map[string]bool{
+		"navigate":   s.Navigate != "",
+		"wait":       s.Wait != "",
+		"fill":       len(s.Fill) > 0,
+		"type":       s.Type != nil,
+		"click":      s.Click != "",
+		"waitForNav": s.WaitForNav,
+		"sleep":      s.Sleep != "",
+		"screenshot": s.Screenshot != nil,
+		"pdf":        s.PDF != nil,
+		"extract":    s.Extract != nil,
+	} {
+		if set {
+			kinds = append(kinds, name)
+		}
+	}
+	sort.Strings(kinds)
+
+	return kinds
+}
+
+// Runner runs scripts on tabs
+type Runner struct {
+	// OutputDir is the directory screenshots and pdfs are written to, defaults to the working directory. Paths of
+	// scripts may not leave it
+	OutputDir string
+}
+
+// Run runs the script on the tab, stopping at the first failing step with a *chrome.ActionError
+func (r *Runner) Run(ctx context.Context, tab chrome.Tab, script *Script) (*Result, error) {
+	if err := script.Validate(); err != nil {
+		return nil, err
+	}
+
+	if script.Timeout != "" {
+		timeout, _ := time.ParseDuration(script.Timeout)
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	result := &Result{Extracted: make(map[string]string)}
+
+	actions := tab.Actions()
+	for _, step := range script.Steps {
+		step := step
+
+		switch {
+		case step.Navigate != "":
+			actions.Navigate(step.Navigate)
+		case step.Wait != "":
+			actions.WaitFor(step.Wait)
+		case len(step.Fill) > 0:
+			actions.Fill(step.Fill)
+		case step.Type != nil:
+			actions.Type(step.Type.Selector, step.Type.Text)
+		case step.Click != "":
+			actions.Click(step.Click)
+		case step.WaitForNav:
+			actions.WaitForNav()
+		case step.Sleep != "":
+			duration, _ := time.ParseDuration(step.Sleep)
+			actions.Sleep(duration)
+		case step.Screenshot != nil:
+			actions.Do("screenshot "+step.Screenshot.Path, func(ctx context.Context, tab chrome.Tab) error {
+				path, err := r.output(step.Screenshot.Path)
+				if err != nil {
+					return err
+				}
+
+				opts := chrome.ScreenshotOpts{
+					Format:  step.Screenshot.Format,
+					Quality: step.Screenshot.Quality,
+					Width:   step.Screenshot.Width,
+					Height:  step.Screenshot.Height,
+				}
+				if err = tab.CaptureScreenshotFile(path, opts, timeout(ctx)); err != nil {
+					return err
+				}
+
+				result.Files = append(result.Files, path)
+				return nil
+			})
+		case step.PDF != nil:
+			actions.Do("pdf "+step.PDF.Path, func(ctx context.Context, tab chrome.Tab) error {
+				path, err := r.output(step.PDF.Path)
+				if err != nil {
+					return err
+				}
+
+				opts := chrome.PDFOpts{Landscape: step.PDF.Landscape, PrintBackground: step.PDF.PrintBackground}
+				data, err := tab.PrintToPDF(opts, timeout(ctx))
+				if err != nil {
+					return err
+				}
+
+				if err = os.WriteFile(path, data, 0644); err != nil {
+					return err
+				}
+
+				result.Files = append(result.Files, path)
+				return nil
+			})
+		case step.Extract != nil:
+			actions.Do("extract "+step.Extract.Name, func(ctx context.Context, tab chrome.Tab) error {
+				var value string
+				var err error
+				switch {
+				case step.Extract.Attribute != "":
+					value, err = tab.GetAttribute(step.Extract.Selector, step.Extract.Attribute, timeout(ctx))
+				case step.Extract.HTML:
+					value, err = tab.GetInnerHTML(step.Extract.Selector, timeout(ctx))
+				default:
+					value, err = tab.GetText(step.Extract.Selector, timeout(ctx))
+				}
+				if err != nil {
+					return err
+				}
+
+				result.Extracted[step.Extract.Name] = value
+				return nil
+			})
+		}
+	}
+
+	return result, actions.Run(ctx)
+}
+
+// output resolves the path of a file written by a script within the output directory
+func (r *Runner) output(path string) (string, error) {
+	dir := r.OutputDir
+	if dir == "" {
+		dir = "."
+	}
+
+	resolved := filepath.Join(dir, filepath.Clean("/"+path))
+	if err := os.MkdirAll(filepath.Dir(resolved), 0755); err != nil {
+		return "", err
+	}
+
+	return resolved, nil
+}
+
+// timeout returns the time left until the deadline of the context
+func timeout(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0
+	}
+
+	// a timeout of zero would fall back to the default timeout of the tab instead of failing
+	if remaining := time.Until(deadline); remaining > 0 {
+		return remaining
+	}
+
+	return time.Nanosecond
+}
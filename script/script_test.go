@@ -0,0 +1,140 @@
+package script
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		steps []Step
+		err   bool
+	}{
+		{
+			name: "json",
+			data: `{"timeout": "30s", "steps": [
+				{"navigate": "https://example.com"},
+				{"fill": {"#user": "alice"}},
+				{"type": {"selector": "#q", "text": "chrome"}},
+				{"waitForNav": true},
+				{"extract": {"name": "title", "selector": "h1", "html": true}},
+				{"screenshot": {"path": "page.png"}}
+			]}`,
+			steps: []Step{
+				{Navigate: "https://example.com"},
+				{Fill: map[string]string{"#user": "alice"}},
+				{Type: &TypeStep{Selector: "#q", Text: "chrome"}},
+				{WaitForNav: true},
+				{Extract: &ExtractStep{Name: "title", Selector: "h1", HTML: true}},
+				{Screenshot: &ScreenshotStep{Path: "page.png"}},
+			},
+		},
+		{name: "unknown field", data: `{"steps": [{"navigate": "https://example.com", "follow": true}]}`, err: true},
+		{name: "step setting two fields", data: `{"steps": [{"navigate": "https://example.com", "click": "a"}]}`, err: true},
+		{name: "step setting no field", data: `{"steps": [{}]}`, err: true},
+		{name: "invalid timeout", data: `{"timeout": "soon", "steps": []}`, err: true},
+		{name: "invalid sleep", data: `{"steps": [{"sleep": "a while"}]}`, err: true},
+		{name: "extract without a name", data: `{"steps": [{"extract": {"selector": "h1"}}]}`, err: true},
+		{name: "screenshot without a path", data: `{"steps": [{"screenshot": {"format": "png"}}]}`, err: true},
+		{name: "pdf without a path", data: `{"steps": [{"pdf": {"landscape": true}}]}`, err: true},
+		{name: "malformed json", data: `{"steps": [`, err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			script, err := Parse([]byte(test.data))
+			if test.err {
+				if !errors.Is(err, ErrInvalidScript) {
+					t.Fatalf("Parse() = %v, want %v", err, ErrInvalidScript)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() = %v", err)
+			}
+
+			if !reflect.DeepEqual(script.Steps, test.steps) {
+				t.Errorf("Parse() steps = %+v, want %+v", script.Steps, test.steps)
+			}
+		})
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	script, err := Parse([]byte(`
+timeout: 30s
+steps:
+  - navigate: https://example.com/login
+  - fill: {"#user": alice}
+  - click: "#submit"
+  - sleep: 500ms
+`))
+	if err != nil {
+		t.Fatalf("Parse() = %v", err)
+	}
+
+	want := &Script{Timeout: "30s", Steps: []Step{
+		{Navigate: "https://example.com/login"},
+		{Fill: map[string]string{"#user": "alice"}},
+		{Click: "#submit"},
+		{Sleep: "500ms"},
+	}}
+	if !reflect.DeepEqual(script, want) {
+		t.Errorf("Parse() = %+v, want %+v", script, want)
+	}
+
+	_, err = Parse([]byte("steps:\n  - navigate: https://example.com\n    follow: true\n"))
+	if !errors.Is(err, ErrInvalidScript) {
+		t.Errorf("Parse() with an unknown field = %v, want %v", err, ErrInvalidScript)
+	}
+}
+
+func TestStepKinds(t *testing.T) {
+	tests := []struct {
+		name  string
+		step  Step
+		kinds []string
+	}{
+		{"none", Step{}, nil},
+		{"one", Step{Click: "a"}, []string{"click"}},
+		{"several in order", Step{Wait: "a", Click: "a", PDF: &PDFStep{}}, []string{"click", "pdf", "wait"}},
+		{"empty fill", Step{Fill: map[string]string{}}, nil},
+	}
+
+	for _, test := range tests {
+		if kinds := test.step.kinds(); !reflect.DeepEqual(kinds, test.kinds) {
+			t.Errorf("%v: kinds() = %v, want %v", test.name, kinds, test.kinds)
+		}
+	}
+}
+
+func TestRunnerOutput(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"file", "page.png", filepath.Join(dir, "page.png")},
+		{"nested file", "shots/page.png", filepath.Join(dir, "shots", "page.png")},
+		{"file outside the output directory", "../../page.png", filepath.Join(dir, "page.png")},
+		{"absolute path", "/tmp/page.png", filepath.Join(dir, "tmp", "page.png")},
+	}
+
+	runner := &Runner{OutputDir: dir}
+	for _, test := range tests {
+		path, err := runner.output(test.path)
+		if err != nil {
+			t.Fatalf("%v: output(%q) = %v", test.name, test.path, err)
+		}
+
+		if path != test.want {
+			t.Errorf("%v: output(%q) = %v, want %v", test.name, test.path, path, test.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package chrome
+
+import (
+	"context"
+	"fmt"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/dom"
+	"log"
+	"time"
+)
+
+func (t *tab) ScrollTo(x, y float64, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	err := t.evaluate(ctx, fmt.Sprintf("window.scrollTo(%v, %v)", x, y), nil)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to scroll", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (t *tab) ScrollIntoView(selector string, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	nodeID, err := t.querySelector(ctx, selector)
+	if err != nil {
+		return err
+	}
+
+	err = t.call(ctx, func(client *cdp.Client) error {
+		return client.DOM.ScrollIntoViewIfNeeded(ctx, dom.NewScrollIntoViewIfNeededArgs().SetNodeID(nodeID))
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to scroll element into view", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// ScrollToBottom scrolls down by step pixels at a time, waiting for delay after every step so that lazily loaded
+// content has a chance to load, until the bottom of the page is reached. A step of zero scrolls by the height of the
+// viewport
+func (t *tab) ScrollToBottom(step float64, delay time.Duration, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	stepExpression := fmt.Sprintf("%v", step)
+	if step <= 0 {
+		stepExpression = "window.innerHeight"
+	}
+
+	script := fmt.Sprintf(`(() => {
+		window.scrollBy(0, %v);
+		const scroller = document.scrollingElement || document.documentElement;
+		return Math.ceil(window.scrollY + window.innerHeight) >= scroller.scrollHeight;
+	})()`, stepExpression)
+
+	for {
+		var bottom bool
+		if err := t.evaluate(ctx, script, &bottom); err != nil {
+			log.Println("go-chrome-framework error: unable to scroll", err.Error())
+			return err
+		}
+
+		if bottom {
+			return nil
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp/protocol/network"
+	"log"
+	"time"
+)
+
+// SecurityState is the security state of the page as shown in the address bar
+type SecurityState struct {
+	// State is one of "unknown", "neutral", "insecure", "secure", "info" or "insecure-broken"
+	State string
+	// Certificate of the page, nil for pages not loaded over tls
+	Certificate *CertificateDetails
+	// Issues lists the ids of the issues lowering the security state
+	Issues []string
+}
+
+// CertificateDetails describes the tls connection and certificate a response was received over
+type CertificateDetails struct {
+	Protocol    string
+	KeyExchange string
+	Cipher      string
+	SubjectName string
+	Issuer      string
+	// SANs are the subject alternative names, only reported for responses
+	SANs      []string
+	ValidFrom time.Time
+	ValidTo   time.Time
+}
+
+// ExpiresWithin reports whether the certificate expires within the duration from now
+func (c *CertificateDetails) ExpiresWithin(d time.Duration) bool {
+	return time.Until(c.ValidTo) < d
+}
+
+// newCertificateDetails returns the details of the certificate of a response, or nil if the response was not received
+// over tls
+func newCertificateDetails(details *network.SecurityDetails) *CertificateDetails {
+	if details == nil {
+		return nil
+	}
+
+	return &CertificateDetails{
+		Protocol:    details.Protocol,
+		KeyExchange: details.KeyExchange,
+		Cipher:      details.Cipher,
+		SubjectName: details.SubjectName,
+		Issuer:      details.Issuer,
+		SANs:        details.SanList,
+		ValidFrom:   details.ValidFrom.Time(),
+		ValidTo:     details.ValidTo.Time(),
+	}
+}
+
+// SecurityState returns the security state of the page currently loaded in the tab
+func (t *tab) SecurityState(timeout time.Duration) (*SecurityState, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return nil, err
+	}
+
+	// the current state is reported as soon as the domain is enabled
+	client, err := t.currentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	stateChanged, err := client.Security.VisibleSecurityStateChanged(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to open visible security state changed client", err.Error())
+		return nil, err
+	}
+	defer closeRes(stateChanged)
+
+	if err = client.Security.Enable(ctx); err != nil {
+		log.Println("go-chrome-framework error: unable to enable security domain", err.Error())
+		return nil, err
+	}
+	defer client.Security.Disable(ctx)
+
+	ev, err := stateChanged.Recv()
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to get visible security state", err.Error())
+		return nil, err
+	}
+
+	state := &SecurityState{
+		State:  string(ev.VisibleSecurityState.SecurityState),
+		Issues: ev.VisibleSecurityState.SecurityStateIssueIDs,
+	}
+
+	if certificate := ev.VisibleSecurityState.CertificateSecurityState; certificate != nil {
+		state.Certificate = &CertificateDetails{
+			Protocol:    certificate.Protocol,
+			KeyExchange: certificate.KeyExchange,
+			Cipher:      certificate.Cipher,
+			SubjectName: certificate.SubjectName,
+			Issuer:      certificate.Issuer,
+			ValidFrom:   certificate.ValidFrom.Time(),
+			ValidTo:     certificate.ValidTo.Time(),
+		}
+	}
+
+	return state, nil
+}
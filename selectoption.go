@@ -0,0 +1,83 @@
+package chrome
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ErrOptionNotFound is returned when a select element has no option with a value or label passed to SelectOption or
+// SelectByLabel
+var ErrOptionNotFound = errors.New("go-chrome-framework: option not found")
+
+// selectScript selects the options of the select element whose value, or label if byLabel is set, is one of the given
+// ones and dispatches input and change events. It returns the values not matching any option, or null when the element
+// is not a select element
+const selectScript = `function(wanted, byLabel) {
+	if (this.tagName.toLowerCase() !== 'select') {
+		return null;
+	}
+	const key = option => byLabel ? option.label : option.value;
+	const options = Array.from(this.options);
+	const missing = wanted.filter(value => !options.some(option => key(option) === value));
+	if (missing.length > 0) {
+		return missing;
+	}
+	for (const option of options) {
+		option.selected = wanted.includes(key(option));
+	}
+	this.dispatchEvent(new Event('input', {bubbles: true}));
+	this.dispatchEvent(new Event('change', {bubbles: true}));
+	return [];
+}`
+
+// SelectOption selects the options of the select element with the given values, deselecting the others. Multiple
+// values can only be selected by multi-selects
+func (e *element) SelectOption(timeout time.Duration, values ...string) error {
+	return e.selectOptions(timeout, values, false)
+}
+
+// SelectByLabel selects the options of the select element with the given labels, deselecting the others. Multiple
+// labels can only be selected by multi-selects
+func (e *element) SelectByLabel(timeout time.Duration, labels ...string) error {
+	return e.selectOptions(timeout, labels, true)
+}
+
+func (e *element) selectOptions(timeout time.Duration, wanted []string, byLabel bool) error {
+	ctx, cancel, err := e.begin(timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if wanted == nil {
+		wanted = []string{}
+	}
+
+	var multiple bool
+	if err = e.callFunction(ctx, `function() { return !!this.multiple; }`, &multiple); err != nil {
+		log.Println("go-chrome-framework error: unable to select option", err.Error())
+		return err
+	}
+
+	if !multiple && len(wanted) > 1 {
+		return fmt.Errorf("go-chrome-framework: unable to select %v options of a single select", len(wanted))
+	}
+
+	var missing *[]string
+	if err = e.callFunction(ctx, selectScript, &missing, wanted, byLabel); err != nil {
+		log.Println("go-chrome-framework error: unable to select option", err.Error())
+		return err
+	}
+
+	if missing == nil {
+		return errors.New("go-chrome-framework: element is not a select element")
+	}
+
+	if len(*missing) > 0 {
+		return fmt.Errorf("%w: %v", ErrOptionNotFound, (*missing)[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,240 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/network"
+	"github.com/mafredri/cdp/protocol/serviceworker"
+	"github.com/mafredri/cdp/protocol/target"
+	"log"
+	"sort"
+	"time"
+)
+
+// serviceWorkerSettle is how long no service worker updates must arrive before the registrations are considered
+// complete, the browser reports the existing registrations right after the domain is enabled
+const serviceWorkerSettle = 200 * time.Millisecond
+
+// ServiceWorkerRegistration is a service worker registered for a scope
+type ServiceWorkerRegistration struct {
+	ID       string
+	ScopeURL string
+	Versions []ServiceWorkerVersion
+}
+
+// ServiceWorkerVersion is a version of the script of a service worker registration
+type ServiceWorkerVersion struct {
+	ID        string
+	ScriptURL string
+	// RunningStatus is one of "stopped", "starting", "running" or "stopping"
+	RunningStatus string
+	// Status is one of "new", "installing", "installed", "activating", "activated" or "redundant"
+	Status string
+}
+
+// ServiceWorkers returns the targets of the service workers running in the browser, across all browser contexts
+func (c *chrome) ServiceWorkers(timeout time.Duration) ([]target.Info, error) {
+	ctx, cancel := context.WithTimeout(c.lifetime(), timeout)
+	defer cancel()
+
+	targets, err := c.client.Target.GetTargets(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to get list of targets", err.Error())
+		return nil, err
+	}
+
+	var workers []target.Info
+	for _, targetInfo := range targets.TargetInfos {
+		if targetInfo.Type == "service_worker" {
+			workers = append(workers, targetInfo)
+		}
+	}
+
+	return workers, nil
+}
+
+// ServiceWorkerRegistrations returns the service workers registered in the browser context of the tab, ordered by
+// scope
+func (t *tab) ServiceWorkerRegistrations(timeout time.Duration) ([]ServiceWorkerRegistration, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return nil, err
+	}
+
+	client, err := t.currentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	registrationUpdated, err := client.ServiceWorker.WorkerRegistrationUpdated(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to open worker registration updated client", err.Error())
+		return nil, err
+	}
+	defer closeRes(registrationUpdated)
+
+	versionUpdated, err := client.ServiceWorker.WorkerVersionUpdated(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to open worker version updated client", err.Error())
+		return nil, err
+	}
+	defer closeRes(versionUpdated)
+
+	if err = client.ServiceWorker.Enable(ctx); err != nil {
+		log.Println("go-chrome-framework error: unable to enable service worker domain", err.Error())
+		return nil, err
+	}
+	// disabling lets the next call receive the registrations again
+	defer client.ServiceWorker.Disable(ctx)
+
+	registrations := make(map[serviceworker.RegistrationID]*ServiceWorkerRegistration)
+	versions := make(map[string]serviceworker.Version)
+	for {
+		select {
+		case <-registrationUpdated.Ready():
+			ev, err := registrationUpdated.Recv()
+			if err != nil {
+				log.Println("go-chrome-framework error: unable to get worker registration updated event", err.Error())
+				return nil, err
+			}
+			for _, registration := range ev.Registrations {
+				if registration.IsDeleted {
+					delete(registrations, registration.RegistrationID)
+					continue
+				}
+				registrations[registration.RegistrationID] = &ServiceWorkerRegistration{
+					ID:       string(registration.RegistrationID),
+					ScopeURL: registration.ScopeURL,
+				}
+			}
+			continue
+		case <-versionUpdated.Ready():
+			ev, err := versionUpdated.Recv()
+			if err != nil {
+				log.Println("go-chrome-framework error: unable to get worker version updated event", err.Error())
+				return nil, err
+			}
+			for _, version := range ev.Versions {
+				versions[version.VersionID] = version
+			}
+			continue
+		case <-ctx.Done():
+			log.Println("go-chrome-framework error: unable to list service worker registrations", ctx.Err().Error())
+			return nil, ctx.Err()
+		case <-time.After(serviceWorkerSettle):
+		}
+		break
+	}
+
+	for _, version := range versions {
+		if registration, ok := registrations[version.RegistrationID]; ok {
+			registration.Versions = append(registration.Versions, ServiceWorkerVersion{
+				ID:            version.VersionID,
+				ScriptURL:     version.ScriptURL,
+				RunningStatus: string(version.RunningStatus),
+				Status:        string(version.Status),
+			})
+		}
+	}
+
+	result := make([]ServiceWorkerRegistration, 0, len(registrations))
+	for _, registration := range registrations {
+		sort.Slice(registration.Versions, func(i, j int) bool {
+			return registration.Versions[i].ID < registration.Versions[j].ID
+		})
+		result = append(result, *registration)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ScopeURL < result[j].ScopeURL
+	})
+
+	return result, nil
+}
+
+// UnregisterServiceWorker unregisters the service worker registered for the scope
+func (t *tab) UnregisterServiceWorker(scopeURL string, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.ServiceWorker.Unregister(ctx, serviceworker.NewUnregisterArgs(scopeURL))
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to unregister service worker", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// SkipServiceWorkerWaiting activates the waiting version of the service worker registered for the scope
+func (t *tab) SkipServiceWorkerWaiting(scopeURL string, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.ServiceWorker.SkipWaiting(ctx, serviceworker.NewSkipWaitingArgs(scopeURL))
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to skip service worker waiting", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// StopAllServiceWorkers stops all the running service workers
+func (t *tab) StopAllServiceWorkers(timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.ServiceWorker.StopAllWorkers(ctx)
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to stop service workers", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// SetBypassServiceWorker makes the requests of the tab go to the network instead of being handled by service workers
+func (t *tab) SetBypassServiceWorker(bypass bool, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	bypassArgs := network.NewSetBypassServiceWorkerArgs(bypass)
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.Network.SetBypassServiceWorker(ctx, bypassArgs)
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to set bypass service worker", err.Error())
+		return err
+	}
+
+	return nil
+}
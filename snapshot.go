@@ -0,0 +1,136 @@
+package chrome
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// SnapshotDir is the directory the golden files of AssertHTMLSnapshot are kept in, relative to the working directory
+// of the test
+var SnapshotDir = filepath.Join("testdata", "snapshots")
+
+// UpdateSnapshotsEnv is the environment variable which, when set to a non-empty value, makes AssertHTMLSnapshot
+// overwrite the golden files with the current html instead of comparing
+const UpdateSnapshotsEnv = "GCF_UPDATE_SNAPSHOTS"
+
+// TestingT is the subset of testing.TB used by the assertions of a tab
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+	Logf(format string, args ...interface{})
+}
+
+// HTMLNormalizer rewrites the parts of an html snapshot which change between renderings of the same page
+type HTMLNormalizer func(html string) string
+
+var (
+	nonceAttribute = regexp.MustCompile(`(\snonce)="[^"]*"`)
+	isoTimestamp   = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:?\d{2})?`)
+	unixTimestamp  = regexp.MustCompile(`\b1\d{9}(\d{3})?\b`)
+	uuid           = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	hexID          = regexp.MustCompile(`(?i)\b[0-9a-f]*\d[0-9a-f]*[a-f][0-9a-f]*\b|\b[0-9a-f]*[a-f][0-9a-f]*\d[0-9a-f]*\b`)
+	trailingSpace  = regexp.MustCompile(`[ \t]+\n`)
+)
+
+// StripNonces empties the nonce attributes of scripts and styles
+func StripNonces(html string) string {
+	return nonceAttribute.ReplaceAllString(html, `$1=""`)
+}
+
+// StripTimestamps replaces ISO 8601 dates with times, and unix timestamps in seconds or milliseconds, with
+// [timestamp]
+func StripTimestamps(html string) string {
+	html = isoTimestamp.ReplaceAllString(html, "[timestamp]")
+	return unixTimestamp.ReplaceAllString(html, "[timestamp]")
+}
+
+// StripRandomIDs replaces uuids, and hexadecimal strings of at least 16 characters mixing digits and letters, with [id]
+func StripRandomIDs(html string) string {
+	html = uuid.ReplaceAllString(html, "[id]")
+	return hexID.ReplaceAllStringFunc(html, func(match string) string {
+		if len(match) < 16 {
+			return match
+		}
+
+		return "[id]"
+	})
+}
+
+// ReplaceHTML returns a normalizer replacing the matches of the regular expression with the replacement, which may
+// refer to submatches as in regexp.Regexp.ReplaceAllString
+func ReplaceHTML(expression, replacement string) HTMLNormalizer {
+	re := regexp.MustCompile(expression)
+
+	return func(html string) string {
+		return re.ReplaceAllString(html, replacement)
+	}
+}
+
+// DefaultHTMLNormalizers are applied by AssertHTMLSnapshot before the normalizers passed to it
+var DefaultHTMLNormalizers = []HTMLNormalizer{StripNonces, StripTimestamps, StripRandomIDs}
+
+// AssertHTMLSnapshot compares the normalized html of the document with the golden file name.html in SnapshotDir,
+// failing the test with the first differing line when they differ. The golden file is written instead when it does not
+// exist or UpdateSnapshotsEnv is set. The html is captured with the default timeout of the tab
+func (t *tab) AssertHTMLSnapshot(tt TestingT, name string, normalizers ...HTMLNormalizer) {
+	tt.Helper()
+
+	html, err := t.GetHTML(0)
+	if err != nil {
+		tt.Fatalf("unable to get html for snapshot %v: %v", name, err)
+		return
+	}
+
+	for _, normalize := range append(append([]HTMLNormalizer{}, DefaultHTMLNormalizers...), normalizers...) {
+		html = normalize(html)
+	}
+	html = strings.TrimSpace(trailingSpace.ReplaceAllString(html, "\n")) + "\n"
+
+	path := filepath.Join(SnapshotDir, name+".html")
+	golden, err := os.ReadFile(path)
+	if os.IsNotExist(err) || err == nil && os.Getenv(UpdateSnapshotsEnv) != "" {
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+			err = os.WriteFile(path, []byte(html), 0644)
+		}
+		if err != nil {
+			tt.Fatalf("unable to write snapshot %v: %v", path, err)
+			return
+		}
+
+		tt.Logf("wrote snapshot %v", path)
+		return
+	}
+	if err != nil {
+		tt.Fatalf("unable to read snapshot %v: %v", path, err)
+		return
+	}
+
+	if expected := string(golden); html != expected {
+		line, want, got := firstDifference(expected, html)
+		tt.Errorf("html does not match snapshot %v at line %v\nwant: %v\n got: %v\nset %v=1 to update the snapshot",
+			path, line, want, got, UpdateSnapshotsEnv)
+	}
+}
+
+// firstDifference returns the number and contents of the first line which differs
+func firstDifference(expected, actual string) (int, string, string) {
+	expectedLines := strings.Split(expected, "\n")
+	actualLines := strings.Split(actual, "\n")
+
+	for i := 0; ; i++ {
+		var want, got string
+		if i < len(expectedLines) {
+			want = expectedLines[i]
+		}
+		if i < len(actualLines) {
+			got = actualLines[i]
+		}
+
+		if want != got || i >= len(expectedLines) || i >= len(actualLines) {
+			return i + 1, want, got
+		}
+	}
+}
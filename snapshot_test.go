@@ -0,0 +1,60 @@
+package chrome
+
+import "testing"
+
+func TestHTMLNormalizers(t *testing.T) {
+	tests := []struct {
+		name      string
+		normalize HTMLNormalizer
+		html      string
+		want      string
+	}{
+		{"nonce", StripNonces, `<script nonce="r4nd0m">`, `<script nonce="">`},
+		{"nonce of a style", StripNonces, `<style nonce="abc" media="print">`, `<style nonce="" media="print">`},
+		{"data attribute ending in nonce", StripNonces, `<div data-nonce="abc">`, `<div data-nonce="abc">`},
+		{"iso timestamp", StripTimestamps, `<time>2020-01-02T03:04:05.678Z</time>`, `<time>[timestamp]</time>`},
+		{"iso timestamp with offset", StripTimestamps, `at 2020-01-02 03:04+05:30.`, `at [timestamp].`},
+		{"date without a time", StripTimestamps, `<time>2020-01-02</time>`, `<time>2020-01-02</time>`},
+		{"unix timestamp", StripTimestamps, `?t=1600000000`, `?t=[timestamp]`},
+		{"unix timestamp in milliseconds", StripTimestamps, `?t=1600000000123`, `?t=[timestamp]`},
+		{"short number", StripTimestamps, `<td>160000</td>`, `<td>160000</td>`},
+		{"uuid", StripRandomIDs, `id="123e4567-E89B-12d3-a456-426614174000"`, `id="[id]"`},
+		{"hexadecimal id", StripRandomIDs, `/static/main.0123456789abcdef.js`, `/static/main.[id].js`},
+		{"short hexadecimal id", StripRandomIDs, `class="a1b2c3"`, `class="a1b2c3"`},
+		{"hexadecimal letters only", StripRandomIDs, `deadbeefdeadbeefdead`, `deadbeefdeadbeefdead`},
+		{"digits only", StripRandomIDs, `12345678901234567890`, `12345678901234567890`},
+		{"replace", ReplaceHTML(`data-v-[0-9a-f]+`, "data-v"), `<p data-v-1a2b3c>`, `<p data-v>`},
+		{"replace with submatch", ReplaceHTML(`(csrf" value=")[^"]+`, "${1}token"), `csrf" value="x1y2"`,
+			`csrf" value="token"`},
+	}
+
+	for _, test := range tests {
+		if html := test.normalize(test.html); html != test.want {
+			t.Errorf("%v: normalized %q to %q, want %q", test.name, test.html, html, test.want)
+		}
+	}
+}
+
+func TestFirstDifference(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		line     int
+		want     string
+		got      string
+	}{
+		{"changed line", "a\nb\nc\n", "a\nx\nc\n", 2, "b", "x"},
+		{"missing line", "a\nb\n", "a\n", 2, "b", ""},
+		{"extra line", "a\n", "a\nb\n", 2, "", "b"},
+		{"first line", "a", "b", 1, "a", "b"},
+	}
+
+	for _, test := range tests {
+		line, want, got := firstDifference(test.expected, test.actual)
+		if line != test.line || want != test.want || got != test.got {
+			t.Errorf("%v: firstDifference() = %v, %q, %q, want %v, %q, %q", test.name, line, want, got, test.line,
+				test.want, test.got)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/domstorage"
+	"github.com/mafredri/cdp/protocol/storage"
+	"log"
+	"strings"
+	"time"
+)
+
+// StorageType is a type of data stored for an origin, see ClearSiteData
+type StorageType string
+
+const (
+	StorageAll            StorageType = "all"
+	StorageCookies        StorageType = "cookies"
+	StorageFileSystems    StorageType = "file_systems"
+	StorageIndexedDB      StorageType = "indexeddb"
+	StorageLocalStorage   StorageType = "local_storage"
+	StorageWebSQL         StorageType = "websql"
+	StorageServiceWorkers StorageType = "service_workers"
+	StorageCacheStorage   StorageType = "cache_storage"
+)
+
+// WebStorage reads and writes the local or session storage of an origin, e.g. "https://example.com". The origin must
+// have been loaded in the tab for its storage to be available
+type WebStorage interface {
+	Items(origin string, timeout time.Duration) (map[string]string, error)
+	// Get returns the value of the key and whether the key exists
+	Get(origin, key string, timeout time.Duration) (string, bool, error)
+	Set(origin, key, value string, timeout time.Duration) error
+	Remove(origin, key string, timeout time.Duration) error
+	Clear(origin string, timeout time.Duration) error
+}
+
+type webStorage struct {
+	tab   *tab
+	local bool
+}
+
+// LocalStorage returns the local storage of the origins loaded in the tab
+func (t *tab) LocalStorage() WebStorage {
+	return &webStorage{tab: t, local: true}
+}
+
+// SessionStorage returns the session storage of the origins loaded in the tab
+func (t *tab) SessionStorage() WebStorage {
+	return &webStorage{tab: t}
+}
+
+// begin resolves the timeout and connects the tab
+func (s *webStorage) begin(timeout time.Duration) (context.Context, context.CancelFunc, error) {
+	timeout = s.tab.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(s.tab.lifetime(), timeout)
+
+	if err := s.tab.ensureConnected(timeout); err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	return ctx, cancel, nil
+}
+
+// call calls fn with the client of the tab once the DOMStorage domain is enabled on its connection
+func (s *webStorage) call(ctx context.Context, fn func(client *cdp.Client) error) error {
+	return s.tab.call(ctx, func(client *cdp.Client) error {
+		if err := client.DOMStorage.Enable(ctx); err != nil {
+			return err
+		}
+
+		return fn(client)
+	})
+}
+
+func (s *webStorage) storageID(origin string) domstorage.StorageID {
+	return domstorage.StorageID{SecurityOrigin: origin, IsLocalStorage: s.local}
+}
+
+func (s *webStorage) Items(origin string, timeout time.Duration) (map[string]string, error) {
+	ctx, cancel, err := s.begin(timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	var reply *domstorage.GetDOMStorageItemsReply
+	err = s.call(ctx, func(client *cdp.Client) (err error) {
+		reply, err = client.DOMStorage.GetDOMStorageItems(ctx, domstorage.NewGetDOMStorageItemsArgs(s.storageID(origin)))
+		return err
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to get storage items", err.Error())
+		return nil, err
+	}
+
+	// every entry is a key and value pair
+	items := make(map[string]string, len(reply.Entries))
+	for _, entry := range reply.Entries {
+		if len(entry) == 2 {
+			items[entry[0]] = entry[1]
+		}
+	}
+
+	return items, nil
+}
+
+func (s *webStorage) Get(origin, key string, timeout time.Duration) (string, bool, error) {
+	items, err := s.Items(origin, timeout)
+	if err != nil {
+		return "", false, err
+	}
+
+	value, ok := items[key]
+	return value, ok, nil
+}
+
+func (s *webStorage) Set(origin, key, value string, timeout time.Duration) error {
+	ctx, cancel, err := s.begin(timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	setArgs := domstorage.NewSetDOMStorageItemArgs(s.storageID(origin), key, value)
+	err = s.call(ctx, func(client *cdp.Client) error {
+		return client.DOMStorage.SetDOMStorageItem(ctx, setArgs)
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to set storage item", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *webStorage) Remove(origin, key string, timeout time.Duration) error {
+	ctx, cancel, err := s.begin(timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	removeArgs := domstorage.NewRemoveDOMStorageItemArgs(s.storageID(origin), key)
+	err = s.call(ctx, func(client *cdp.Client) error {
+		return client.DOMStorage.RemoveDOMStorageItem(ctx, removeArgs)
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to remove storage item", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *webStorage) Clear(origin string, timeout time.Duration) error {
+	ctx, cancel, err := s.begin(timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	err = s.call(ctx, func(client *cdp.Client) error {
+		return client.DOMStorage.Clear(ctx, domstorage.NewClearArgs(s.storageID(origin)))
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to clear storage", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// ClearSiteData clears the data of the given types stored for the origin, e.g. "https://example.com". All types of data
+// are cleared when no types are given
+func (t *tab) ClearSiteData(origin string, types []StorageType, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if len(types) == 0 {
+		types = []StorageType{StorageAll}
+	}
+
+	names := make([]string, len(types))
+	for i, storageType := range types {
+		names[i] = string(storageType)
+	}
+
+	clearArgs := storage.NewClearDataForOriginArgs(origin, strings.Join(names, ","))
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.Storage.ClearDataForOrigin(ctx, clearArgs)
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to clear site data", err.Error())
+		return err
+	}
+
+	return nil
+}
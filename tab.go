@@ -3,28 +3,152 @@ package chrome
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/browser"
 	"github.com/mafredri/cdp/protocol/dom"
 	"github.com/mafredri/cdp/protocol/emulation"
+	"github.com/mafredri/cdp/protocol/network"
 	"github.com/mafredri/cdp/protocol/page"
 	"github.com/mafredri/cdp/protocol/runtime"
 	"github.com/mafredri/cdp/protocol/target"
 	"github.com/mafredri/cdp/rpcc"
+	"go.ajitem.com/gcf/v3/visualdiff"
+	"go.opentelemetry.io/otel/attribute"
+	"io"
 	"log"
+	"regexp"
+	"sync"
 	"time"
 )
 
+// Tab controls a single page of the browser. Methods accepting a timeout fall back to the default timeout of the tab
+// when the timeout is zero, see SetDefaultTimeout and SetDefaultNavigationTimeout.
+//
+// A Tab is safe for concurrent use by multiple goroutines. The connection is established once even when several
+// goroutines race to use a new tab, and hooks are executed one at a time while connecting, hence hooks must not call
+// methods of the tab. Starting and stopping tracing, coverage, screencasts and routes is serialized, other calls are
+// sent to the browser concurrently and are subject to the ordering the browser applies to them
 type Tab interface {
-	Navigate(url string, timeout time.Duration) (bool, error)
+	Navigate(url string, timeout time.Duration) (*NavigationResult, error)
+	NavigateWithOpts(url string, opts *NavigateOpts, timeout time.Duration) (*NavigationResult, error)
+	WaitForURL(pattern string, timeout time.Duration) (string, error)
+	WaitForURLMatch(re *regexp.Regexp, timeout time.Duration) (string, error)
 	GetHTML(timeout time.Duration) (string, error)
 	CaptureScreenshot(opts ScreenshotOpts, timeout time.Duration) (string, error)
+	CaptureScreenshotTo(w io.Writer, opts ScreenshotOpts, timeout time.Duration) error
+	CaptureScreenshotFile(path string, opts ScreenshotOpts, timeout time.Duration) error
+	CompareScreenshot(baselinePath string, opts visualdiff.Opts, timeout time.Duration) (*visualdiff.Result, error)
+	AssertHTMLSnapshot(t TestingT, name string, normalizers ...HTMLNormalizer)
+	Actions() *Actions
+	ScreenshotElement(selector string, opts ElementScreenshotOpts, timeout time.Duration) ([]byte, error)
+	ScrollTo(x, y float64, timeout time.Duration) error
+	ScrollIntoView(selector string, timeout time.Duration) error
+	ScrollToBottom(step float64, delay time.Duration, timeout time.Duration) error
+	AutoScroll(opts AutoScrollOpts, timeout time.Duration) error
+	FillForm(fields map[string]string, timeout time.Duration) error
+	Submit(selector string, timeout time.Duration) error
+	GetText(selector string, timeout time.Duration) (string, error)
+	GetAttribute(selector, name string, timeout time.Duration) (string, error)
+	GetInnerHTML(selector string, timeout time.Duration) (string, error)
+	Count(selector string, timeout time.Duration) (int, error)
+	QueryXPath(expression string, timeout time.Duration) ([]dom.NodeID, error)
+	WaitForXPath(expression string, timeout time.Duration) ([]dom.NodeID, error)
+	ExtractTable(selector string, timeout time.Duration) ([][]string, error)
+	ExtractTableTo(selector string, out interface{}, opts TableOpts, timeout time.Duration) error
+	ExtractLinks(opts LinkOpts, timeout time.Duration) ([]Link, error)
+	SetContent(html string, timeout time.Duration) error
+	WaitForFonts(timeout time.Duration) error
+	InjectFonts(fonts []FontFace, timeout time.Duration) error
+	WaitForImages(timeout time.Duration) ([]FailedImage, error)
+	FreezeAnimations(timeout time.Duration) error
+	SetAnimationPlaybackRate(rate float64, timeout time.Duration) error
+	PrintToPDF(opts PDFOpts, timeout time.Duration) ([]byte, error)
+	LocalStorage() WebStorage
+	SessionStorage() WebStorage
+	ClearSiteData(origin string, types []StorageType, timeout time.Duration) error
+	ServiceWorkerRegistrations(timeout time.Duration) ([]ServiceWorkerRegistration, error)
+	UnregisterServiceWorker(scopeURL string, timeout time.Duration) error
+	SkipServiceWorkerWaiting(scopeURL string, timeout time.Duration) error
+	StopAllServiceWorkers(timeout time.Duration) error
+	SetBypassServiceWorker(bypass bool, timeout time.Duration) error
+	SetCacheDisabled(disabled bool, timeout time.Duration) error
+	SetBypassCSP(bypass bool, timeout time.Duration) error
+	ClearBrowserCache(timeout time.Duration) error
+	SecurityState(timeout time.Duration) (*SecurityState, error)
+	SetIgnoreCertificateErrors(ignore bool, timeout time.Duration) error
+	OnCertificateError(handler CertificateErrorHandler, timeout time.Duration) error
+	DragAndDrop(sourceSelector, targetSelector string, opts DragAndDropOpts, timeout time.Duration) error
+	Element(selector string, timeout time.Duration) (Element, error)
+	SetClipboard(text string, timeout time.Duration) error
+	ReadClipboard(timeout time.Duration) (string, error)
+	Close(ctx context.Context) error
+	Detach(ctx context.Context) error
+	BringToFront(timeout time.Duration) error
+	SetWindowBounds(bounds WindowBounds, timeout time.Duration) error
+	Minimize(timeout time.Duration) error
+	Maximize(timeout time.Duration) error
+	Fullscreen(timeout time.Duration) error
+	RestoreWindow(timeout time.Duration) error
+	SetViewport(width, height int, deviceScaleFactor float64, mobile bool, timeout time.Duration) error
+	ResetViewport(timeout time.Duration) error
+	EmulateVisionDeficiency(deficiency VisionDeficiency, timeout time.Duration) error
+	EmulateMediaFeatures(features map[string]string, timeout time.Duration) error
+	SetFocusEmulationEnabled(enabled bool, timeout time.Duration) error
+	SetIdleOverride(userActive, screenUnlocked bool, timeout time.Duration) error
+	ClearIdleOverride(timeout time.Duration) error
+	SendCommand(method string, params interface{}, result interface{}, timeout time.Duration) error
+	OnWebSocketFrame(handler WebSocketFrameHandler, timeout time.Duration) error
+	OnEventSourceMessage(handler EventSourceMessageHandler, timeout time.Duration) error
+	OnRequestFailed(handler RequestFailedHandler, timeout time.Duration) error
+	OnRouteChange(handler RouteChangeHandler, timeout time.Duration) error
+	SetFilterList(list *FilterList, timeout time.Duration) error
+	EnableNetworkStats(timeout time.Duration) error
+	NetworkStats() NetworkStats
+	EnableDebugLog(timeout time.Duration) error
+	DebugDump(dir string, timeout time.Duration) error
+	SetTraceContext(ctx context.Context)
 	Exec(javascript string, timeout time.Duration) (*runtime.EvaluateReply, error)
+	Evaluate(expression string, result interface{}, timeout time.Duration) error
 	GetClient() *cdp.Client
 	GetTargetID() target.ID
 	AttachHook(hook ClientHook)
+	GrantPermissions(origin string, timeout time.Duration, permissions ...browser.PermissionType) error
+	StartTracing(w io.Writer, categories []string, timeout time.Duration) error
+	StopTracing(timeout time.Duration) error
+	StartJSCoverage(timeout time.Duration) error
+	StopJSCoverage(timeout time.Duration) ([]Coverage, error)
+	StartCSSCoverage(timeout time.Duration) error
+	StopCSSCoverage(timeout time.Duration) ([]Coverage, error)
+	Metrics(timeout time.Duration) (*PerformanceMetrics, error)
+	AccessibilitySnapshot(opts AccessibilitySnapshotOpts, timeout time.Duration) (*AXNode, error)
+	DOMSnapshot(opts DOMSnapshotOpts, timeout time.Duration) (*DOMSnapshot, error)
+	CaptureMHTML(timeout time.Duration) (string, error)
+	StartScreencast(opts ScreencastOpts, timeout time.Duration) error
+	StopScreencast(timeout time.Duration) error
+	OnConnectionStateChanged(handler ConnectionStateHandler)
+	Route(pattern string, handler RouteHandler, timeout time.Duration) error
+	RouteResponse(pattern string, handler RouteHandler, timeout time.Duration) error
+	RewriteDocument(timeout time.Duration, rewriters ...DocumentRewriter) error
+	Unroute(pattern string, timeout time.Duration) error
+	SetDefaultTimeout(timeout time.Duration)
+	SetDefaultNavigationTimeout(timeout time.Duration)
+	OnClose(handler TabCloseHandler)
+	Closed() <-chan struct{}
 }
 
+const (
+	// DefaultTimeout is the default timeout of the tab methods
+	DefaultTimeout = 30 * time.Second
+	// DefaultNavigationTimeout is the default timeout of navigations
+	DefaultNavigationTimeout = 30 * time.Second
+)
+
+// ErrNotConnected is returned by the calls made while the tab is not connected, e.g. after reconnecting to it failed
+var ErrNotConnected = errors.New("go-chrome-framework: tab not connected")
+
 type ClientHook func(c *cdp.Client) error
 
 type ClientHooks []ClientHook
@@ -32,212 +156,570 @@ type ClientHooks []ClientHook
 type tab struct {
 	// target id of a single tab
 	id target.ID
+	// id of the browser context the tab belongs to, empty for the default browser context
+	browserContextID browser.ContextID
 	// port on which chrome process is listening for dev tools protocol
 	port *int
+	// browser the tab belongs to
+	browser *chrome
+	// guards conn, client, hooks, connectionStateHandlers, closing, viewport, the trace context, the last navigation
+	// and the default timeouts
+	mu sync.Mutex
+	// guards the state of tracing, coverage, screencast, interception, certificate error handling, network event
+	// handlers and the debug log
+	featureMu sync.Mutex
 	// connection to connect with the browser
 	conn *rpcc.Conn
 	// client to control the browser
 	client *cdp.Client
 	// hooks to attach additional functionality to client, enable domains etc
 	hooks ClientHooks
+	// tracer collecting trace events while tracing is in progress
+	tracer *tracer
+	// whether js coverage is being collected
+	jsCoverage bool
+	// css coverage being collected
+	cssCoverage *cssCoverage
+	// screencast being recorded
+	screencast *screencast
+	// handlers invoked when the connection drops and is re-established
+	connectionStateHandlers []ConnectionStateHandler
+	// interceptor pausing requests matching the registered routes
+	interceptor *interceptor
+	// stops handling certificate errors
+	certificateErrors context.CancelFunc
+	// viewport set by SetViewport, restored after screenshots resize the viewport
+	viewport *emulation.SetDeviceMetricsOverrideArgs
+	// dispatches websocket frames to the handlers registered with OnWebSocketFrame
+	webSockets *webSockets
+	// dispatches server-sent events to the handlers registered with OnEventSourceMessage
+	eventSources *eventSources
+	// dispatches failed requests to the handlers registered with OnRequestFailed
+	requestFailures *requestFailures
+	// dispatches route changes to the handlers registered with OnRouteChange
+	routeChanges *routeChanges
+	// network traffic accounted for since EnableNetworkStats
+	networkStats *networkStats
+	// console messages and failed requests retained since EnableDebugLog
+	debugLog *debugLog
+	// result of the last navigation, included in debug dumps
+	lastNavigation *NavigationResult
+	// parent of the contexts of the calls to the tab, canceled once the tab or its browser is closed
+	ctx context.Context
+	// cancels ctx
+	cancel context.CancelFunc
+	// parent of the spans of the tab, see SetTraceContext
+	traceContext context.Context
+	// timeout used when methods are passed a zero timeout, DefaultTimeout if unset
+	defaultTimeout time.Duration
+	// timeout used when navigations are passed a zero timeout, DefaultNavigationTimeout if unset
+	defaultNavigationTimeout time.Duration
+	// guards closed, isClosed and closeHandlers
+	lifecycleMu sync.Mutex
+	// closed once the page of the tab is closed
+	closed chan struct{}
+	// whether the page of the tab is closed
+	isClosed bool
+	// handlers invoked once the page of the tab is closed
+	closeHandlers []TabCloseHandler
+	// set when the connection is being closed deliberately so that it is not re-established
+	closing bool
 }
 
+// connect connects to the target and executes the hooks on the new connection. The connection is only retained if
+// all the hooks succeed. Must be called with mu held
 func (t *tab) connect(timeout time.Duration) error {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
 	defer cancel()
 
+	var conn *rpcc.Conn
 	var err error
-	// connect to chrome
-	t.conn, err = rpcc.DialContext(
-		ctx,
-		fmt.Sprintf("ws://127.0.0.1:%v/devtools/page/%v", IntValue(t.port), t.id),
-	)
+	if t.browser != nil && t.browser.sessions != nil {
+		// connect to the target through a session over the shared browser connection
+		conn, err = t.browser.sessions.dial(ctx, t.id)
+	} else {
+		// connect to chrome
+		conn, err = rpcc.DialContext(
+			ctx,
+			fmt.Sprintf("ws://127.0.0.1:%v/devtools/page/%v", IntValue(t.port), t.id),
+		)
+	}
 	if err != nil {
 		log.Println("go-chrome-framework error: unable to connect to target", err.Error())
 		return err
 	}
 
 	// This cdp Client controls the tab.
-	t.client = cdp.NewClient(t.conn)
+	client := cdp.NewClient(conn)
 
-	// execute hooks for current target
+	// execute hooks for current target, one at a time
 	for _, hook := range t.hooks {
-		err := hook(t.client)
+		err := hook(client)
 		if err != nil {
 			log.Println("go-chrome-framework error: unable to execute hook", err.Error())
+			closeRes(conn)
 			return err
 		}
 	}
 
+	// learn about the page being closed as soon as it happens
+	if err = t.watchDetached(client); err != nil {
+		log.Println("go-chrome-framework error: unable to watch for tab being detached", err.Error())
+		closeRes(conn)
+		return err
+	}
+
+	// navigations and the network features rely on the network events, which are enabled once per connection
+	if err = client.Network.Enable(ctx, network.NewEnableArgs()); err != nil {
+		log.Println("go-chrome-framework error: unable to enable network domain", err.Error())
+		closeRes(conn)
+		return err
+	}
+
+	t.conn = conn
+	t.client = client
+
+	// re-establish the connection if it drops
+	go t.watch(conn)
+
 	return nil
 }
 
+// ensureConnected connects to the target unless connected already
+func (t *tab) ensureConnected(timeout time.Duration) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.conn != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	// retry transient failures within the timeout of the call, e.g. the browser connection being re-established
+	return t.browser.retryOpts().run(ctx, t.retryable, func(ctx context.Context) error {
+		return t.connect(contextTimeout(ctx))
+	})
+}
+
+// retryable reports whether connecting to the tab or a call to it is retried after failing with err
+func (t *tab) retryable(err error) bool {
+	return isTransient(err) && !t.closedState()
+}
+
+// currentClient returns the client of the current connection, which changes when a dropped connection is
+// re-established. ErrNotConnected is returned once reconnecting gave up, until the next call connects again
+func (t *tab) currentClient() (*cdp.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.client == nil {
+		return nil, ErrNotConnected
+	}
+
+	return t.client, nil
+}
+
 func (t *tab) disconnect() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closing = true
+	if t.conn == nil {
+		return nil
+	}
+
 	return t.conn.Close()
 }
 
-func (t *tab) Navigate(url string, timeout time.Duration) (bool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+// Navigate navigates the tab to the url and waits for the DOMContentLoaded event of the document. The result describes
+// the response of the main frame document, including the redirects which were followed to reach it
+func (t *tab) Navigate(url string, timeout time.Duration) (*NavigationResult, error) {
+	return t.NavigateWithOpts(url, nil, timeout)
+}
+
+// NavigateWithOpts navigates like Navigate with the given options, opts may be nil. When the status of the document
+// fails the FailOnStatus option, the result is returned along with an error wrapping ErrBadStatus
+func (t *tab) NavigateWithOpts(url string, opts *NavigateOpts, timeout time.Duration) (*NavigationResult, error) {
+	start := time.Now()
+	span := t.startSpan("tab.Navigate", attribute.String("http.url", url))
+
+	result, err := t.navigate(url, opts, timeout)
+	t.metrics().ObserveNavigation(time.Since(start), err)
+	if result != nil {
+		span.SetAttributes(attribute.Int("http.status_code", result.StatusCode))
+
+		t.mu.Lock()
+		t.lastNavigation = result
+		t.mu.Unlock()
+	}
+	endSpan(span, err)
+
+	return result, err
+}
+
+func (t *tab) navigate(url string, opts *NavigateOpts, timeout time.Duration) (*NavigationResult, error) {
+	timeout = t.resolveNavigationTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
 	defer cancel()
 
-	if t.conn == nil {
-		err := t.connect(timeout)
-		if err != nil {
-			return false, err
-		}
+	if err := t.ensureConnected(timeout); err != nil {
+		return nil, err
+	}
+
+	client, err := t.currentClient()
+	if err != nil {
+		return nil, err
 	}
 
 	// Open a DOMContentEventFired Client to buffer this event.
-	domContent, err := t.client.Page.DOMContentEventFired(ctx)
+	domContent, err := client.Page.DOMContentEventFired(ctx)
 	if err != nil {
 		log.Println("go-chrome-framework error: unable to open dom content event fired client", err.Error())
-		return false, err
+		return nil, err
 	}
 	defer closeRes(domContent)
 
+	// frames other than the main frame are awaited until they stopped loading
+	frameStoppedLoading, err := client.Page.FrameStoppedLoading(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to open frame stopped loading client", err.Error())
+		return nil, err
+	}
+	defer closeRes(frameStoppedLoading)
+
+	// The requests and responses of the document describe the redirects and the final response
+	requestWillBeSent, err := client.Network.RequestWillBeSent(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to open request will be sent client", err.Error())
+		return nil, err
+	}
+	defer closeRes(requestWillBeSent)
+
+	responseReceived, err := client.Network.ResponseReceived(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to open response received client", err.Error())
+		return nil, err
+	}
+	defer closeRes(responseReceived)
+
 	// Enable events on the Page domain, it's often preferable to create
 	// event clients before enabling events so that we don't miss any.
-	if err = t.client.Page.Enable(ctx); err != nil {
+	if err = client.Page.Enable(ctx); err != nil {
 		log.Println("go-chrome-framework error: unable to enable page domain", err.Error())
-		return false, err
+		return nil, err
+	}
+
+	if opts != nil && opts.cacheDisabled != nil {
+		if err = t.setCacheDisabled(ctx, *opts.cacheDisabled); err != nil {
+			log.Println("go-chrome-framework error: unable to set cache disabled", err.Error())
+			return nil, err
+		}
+	}
+
+	if opts != nil && opts.clearCache {
+		if err = client.Network.ClearBrowserCache(ctx); err != nil {
+			log.Println("go-chrome-framework error: unable to clear browser cache", err.Error())
+			return nil, err
+		}
+	}
+
+	// fast-forward the timers of the page from the moment the document is committed
+	var budgetExpired emulation.VirtualTimeBudgetExpiredClient
+	if opts != nil && opts.virtualTimeBudget > 0 {
+		budgetExpired, err = client.Emulation.VirtualTimeBudgetExpired(ctx)
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to open virtual time budget expired client", err.Error())
+			return nil, err
+		}
+		defer closeRes(budgetExpired)
+
+		policyArgs := emulation.NewSetVirtualTimePolicyArgs(emulation.VirtualTimePolicyPauseIfNetworkFetchesPending).
+			SetBudget(float64(opts.virtualTimeBudget.Milliseconds())).
+			SetWaitForNavigation(true)
+		if _, err = client.Emulation.SetVirtualTimePolicy(ctx, policyArgs); err != nil {
+			log.Println("go-chrome-framework error: unable to set virtual time policy", err.Error())
+			return nil, err
+		}
 	}
 
 	// Create the Navigate arguments with the optional Referrer field set.
 	navArgs := page.NewNavigateArgs(url)
-	nav, err := t.client.Page.Navigate(ctx, navArgs)
+	if opts != nil && opts.referrer != "" {
+		navArgs.SetReferrer(opts.referrer)
+	}
+	if opts != nil && opts.transitionType != "" {
+		navArgs.SetTransitionType(opts.transitionType)
+	}
+	if opts != nil && opts.frameID != "" {
+		navArgs.SetFrameID(opts.frameID)
+	}
+	nav, err := client.Page.Navigate(ctx, navArgs)
 	if err != nil {
 		log.Println("go-chrome-framework error: unable to navigate to given url", err.Error())
-		return false, err
+		return nil, err
 	}
 
-	// Wait until we have a DOMContentEventFired event.
-	_, err = domContent.Recv()
-	if err != nil {
-		log.Println("go-chrome-framework error: unable to get dom content event", err.Error())
-		return false, err
+	if nav.ErrorText != nil {
+		err = fmt.Errorf("go-chrome-framework: navigation to %v failed: %v", url, *nav.ErrorText)
+		log.Println("go-chrome-framework error: unable to navigate to given url", err.Error())
+		return nil, err
+	}
+
+	if opts != nil && opts.frameID != "" {
+		// Wait until the frame stopped loading, DOMContentEventFired is only fired for the main frame
+		for {
+			ev, err := frameStoppedLoading.Recv()
+			if err != nil {
+				log.Println("go-chrome-framework error: unable to get frame stopped loading event", err.Error())
+				return nil, err
+			}
+			if ev.FrameID == opts.frameID {
+				break
+			}
+		}
+	} else {
+		// Wait until we have a DOMContentEventFired event.
+		_, err = domContent.Recv()
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to get dom content event", err.Error())
+			return nil, err
+		}
+	}
+
+	if budgetExpired != nil {
+		if _, err = budgetExpired.Recv(); err != nil {
+			log.Println("go-chrome-framework error: unable to get virtual time budget expired event", err.Error())
+			return nil, err
+		}
+
+		// virtual time is paused once the budget is used up, let time pass in real time again
+		policyArgs := emulation.NewSetVirtualTimePolicyArgs(emulation.VirtualTimePolicyAdvance)
+		if _, err = client.Emulation.SetVirtualTimePolicy(ctx, policyArgs); err != nil {
+			log.Println("go-chrome-framework error: unable to set virtual time policy", err.Error())
+			return nil, err
+		}
 	}
 
 	log.Printf("go-chrome-framework: page loaded with frame ID: %s\n", nav.FrameID)
 
-	return true, nil
+	result := &NavigationResult{URL: url, FrameID: nav.FrameID}
+	if nav.LoaderID != nil {
+		result.LoaderID = *nav.LoaderID
+	}
+
+	// The events of the document precede DOMContentLoaded, hence they are buffered by now. The request id of the main
+	// document request is its loader id
+	for ready := true; ready; {
+		select {
+		case <-requestWillBeSent.Ready():
+			ev, err := requestWillBeSent.Recv()
+			if err != nil {
+				log.Println("go-chrome-framework error: unable to get request will be sent event", err.Error())
+				return nil, err
+			}
+			if string(ev.RequestID) == string(result.LoaderID) {
+				result.recordRequest(ev)
+			}
+		case <-responseReceived.Ready():
+			ev, err := responseReceived.Recv()
+			if err != nil {
+				log.Println("go-chrome-framework error: unable to get response received event", err.Error())
+				return nil, err
+			}
+			if string(ev.RequestID) == string(result.LoaderID) {
+				result.recordResponse(ev)
+			}
+		default:
+			ready = false
+		}
+	}
+
+	if opts != nil && opts.failOnStatus > 0 && result.StatusCode >= opts.failOnStatus {
+		err = fmt.Errorf("%w: %v %v for %v", ErrBadStatus, result.StatusCode, result.StatusText, result.URL)
+		log.Println("go-chrome-framework error: navigation responded with error status", err.Error())
+		return result, err
+	}
+
+	return result, nil
 }
 
 func (t *tab) GetHTML(timeout time.Duration) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
 	defer cancel()
 
-	if t.conn == nil {
-		err := t.connect(timeout)
+	if err := t.ensureConnected(timeout); err != nil {
+		return "", err
+	}
+
+	var html string
+	err := t.call(ctx, func(client *cdp.Client) error {
+		// Fetch the document root node. We can pass nil here
+		// since this method only takes optional arguments.
+		doc, err := client.DOM.GetDocument(ctx, nil)
 		if err != nil {
-			return "", err
+			log.Println("go-chrome-framework error: unable to get DOM root node", err.Error())
+			return err
 		}
-	}
 
-	// Fetch the document root node. We can pass nil here
-	// since this method only takes optional arguments.
-	doc, err := t.client.DOM.GetDocument(ctx, nil)
-	if err != nil {
-		log.Println("go-chrome-framework error: unable to get DOM root node", err.Error())
-		return "", err
-	}
+		// Get the outer HTML for the page.
+		result, err := client.DOM.GetOuterHTML(ctx, &dom.GetOuterHTMLArgs{
+			NodeID: &doc.Root.NodeID,
+		})
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to get outer html", err.Error())
+			return err
+		}
+		html = result.OuterHTML
 
-	// Get the outer HTML for the page.
-	result, err := t.client.DOM.GetOuterHTML(ctx, &dom.GetOuterHTMLArgs{
-		NodeID: &doc.Root.NodeID,
+		return nil
 	})
 	if err != nil {
-		log.Println("go-chrome-framework error: unable to get outer html", err.Error())
 		return "", err
 	}
 
-	return result.OuterHTML, nil
+	return html, nil
 }
 
 func (t *tab) CaptureScreenshot(opts ScreenshotOpts, timeout time.Duration) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	span := t.startSpan("tab.CaptureScreenshot")
+	image, err := t.captureScreenshotDataURL(opts, timeout)
+	endSpan(span, err)
+
+	return image, err
+}
+
+func (t *tab) captureScreenshotDataURL(opts ScreenshotOpts, timeout time.Duration) (string, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
 	defer cancel()
 
-	if t.conn == nil {
-		err := t.connect(timeout)
-		if err != nil {
-			return "", err
-		}
+	if err := t.ensureConnected(timeout); err != nil {
+		return "", err
 	}
 
-	// Fetch the document root node. We can pass nil here
-	// since this method only takes optional arguments.
-	doc, err := t.client.DOM.GetDocument(ctx, nil)
+	data, err := t.captureScreenshot(ctx, opts)
 	if err != nil {
-		log.Println("go-chrome-framework error: unable to get DOM root node", err.Error())
 		return "", err
 	}
 
-	querySelectorArgs := dom.NewQuerySelectorArgs(doc.Root.NodeID, "body")
-	bodyNode, err := t.client.DOM.QuerySelector(ctx, querySelectorArgs)
-	if err != nil {
-		log.Println("go-chrome-framework error: unable to get DOM root node", err.Error())
+	image := fmt.Sprintf("data:image/%v;base64,%v", screenshotFormat(opts), base64.StdEncoding.EncodeToString(data))
+
+	return image, nil
+}
+
+func (t *tab) CaptureMHTML(timeout time.Duration) (string, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
 		return "", err
 	}
 
-	getBoxModelArgs := dom.NewGetBoxModelArgs().SetNodeID(bodyNode.NodeID)
-	bodyBoxModel, err := t.client.DOM.GetBoxModel(ctx, getBoxModelArgs)
+	// capture the page along with its resources as a single mhtml archive
+	var snapshot *page.CaptureSnapshotReply
+	err := t.call(ctx, func(client *cdp.Client) (err error) {
+		snapshot, err = client.Page.CaptureSnapshot(ctx, page.NewCaptureSnapshotArgs().SetFormat("mhtml"))
+		return err
+	})
 	if err != nil {
-		log.Println("go-chrome-framework error: unable to get DOM root node", err.Error())
+		log.Println("go-chrome-framework error: unable to capture mhtml snapshot", err.Error())
 		return "", err
 	}
 
-	if opts.Width == 0 {
-		opts.Width = 800
-	}
+	return snapshot.Data, nil
+}
 
-	if opts.Height == 0 {
-		opts.Height = bodyBoxModel.Model.Height
-	}
+func (t *tab) Exec(javascript string, timeout time.Duration) (*runtime.EvaluateReply, error) {
+	span := t.startSpan("tab.Exec")
+	reply, err := t.exec(javascript, timeout)
+	endSpan(span, err)
+
+	return reply, err
+}
 
-	if opts.DeviceScaleFactor == 0 {
-		opts.DeviceScaleFactor = 1.0
+func (t *tab) exec(javascript string, timeout time.Duration) (*runtime.EvaluateReply, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return nil, err
 	}
 
-	deviceMetricsOverrideArgs := emulation.NewSetDeviceMetricsOverrideArgs(opts.Width, opts.Height, opts.DeviceScaleFactor, opts.Mobile)
-	err = t.client.Emulation.SetDeviceMetricsOverride(ctx, deviceMetricsOverrideArgs)
+	evalArgs := runtime.NewEvaluateArgs(javascript).SetAwaitPromise(true).SetReturnByValue(true)
+
+	var reply *runtime.EvaluateReply
+	err := t.call(ctx, func(client *cdp.Client) (err error) {
+		reply, err = client.Runtime.Evaluate(ctx, evalArgs)
+		return err
+	})
+
+	return reply, err
+}
+
+// evaluate evaluates the javascript expression, awaiting the result if it is a promise, and unmarshals the result into
+// value unless value is nil
+func (t *tab) evaluate(ctx context.Context, expression string, value interface{}) error {
+	evalArgs := runtime.NewEvaluateArgs(expression).SetAwaitPromise(true).SetReturnByValue(true)
 
-	screenshotArgs := page.NewCaptureScreenshotArgs().SetFormat("png").SetQuality(80)
-	screenshot, err := t.client.Page.CaptureScreenshot(ctx, screenshotArgs)
+	var reply *runtime.EvaluateReply
+	err := t.call(ctx, func(client *cdp.Client) (err error) {
+		reply, err = client.Runtime.Evaluate(ctx, evalArgs)
+		return err
+	})
 	if err != nil {
-		// error
-		return "", err
+		return err
 	}
 
-	image := fmt.Sprintf("data:image/png;base64,%v", base64.StdEncoding.EncodeToString(screenshot.Data))
+	if reply.ExceptionDetails != nil {
+		return &JavaScriptError{Details: reply.ExceptionDetails}
+	}
 
-	return image, nil
+	// undefined results have no value
+	if value == nil || len(reply.Result.Value) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(reply.Result.Value, value)
 }
 
-func (t *tab) Exec(javascript string, timeout time.Duration) (*runtime.EvaluateReply, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+// jsString quotes the string as a javascript string literal
+func jsString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
 
-	if t.conn == nil {
-		err := t.connect(timeout)
-		if err != nil {
-			return nil, err
-		}
+// JavaScriptError is returned when the javascript evaluated in a tab throws an exception
+type JavaScriptError struct {
+	Details *runtime.ExceptionDetails
+}
+
+func (e *JavaScriptError) Error() string {
+	text := e.Details.Text
+	if e.Details.Exception != nil && e.Details.Exception.Description != nil {
+		text = *e.Details.Exception.Description
 	}
 
-	evalArgs := runtime.NewEvaluateArgs(javascript).SetAwaitPromise(true).SetReturnByValue(true)
-	return t.client.Runtime.Evaluate(ctx, evalArgs)
+	return fmt.Sprintf("go-chrome-framework: javascript exception: %v", text)
 }
 
 func (t *tab) GetClient() *cdp.Client {
-	if t.client == nil {
-		err := t.connect(120 * time.Second)
-		if err != nil {
-			log.Println("unable to connect", err)
-			return nil
-		}
+	if err := t.ensureConnected(120 * time.Second); err != nil {
+		log.Println("unable to connect", err)
+		return nil
 	}
 
-	return t.client
+	client, err := t.currentClient()
+	if err != nil {
+		log.Println("unable to connect", err)
+		return nil
+	}
+
+	return client
 }
 
 func (t *tab) GetTargetID() target.ID {
@@ -245,5 +727,76 @@ func (t *tab) GetTargetID() target.ID {
 }
 
 func (t *tab) AttachHook(hook ClientHook) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	t.hooks = append(t.hooks, hook)
 }
+
+func (t *tab) SetDefaultTimeout(timeout time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.defaultTimeout = timeout
+}
+
+func (t *tab) SetDefaultNavigationTimeout(timeout time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.defaultNavigationTimeout = timeout
+}
+
+// resolveTimeout returns the timeout passed explicitly to a method, or the default timeout of the tab if it is zero
+func (t *tab) resolveTimeout(timeout time.Duration) time.Duration {
+	if timeout > 0 {
+		return timeout
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.defaultTimeout > 0 {
+		return t.defaultTimeout
+	}
+
+	return DefaultTimeout
+}
+
+// resolveNavigationTimeout returns the timeout passed explicitly to a navigation, or the default navigation timeout of
+// the tab if it is zero
+func (t *tab) resolveNavigationTimeout(timeout time.Duration) time.Duration {
+	if timeout > 0 {
+		return timeout
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.defaultNavigationTimeout > 0 {
+		return t.defaultNavigationTimeout
+	}
+
+	return DefaultNavigationTimeout
+}
+
+func (t *tab) GrantPermissions(origin string, timeout time.Duration, permissions ...browser.PermissionType) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	// permissions are granted to the browser context the tab belongs to
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.Browser.GrantPermissions(ctx, newGrantPermissionsArgs(t.browserContextID, origin, permissions))
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to grant permissions", err.Error())
+		return err
+	}
+
+	return nil
+}
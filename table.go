@@ -0,0 +1,199 @@
+package chrome
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotTable is returned when the element matched by the selector passed to ExtractTable is not a table
+var ErrNotTable = errors.New("go-chrome-framework: element is not a table")
+
+// tableScript returns the trimmed text of the cells of every row of the table, cells spanning multiple columns are
+// repeated for every column they span. It returns null when the element is not a table
+const tableScript = `(() => {
+	const table = el.tagName.toLowerCase() === 'table' ? el : null;
+	if (!table) {
+		return null;
+	}
+	return Array.from(table.rows).map(row => {
+		const cells = [];
+		for (const cell of row.cells) {
+			for (let i = 0; i < Math.max(cell.colSpan, 1); i++) {
+				cells.push(cell.innerText.trim());
+			}
+		}
+		return cells;
+	});
+})()`
+
+// ExtractTable returns the text of the cells of the table matched by the selector, row by row including the header
+// rows
+func (t *tab) ExtractTable(selector string, timeout time.Duration) ([][]string, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return nil, err
+	}
+
+	rows, err := t.extractTable(ctx, selector)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to extract table", err.Error())
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (t *tab) extractTable(ctx context.Context, selector string) ([][]string, error) {
+	var rows *[][]string
+	if err := t.evaluateElement(ctx, selector, tableScript, &rows); err != nil {
+		return nil, err
+	}
+
+	if rows == nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotTable, selector)
+	}
+
+	return *rows, nil
+}
+
+// ExtractTableTo extracts the table matched by the selector into out, which must be a pointer to a slice of structs.
+// Every row after the header row becomes an element of the slice, with each column assigned to the struct field tagged
+// `table:"<header>"`, or to the field of the same name as the header ignoring case when there is no tag. Fields tagged
+// `table:"-"` are skipped. String, bool, integer and float fields are supported
+func (t *tab) ExtractTableTo(selector string, out interface{}, opts TableOpts, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	rows, err := t.extractTable(ctx, selector)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to extract table", err.Error())
+		return err
+	}
+
+	if err = mapTable(rows, out, opts); err != nil {
+		log.Println("go-chrome-framework error: unable to map table", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// mapTable maps the rows following the header row onto a slice of structs
+func mapTable(rows [][]string, out interface{}, opts TableOpts) error {
+	slice := reflect.ValueOf(out)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice || slice.Elem().Type().Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("go-chrome-framework: expected pointer to slice of structs, got %T", out)
+	}
+	slice = slice.Elem()
+	elem := slice.Type().Elem()
+
+	if opts.HeaderRow >= len(rows) {
+		return fmt.Errorf("go-chrome-framework: header row %v out of range of %v rows", opts.HeaderRow, len(rows))
+	}
+
+	// the index of the field every column is assigned to, -1 for columns without a field
+	columns := make([]int, len(rows[opts.HeaderRow]))
+	for i, header := range rows[opts.HeaderRow] {
+		columns[i] = tableField(elem, header)
+		if columns[i] < 0 && opts.Strict {
+			return fmt.Errorf("go-chrome-framework: no field for column %q of %v", header, elem)
+		}
+	}
+
+	result := reflect.MakeSlice(slice.Type(), 0, len(rows)-opts.HeaderRow-1)
+	for _, row := range rows[opts.HeaderRow+1:] {
+		value := reflect.New(elem).Elem()
+		for i, cell := range row {
+			if i >= len(columns) || columns[i] < 0 {
+				continue
+			}
+
+			if err := setTableField(value.Field(columns[i]), cell); err != nil {
+				return fmt.Errorf("go-chrome-framework: column %q: %w", rows[opts.HeaderRow][i], err)
+			}
+		}
+		result = reflect.Append(result, value)
+	}
+	slice.Set(result)
+
+	return nil
+}
+
+// tableField returns the index of the exported struct field for the header, or -1 if there is none
+func tableField(elem reflect.Type, header string) int {
+	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		tag, tagged := field.Tag.Lookup("table")
+		if tag == "-" {
+			continue
+		}
+
+		if tagged && tag == header || !tagged && strings.EqualFold(field.Name, strings.ReplaceAll(header, " ", "")) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// setTableField converts the text of a cell to the type of the field
+func setTableField(field reflect.Value, cell string) error {
+	if field.Kind() == reflect.String {
+		field.SetString(cell)
+		return nil
+	}
+
+	// empty cells leave the field at its zero value
+	if cell == "" {
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.Bool:
+		value, err := strconv.ParseBool(cell)
+		if err != nil {
+			return err
+		}
+		field.SetBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(strings.ReplaceAll(cell, ",", ""), 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseUint(strings.ReplaceAll(cell, ",", ""), 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(strings.ReplaceAll(cell, ",", ""), field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(value)
+	default:
+		return fmt.Errorf("unsupported field type %v", field.Type())
+	}
+
+	return nil
+}
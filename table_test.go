@@ -0,0 +1,121 @@
+package chrome
+
+import (
+	"reflect"
+	"testing"
+)
+
+type tableRow struct {
+	Name      string
+	UnitPrice float64
+	Stock     int     `table:"In stock"`
+	Available bool    `table:"Available?"`
+	Weight    uint    `table:"-"`
+	Ratio     float32 `table:"Ratio"`
+	note      string
+}
+
+func TestMapTable(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]string
+		opts TableOpts
+		want []tableRow
+		err  bool
+	}{
+		{
+			name: "fields by name and tag",
+			rows: [][]string{
+				{"Name", "Unit Price", "In stock", "Available?", "Ratio"},
+				{"Pen", "1.50", "1,200", "true", "0.5"},
+				{"Ink", "12", "", "false", ""},
+			},
+			want: []tableRow{
+				{Name: "Pen", UnitPrice: 1.5, Stock: 1200, Available: true, Ratio: 0.5},
+				{Name: "Ink", UnitPrice: 12},
+			},
+		},
+		{
+			name: "header row",
+			rows: [][]string{{"Products"}, {"name", "In stock"}, {"Pen", "3"}},
+			opts: TableOpts{HeaderRow: 1},
+			want: []tableRow{{Name: "Pen", Stock: 3}},
+		},
+		{
+			name: "columns without a field",
+			rows: [][]string{{"Name", "Color", "Weight", "Note"}, {"Pen", "blue", "20", "refillable"}},
+			want: []tableRow{{Name: "Pen"}},
+		},
+		{
+			name: "rows longer than the header",
+			rows: [][]string{{"Name"}, {"Pen", "extra"}},
+			want: []tableRow{{Name: "Pen"}},
+		},
+		{
+			name: "no rows after the header",
+			rows: [][]string{{"Name"}},
+			want: []tableRow{},
+		},
+		{
+			name: "strict",
+			rows: [][]string{{"Name", "Color"}, {"Pen", "blue"}},
+			opts: TableOpts{Strict: true},
+			err:  true,
+		},
+		{
+			name: "header row out of range",
+			rows: [][]string{{"Name"}},
+			opts: TableOpts{HeaderRow: 1},
+			err:  true,
+		},
+		{
+			name: "invalid number",
+			rows: [][]string{{"In stock"}, {"many"}},
+			err:  true,
+		},
+		{
+			name: "invalid bool",
+			rows: [][]string{{"Available?"}, {"maybe"}},
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out []tableRow
+			err := mapTable(test.rows, &out, test.opts)
+			if test.err {
+				if err == nil {
+					t.Fatalf("mapTable() = %+v, want an error", out)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("mapTable() = %v", err)
+			}
+
+			if !reflect.DeepEqual(out, test.want) {
+				t.Errorf("mapTable() = %+v, want %+v", out, test.want)
+			}
+		})
+	}
+}
+
+func TestMapTableOut(t *testing.T) {
+	rows := [][]string{{"Name"}, {"Pen"}}
+
+	tests := []struct {
+		name string
+		out  interface{}
+	}{
+		{"slice", []tableRow{}},
+		{"pointer to struct", &tableRow{}},
+		{"pointer to slice of strings", &[]string{}},
+	}
+
+	for _, test := range tests {
+		if err := mapTable(rows, test.out, TableOpts{}); err == nil {
+			t.Errorf("%v: mapTable(%T) = nil, want an error", test.name, test.out)
+		}
+	}
+}
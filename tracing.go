@@ -0,0 +1,170 @@
+package chrome
+
+import (
+	"context"
+	"errors"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/tracing"
+	"io"
+	"log"
+	"time"
+)
+
+var (
+	// ErrTracingStarted is returned when tracing is started on a tab which is already being traced
+	ErrTracingStarted = errors.New("go-chrome-framework: tracing already started")
+	// ErrTracingNotStarted is returned when tracing is stopped on a tab which is not being traced
+	ErrTracingNotStarted = errors.New("go-chrome-framework: tracing not started")
+)
+
+type tracer struct {
+	// cancels the event streams once tracing is complete
+	cancel context.CancelFunc
+	// receives the result of writing the trace once tracing is complete
+	done chan error
+}
+
+func (t *tab) StartTracing(w io.Writer, categories []string, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if t.tracer != nil {
+		return ErrTracingStarted
+	}
+
+	// event streams live until tracing is stopped, hence they are not bound to the timeout
+	client, err := t.currentClient()
+	if err != nil {
+		return err
+	}
+
+	streamCtx, streamCancel := context.WithCancel(context.Background())
+
+	dataCollected, err := client.Tracing.DataCollected(streamCtx)
+	if err != nil {
+		streamCancel()
+		log.Println("go-chrome-framework error: unable to open tracing data collected client", err.Error())
+		return err
+	}
+
+	tracingComplete, err := client.Tracing.TracingComplete(streamCtx)
+	if err != nil {
+		streamCancel()
+		log.Println("go-chrome-framework error: unable to open tracing complete client", err.Error())
+		return err
+	}
+
+	// make sure all the collected data is delivered before tracing complete event
+	if err = cdp.Sync(dataCollected, tracingComplete); err != nil {
+		streamCancel()
+		log.Println("go-chrome-framework error: unable to synchronise tracing clients", err.Error())
+		return err
+	}
+
+	startArgs := tracing.NewStartArgs().
+		SetTransferMode("ReportEvents").
+		SetTraceConfig(tracing.TraceConfig{IncludedCategories: categories})
+	if err = client.Tracing.Start(ctx, startArgs); err != nil {
+		streamCancel()
+		log.Println("go-chrome-framework error: unable to start tracing", err.Error())
+		return err
+	}
+
+	t.tracer = &tracer{
+		cancel: streamCancel,
+		done:   make(chan error, 1),
+	}
+
+	go func(done chan<- error) {
+		defer closeRes(dataCollected)
+		defer closeRes(tracingComplete)
+
+		done <- writeTrace(w, dataCollected, tracingComplete)
+	}(t.tracer.done)
+
+	return nil
+}
+
+func (t *tab) StopTracing(timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	if t.tracer == nil {
+		return ErrTracingNotStarted
+	}
+
+	tracer := t.tracer
+	t.tracer = nil
+	defer tracer.cancel()
+
+	client, err := t.currentClient()
+	if err != nil {
+		return err
+	}
+
+	if err := client.Tracing.End(ctx); err != nil {
+		log.Println("go-chrome-framework error: unable to stop tracing", err.Error())
+		return err
+	}
+
+	// wait for the remaining trace events to be flushed
+	select {
+	case err := <-tracer.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// writeTrace writes the collected trace events to w in the JSON object format understood by chrome://tracing and
+// Perfetto until tracing is complete
+func writeTrace(w io.Writer, dataCollected tracing.DataCollectedClient, tracingComplete tracing.CompleteClient) error {
+	if _, err := io.WriteString(w, `{"traceEvents":[`); err != nil {
+		return err
+	}
+
+	first := true
+	for {
+		select {
+		case <-dataCollected.Ready():
+			reply, err := dataCollected.Recv()
+			if err != nil {
+				log.Println("go-chrome-framework error: unable to receive tracing data", err.Error())
+				return err
+			}
+
+			for _, event := range reply.Value {
+				if !first {
+					if _, err = io.WriteString(w, ","); err != nil {
+						return err
+					}
+				}
+				first = false
+
+				if _, err = w.Write(event); err != nil {
+					return err
+				}
+			}
+		case <-tracingComplete.Ready():
+			if _, err := tracingComplete.Recv(); err != nil {
+				log.Println("go-chrome-framework error: unable to receive tracing complete event", err.Error())
+				return err
+			}
+
+			_, err := io.WriteString(w, "]}")
+			return err
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package chrome
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// captureBeyondViewportVersion is the first major version of chrome supporting screenshots beyond the viewport
+const captureBeyondViewportVersion = 87
+
+var webKitVersion = regexp.MustCompile(`AppleWebKit/([\d.]+)`)
+
+// BrowserVersion describes the browser and the versions of its engines
+type BrowserVersion struct {
+	// Product is the name and version of the browser, e.g. HeadlessChrome/120.0.6099.109
+	Product         string
+	Revision        string
+	ProtocolVersion string
+	UserAgent       string
+	// V8 is the version of the javascript engine
+	V8 string
+	// WebKit is the version of the rendering engine reported in the user agent
+	WebKit string
+	// Major is the major version of the browser
+	Major int
+}
+
+// AtLeast reports whether the major version of the browser is at least major
+func (v *BrowserVersion) AtLeast(major int) bool {
+	return v.Major >= major
+}
+
+// Version returns the version of the browser. The version is fetched once and cached afterwards
+func (c *chrome) Version(ctx context.Context) (*BrowserVersion, error) {
+	if c.version != nil {
+		return c.version, nil
+	}
+
+	reply, err := c.client.Browser.GetVersion(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to get browser version", err.Error())
+		return nil, err
+	}
+
+	version := &BrowserVersion{
+		Product:         reply.Product,
+		Revision:        reply.Revision,
+		ProtocolVersion: reply.ProtocolVersion,
+		UserAgent:       reply.UserAgent,
+		V8:              reply.JsVersion,
+	}
+
+	if match := webKitVersion.FindStringSubmatch(reply.UserAgent); match != nil {
+		version.WebKit = match[1]
+	}
+
+	// product is of the form Chrome/120.0.6099.109
+	if i := strings.Index(reply.Product, "/"); i >= 0 {
+		major := strings.SplitN(reply.Product[i+1:], ".", 2)[0]
+		version.Major, _ = strconv.Atoi(major)
+	}
+
+	c.version = version
+
+	return version, nil
+}
+
+// supports reports whether the browser is at least of the given major version, assuming it is not if the version
+// cannot be determined
+func (c *chrome) supports(ctx context.Context, major int) bool {
+	version, err := c.Version(ctx)
+	if err != nil {
+		return false
+	}
+
+	return version.AtLeast(major)
+}
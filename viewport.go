@@ -0,0 +1,79 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/emulation"
+	"log"
+	"time"
+)
+
+// SetViewport overrides the size of the viewport, the device pixel ratio and whether the page is emulated as mobile.
+// The viewport remains in effect until ResetViewport is called, screenshots which resize the viewport restore it
+// afterwards
+func (t *tab) SetViewport(width, height int, deviceScaleFactor float64, mobile bool, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	viewport := emulation.NewSetDeviceMetricsOverrideArgs(width, height, deviceScaleFactor, mobile)
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.Emulation.SetDeviceMetricsOverride(ctx, viewport)
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to set viewport", err.Error())
+		return err
+	}
+
+	t.mu.Lock()
+	t.viewport = viewport
+	t.mu.Unlock()
+
+	return nil
+}
+
+// ResetViewport removes the viewport override set by SetViewport, restoring the viewport of the window
+func (t *tab) ResetViewport(timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.viewport = nil
+	t.mu.Unlock()
+
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.Emulation.ClearDeviceMetricsOverride(ctx)
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to reset viewport", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// restoreViewport re-applies the viewport set by SetViewport, or removes the override when none is set
+func (t *tab) restoreViewport(ctx context.Context) error {
+	t.mu.Lock()
+	viewport := t.viewport
+	t.mu.Unlock()
+
+	if viewport == nil {
+		return t.call(ctx, func(client *cdp.Client) error {
+			return client.Emulation.ClearDeviceMetricsOverride(ctx)
+		})
+	}
+
+	return t.call(ctx, func(client *cdp.Client) error {
+		return client.Emulation.SetDeviceMetricsOverride(ctx, viewport)
+	})
+}
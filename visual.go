@@ -0,0 +1,27 @@
+package chrome
+
+import (
+	"bytes"
+	"go.ajitem.com/gcf/v3/visualdiff"
+	"image/png"
+	"log"
+	"time"
+)
+
+// CompareScreenshot captures the full page as a png and compares it with the baseline png at the path, see
+// visualdiff.CompareFile. The error wraps visualdiff.ErrMismatch when the screenshot differs from the baseline by more
+// than the options tolerate
+func (t *tab) CompareScreenshot(baselinePath string, opts visualdiff.Opts, timeout time.Duration) (*visualdiff.Result, error) {
+	var screenshot bytes.Buffer
+	if err := t.CaptureScreenshotTo(&screenshot, ScreenshotOpts{Format: "png"}, timeout); err != nil {
+		return nil, err
+	}
+
+	actual, err := png.Decode(&screenshot)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to decode screenshot", err.Error())
+		return nil, err
+	}
+
+	return visualdiff.CompareFile(baselinePath, actual, opts)
+}
@@ -0,0 +1,203 @@
+// Package visualdiff compares screenshots pixel by pixel for visual regression tests. Pixels are compared by their
+// perceived color difference in the YIQ color space, so that minor rendering noise can be tolerated with a threshold,
+// and the differing pixels are highlighted in a diff image
+package visualdiff
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// ErrMismatch is wrapped by the errors of comparisons whose difference exceeds the allowed difference
+var ErrMismatch = errors.New("go-chrome-framework: screenshot does not match baseline")
+
+// maxDelta is the largest possible YIQ difference of two colors
+const maxDelta = 35215.0
+
+type Opts struct {
+	// Threshold is the perceived color difference from 0 to 1 above which two pixels differ, defaults to 0.1
+	Threshold float64
+	// MaxDiffPixels is the number of differing pixels tolerated
+	MaxDiffPixels int
+	// MaxDiffRatio is the fraction of differing pixels tolerated, from 0 to 1
+	MaxDiffRatio float64
+	// DiffPath is where the diff image is written when the comparison fails, no diff image is written if empty
+	DiffPath string
+	// Update writes the screenshot as the new baseline instead of comparing, e.g. when tests run with an -update flag.
+	// Missing baselines are written as well
+	Update bool
+}
+
+// Result of a comparison
+type Result struct {
+	// DiffPixels is the number of differing pixels, pixels present in only one of the images differ
+	DiffPixels int
+	// TotalPixels is the number of pixels of the larger of the images
+	TotalPixels int
+	// DiffRatio is DiffPixels relative to TotalPixels
+	DiffRatio float64
+	// Match reports whether the difference is within the tolerated difference
+	Match bool
+	// Diff highlights the differing pixels in red on a faded copy of the baseline
+	Diff *image.RGBA
+	// Updated reports whether the baseline was written instead of compared
+	Updated bool
+}
+
+// Compare compares the image with the baseline
+func Compare(baseline, actual image.Image, opts Opts) *Result {
+	threshold := opts.Threshold
+	if threshold == 0 {
+		threshold = 0.1
+	}
+	maxPixelDelta := maxDelta * threshold * threshold
+
+	bounds := baseline.Bounds().Union(actual.Bounds())
+	diff := image.NewRGBA(bounds)
+
+	result := &Result{TotalPixels: bounds.Dx() * bounds.Dy(), Diff: diff}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			p := image.Pt(x, y)
+			if !p.In(baseline.Bounds()) || !p.In(actual.Bounds()) {
+				result.DiffPixels++
+				diff.Set(x, y, color.RGBA{R: 255, A: 255})
+				continue
+			}
+
+			expected := baseline.At(x, y)
+			if colorDelta(expected, actual.At(x, y)) > maxPixelDelta {
+				result.DiffPixels++
+				diff.Set(x, y, color.RGBA{R: 255, A: 255})
+				continue
+			}
+
+			// unchanged pixels are faded so that the differing pixels stand out
+			gray := 255 - (255-brightness(expected))/10
+			diff.Set(x, y, color.RGBA{R: gray, G: gray, B: gray, A: 255})
+		}
+	}
+
+	if result.TotalPixels > 0 {
+		result.DiffRatio = float64(result.DiffPixels) / float64(result.TotalPixels)
+	}
+	result.Match = result.DiffPixels <= opts.MaxDiffPixels || result.DiffRatio <= opts.MaxDiffRatio && opts.MaxDiffRatio > 0
+
+	return result
+}
+
+// CompareFile compares the image with the baseline png at the path, writing the diff image to opts.DiffPath when the
+// comparison fails. The error wraps ErrMismatch when the comparison fails. With opts.Update, or without a baseline at
+// the path, the image is written as the baseline instead
+func CompareFile(baselinePath string, actual image.Image, opts Opts) (*Result, error) {
+	if !opts.Update {
+		_, err := os.Stat(baselinePath)
+		opts.Update = os.IsNotExist(err)
+	}
+
+	if opts.Update {
+		if err := WritePNG(baselinePath, actual); err != nil {
+			return nil, err
+		}
+
+		return &Result{TotalPixels: actual.Bounds().Dx() * actual.Bounds().Dy(), Match: true, Updated: true}, nil
+	}
+
+	baseline, err := ReadPNG(baselinePath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := Compare(baseline, actual, opts)
+	if result.Match {
+		return result, nil
+	}
+
+	if opts.DiffPath != "" {
+		if err = WritePNG(opts.DiffPath, result.Diff); err != nil {
+			return result, err
+		}
+	}
+
+	return result, fmt.Errorf("%w: %v of %v pixels differ from %v", ErrMismatch, result.DiffPixels, result.TotalPixels, baselinePath)
+}
+
+// ReadPNG reads a png image
+func ReadPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return png.Decode(file)
+}
+
+// WritePNG writes the image as a png, creating the directories of the path
+func WritePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = png.Encode(file, img)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// colorDelta returns the perceived difference of two colors in the YIQ color space, blending translucent colors with
+// white
+func colorDelta(a, b color.Color) float64 {
+	ar, ag, ab := blend(a)
+	br, bg, bb := blend(b)
+
+	y := yiqY(ar, ag, ab) - yiqY(br, bg, bb)
+	i := yiqI(ar, ag, ab) - yiqI(br, bg, bb)
+	q := yiqQ(ar, ag, ab) - yiqQ(br, bg, bb)
+
+	return 0.5053*y*y + 0.299*i*i + 0.1957*q*q
+}
+
+// blend returns the 8-bit channels of the color blended with white
+func blend(c color.Color) (float64, float64, float64) {
+	r, g, b, a := c.RGBA()
+	alpha := float64(a) / 0xffff
+
+	channel := func(v uint32) float64 {
+		// RGBA returns alpha-premultiplied channels
+		return float64(v)/0xffff*255 + 255*(1-alpha)
+	}
+
+	return channel(r), channel(g), channel(b)
+}
+
+func yiqY(r, g, b float64) float64 {
+	return r*0.29889531 + g*0.58662247 + b*0.11448223
+}
+
+func yiqI(r, g, b float64) float64 {
+	return r*0.59597799 - g*0.27417610 - b*0.32180189
+}
+
+func yiqQ(r, g, b float64) float64 {
+	return r*0.21147017 - g*0.52261711 + b*0.31114694
+}
+
+// brightness returns the luma of the color blended with white
+func brightness(c color.Color) uint8 {
+	r, g, b := blend(c)
+
+	return uint8(yiqY(r, g, b) + 0.5)
+}
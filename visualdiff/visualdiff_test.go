@@ -0,0 +1,205 @@
+package visualdiff
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// filled returns an image of the size filled with the color, with the pixels of set changed to red
+func filled(width, height int, c color.Color, set ...image.Point) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	for _, p := range set {
+		img.Set(p.X, p.Y, color.RGBA{R: 255, A: 255})
+	}
+
+	return img
+}
+
+func TestCompare(t *testing.T) {
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	offWhite := color.RGBA{R: 250, G: 250, B: 250, A: 255}
+
+	tests := []struct {
+		name       string
+		baseline   image.Image
+		actual     image.Image
+		opts       Opts
+		diffPixels int
+		match      bool
+	}{
+		{
+			name:     "identical",
+			baseline: filled(4, 4, white),
+			actual:   filled(4, 4, white),
+			match:    true,
+		},
+		{
+			name:       "differing pixels",
+			baseline:   filled(4, 4, white),
+			actual:     filled(4, 4, white, image.Pt(0, 0), image.Pt(3, 3)),
+			diffPixels: 2,
+		},
+		{
+			name:     "difference below the threshold",
+			baseline: filled(4, 4, white),
+			actual:   filled(4, 4, offWhite),
+			match:    true,
+		},
+		{
+			name:       "difference above a lower threshold",
+			baseline:   filled(4, 4, white),
+			actual:     filled(4, 4, offWhite),
+			opts:       Opts{Threshold: 0.01},
+			diffPixels: 16,
+		},
+		{
+			name:       "tolerated pixels",
+			baseline:   filled(4, 4, white),
+			actual:     filled(4, 4, white, image.Pt(0, 0), image.Pt(3, 3)),
+			opts:       Opts{MaxDiffPixels: 2},
+			diffPixels: 2,
+			match:      true,
+		},
+		{
+			name:       "tolerated ratio",
+			baseline:   filled(4, 4, white),
+			actual:     filled(4, 4, white, image.Pt(0, 0), image.Pt(3, 3)),
+			opts:       Opts{MaxDiffRatio: 0.125},
+			diffPixels: 2,
+			match:      true,
+		},
+		{
+			name:       "ratio above the tolerated ratio",
+			baseline:   filled(4, 4, white),
+			actual:     filled(4, 4, white, image.Pt(0, 0), image.Pt(3, 3)),
+			opts:       Opts{MaxDiffRatio: 0.1},
+			diffPixels: 2,
+		},
+		{
+			name:       "different sizes",
+			baseline:   filled(4, 4, white),
+			actual:     filled(4, 2, white),
+			diffPixels: 8,
+		},
+		{
+			name:     "transparent pixels blended with white",
+			baseline: filled(4, 4, white),
+			actual:   filled(4, 4, color.RGBA{}),
+			match:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := Compare(test.baseline, test.actual, test.opts)
+			if result.DiffPixels != test.diffPixels {
+				t.Errorf("DiffPixels = %v, want %v", result.DiffPixels, test.diffPixels)
+			}
+			if result.Match != test.match {
+				t.Errorf("Match = %v, want %v", result.Match, test.match)
+			}
+			if want := float64(test.diffPixels) / 16; result.DiffRatio != want {
+				t.Errorf("DiffRatio = %v, want %v", result.DiffRatio, want)
+			}
+		})
+	}
+}
+
+func TestCompareDiff(t *testing.T) {
+	baseline := filled(2, 1, color.Black)
+	actual := filled(2, 1, color.Black, image.Pt(1, 0))
+
+	diff := Compare(baseline, actual, Opts{}).Diff
+
+	tests := []struct {
+		name  string
+		x     int
+		color color.RGBA
+	}{
+		// black is faded to 90% of the way to white
+		{"unchanged pixel", 0, color.RGBA{R: 230, G: 230, B: 230, A: 255}},
+		{"differing pixel", 1, color.RGBA{R: 255, A: 255}},
+	}
+
+	for _, test := range tests {
+		if c := diff.RGBAAt(test.x, 0); c != test.color {
+			t.Errorf("%v: Diff.At(%v, 0) = %v, want %v", test.name, test.x, c, test.color)
+		}
+	}
+}
+
+func TestColorDelta(t *testing.T) {
+	tests := []struct {
+		name string
+		a    color.Color
+		b    color.Color
+		// bounds of the delta
+		min float64
+		max float64
+	}{
+		{"same color", color.RGBA{R: 12, G: 34, B: 56, A: 255}, color.RGBA{R: 12, G: 34, B: 56, A: 255}, 0, 0},
+		{"transparent and white", color.Transparent, color.White, 0, 0},
+		{"black and white", color.Black, color.White, 0.9 * maxDelta, maxDelta},
+		{"white and black", color.White, color.Black, 0.9 * maxDelta, maxDelta},
+		{"red and blue", color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}, 0.1 * maxDelta, maxDelta},
+		{"white and half transparent black", color.White, color.RGBA{A: 128}, 0.2 * maxDelta, 0.3 * maxDelta},
+	}
+
+	for _, test := range tests {
+		if delta := colorDelta(test.a, test.b); delta < test.min-1e-6 || delta > test.max+1e-6 {
+			t.Errorf("%v: colorDelta() = %v, want between %v and %v", test.name, delta, test.min, test.max)
+		}
+	}
+}
+
+func TestCompareFile(t *testing.T) {
+	dir := t.TempDir()
+	baselinePath := filepath.Join(dir, "baseline", "page.png")
+	diffPath := filepath.Join(dir, "diff", "page.png")
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+	tests := []struct {
+		name     string
+		actual   image.Image
+		opts     Opts
+		updated  bool
+		mismatch bool
+	}{
+		{name: "missing baseline", actual: filled(4, 4, white), updated: true},
+		{name: "matching baseline", actual: filled(4, 4, white)},
+		{name: "mismatching baseline", actual: filled(4, 4, white, image.Pt(1, 1)), mismatch: true},
+		{name: "update", actual: filled(4, 4, white, image.Pt(1, 1)), opts: Opts{Update: true}, updated: true},
+		{name: "updated baseline", actual: filled(4, 4, white, image.Pt(1, 1))},
+	}
+
+	for _, test := range tests {
+		opts := test.opts
+		opts.DiffPath = diffPath
+
+		result, err := CompareFile(baselinePath, test.actual, opts)
+		if test.mismatch {
+			if !errors.Is(err, ErrMismatch) {
+				t.Fatalf("%v: CompareFile() = %v, want %v", test.name, err, ErrMismatch)
+			}
+			if _, err = os.Stat(diffPath); err != nil {
+				t.Errorf("%v: diff image not written: %v", test.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: CompareFile() = %v", test.name, err)
+		}
+		if result.Updated != test.updated {
+			t.Errorf("%v: Updated = %v, want %v", test.name, result.Updated, test.updated)
+		}
+	}
+}
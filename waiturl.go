@@ -0,0 +1,107 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp/protocol/page"
+	"log"
+	"regexp"
+	"time"
+)
+
+// frameURL returns the url of the frame including its fragment
+func frameURL(frame page.Frame) string {
+	if frame.URLFragment != nil {
+		return frame.URL + *frame.URLFragment
+	}
+
+	return frame.URL
+}
+
+// WaitForURL waits until the main frame has navigated to a url matching the pattern and returns the url, e.g. once an
+// oauth flow redirected back to the application. In the pattern * matches zero or more characters and ? a single
+// character, as in Route. Returns immediately if the current url matches. Redirects are followed before the frame
+// navigates, hence only the urls the frame lands on are matched
+func (t *tab) WaitForURL(pattern string, timeout time.Duration) (string, error) {
+	return t.WaitForURLMatch(compileURLPattern(pattern), timeout)
+}
+
+// WaitForURLMatch waits until the main frame has navigated to a url matching the regular expression and returns the url,
+// see WaitForURL
+func (t *tab) WaitForURLMatch(re *regexp.Regexp, timeout time.Duration) (string, error) {
+	timeout = t.resolveNavigationTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return "", err
+	}
+
+	client, err := t.currentClient()
+	if err != nil {
+		return "", err
+	}
+
+	frameNavigated, err := client.Page.FrameNavigated(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to open frame navigated client", err.Error())
+		return "", err
+	}
+	defer closeRes(frameNavigated)
+
+	// navigations within the document, e.g. to fragments or by the history api, do not navigate the frame
+	navigatedWithinDocument, err := client.Page.NavigatedWithinDocument(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to open navigated within document client", err.Error())
+		return "", err
+	}
+	defer closeRes(navigatedWithinDocument)
+
+	if err = client.Page.Enable(ctx); err != nil {
+		log.Println("go-chrome-framework error: unable to enable page domain", err.Error())
+		return "", err
+	}
+
+	tree, err := client.Page.GetFrameTree(ctx)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to get frame tree", err.Error())
+		return "", err
+	}
+
+	if url := frameURL(tree.FrameTree.Frame); re.MatchString(url) {
+		return url, nil
+	}
+
+	for {
+		var url string
+
+		select {
+		case <-ctx.Done():
+			log.Println("go-chrome-framework error: timed out waiting for url", ctx.Err().Error())
+			return "", ctx.Err()
+		case <-frameNavigated.Ready():
+			ev, err := frameNavigated.Recv()
+			if err != nil {
+				log.Println("go-chrome-framework error: unable to get frame navigated event", err.Error())
+				return "", err
+			}
+			if ev.Frame.ParentID != nil {
+				continue
+			}
+			url = frameURL(ev.Frame)
+		case <-navigatedWithinDocument.Ready():
+			ev, err := navigatedWithinDocument.Recv()
+			if err != nil {
+				log.Println("go-chrome-framework error: unable to get navigated within document event", err.Error())
+				return "", err
+			}
+			if ev.FrameID != tree.FrameTree.Frame.ID {
+				continue
+			}
+			url = ev.URL
+		}
+
+		if re.MatchString(url) {
+			return url, nil
+		}
+	}
+}
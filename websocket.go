@@ -0,0 +1,165 @@
+package chrome
+
+import (
+	"github.com/mafredri/cdp/protocol/network"
+	"log"
+	"sync"
+	"time"
+)
+
+// WebSocketFrame is a message sent or received over a websocket of the page
+type WebSocketFrame struct {
+	// RequestID identifies the websocket
+	RequestID network.RequestID
+	// URL the websocket is connected to
+	URL string
+	// Sent is set for frames sent by the page and unset for frames it received
+	Sent bool
+	// Opcode of the frame, 1 for text and 2 for binary frames
+	Opcode int
+	// PayloadData holds the text of text frames and the base64 encoded data of binary frames
+	PayloadData string
+	// Time the frame was reported at
+	Time time.Time
+}
+
+// WebSocketFrameHandler is invoked for every websocket frame of a tab
+type WebSocketFrameHandler func(WebSocketFrame)
+
+// webSockets dispatches the websocket frames of a tab to the handlers
+type webSockets struct {
+	// guards handlers and urls
+	mu       sync.Mutex
+	handlers []WebSocketFrameHandler
+	// urls of the open websockets by their request id
+	urls map[network.RequestID]string
+}
+
+// OnWebSocketFrame invokes the handler for every frame sent or received over the websockets of the tab. Handlers are
+// invoked one at a time in the order the frames are sent and received
+func (t *tab) OnWebSocketFrame(handler WebSocketFrameHandler, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+
+	t.featureMu.Lock()
+	defer t.featureMu.Unlock()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	if t.webSockets != nil {
+		t.webSockets.mu.Lock()
+		t.webSockets.handlers = append(t.webSockets.handlers, handler)
+		t.webSockets.mu.Unlock()
+
+		return nil
+	}
+
+	sockets := &webSockets{
+		handlers: []WebSocketFrameHandler{handler},
+		urls:     make(map[network.RequestID]string),
+	}
+	if err := t.openWebSockets(sockets); err != nil {
+		return err
+	}
+	t.webSockets = sockets
+
+	return nil
+}
+
+// openWebSockets opens the websocket streams of the current connection and dispatches the frames to the handlers,
+// see restoreFeatures for re-opening them after a reconnect
+func (t *tab) openWebSockets(sockets *webSockets) error {
+	// frames are reported for the lifetime of the tab, hence the streams are not bound to the timeout of a call
+	client, err := t.currentClient()
+	if err != nil {
+		return err
+	}
+
+	created, err := client.Network.WebSocketCreated(t.lifetime())
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to open web socket created client", err.Error())
+		return err
+	}
+
+	sent, err := client.Network.WebSocketFrameSent(t.lifetime())
+	if err != nil {
+		closeRes(created)
+		log.Println("go-chrome-framework error: unable to open web socket frame sent client", err.Error())
+		return err
+	}
+
+	received, err := client.Network.WebSocketFrameReceived(t.lifetime())
+	if err != nil {
+		closeRes(created)
+		closeRes(sent)
+		log.Println("go-chrome-framework error: unable to open web socket frame received client", err.Error())
+		return err
+	}
+
+	go func() {
+		defer closeRes(created)
+		defer closeRes(sent)
+		defer closeRes(received)
+
+		defer t.streamsEnded(func() {
+			if t.webSockets == sockets {
+				t.webSockets = nil
+			}
+		})
+
+		for {
+			var frame WebSocketFrame
+
+			// the streams are read in the order the events arrived over the connection
+			select {
+			case <-created.Ready():
+				ev, err := created.Recv()
+				if err != nil {
+					return
+				}
+
+				sockets.mu.Lock()
+				sockets.urls[ev.RequestID] = ev.URL
+				sockets.mu.Unlock()
+
+				continue
+			case <-sent.Ready():
+				ev, err := sent.Recv()
+				if err != nil {
+					return
+				}
+
+				frame = newWebSocketFrame(ev.RequestID, ev.Response, true)
+			case <-received.Ready():
+				ev, err := received.Recv()
+				if err != nil {
+					return
+				}
+
+				frame = newWebSocketFrame(ev.RequestID, ev.Response, false)
+			}
+
+			sockets.mu.Lock()
+			frame.URL = sockets.urls[frame.RequestID]
+			handlers := append([]WebSocketFrameHandler{}, sockets.handlers...)
+			sockets.mu.Unlock()
+
+			for _, handler := range handlers {
+				handler(frame)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func newWebSocketFrame(requestID network.RequestID, frame network.WebSocketFrame, sent bool) WebSocketFrame {
+	return WebSocketFrame{
+		RequestID:   requestID,
+		Sent:        sent,
+		Opcode:      int(frame.Opcode),
+		PayloadData: frame.PayloadData,
+		Time:        time.Now(),
+	}
+}
@@ -0,0 +1,111 @@
+package chrome
+
+import (
+	"context"
+	"github.com/mafredri/cdp"
+	"github.com/mafredri/cdp/protocol/browser"
+	"log"
+	"time"
+)
+
+// WindowBounds is the position and size of a browser window in screen pixels
+type WindowBounds struct {
+	Left   int
+	Top    int
+	Width  int
+	Height int
+}
+
+// BringToFront activates the tab, making it the visible tab of its window
+func (t *tab) BringToFront(timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	err := t.call(ctx, func(client *cdp.Client) error {
+		return client.Page.BringToFront(ctx)
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to bring tab to front", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// SetWindowBounds moves and resizes the window of the tab, restoring it first if it is minimized, maximized or in
+// fullscreen
+func (t *tab) SetWindowBounds(bounds WindowBounds, timeout time.Duration) error {
+	return t.setWindowBounds(browser.Bounds{
+		Left:   Int(bounds.Left),
+		Top:    Int(bounds.Top),
+		Width:  Int(bounds.Width),
+		Height: Int(bounds.Height),
+	}, timeout)
+}
+
+// Minimize minimizes the window of the tab
+func (t *tab) Minimize(timeout time.Duration) error {
+	return t.setWindowBounds(browser.Bounds{WindowState: browser.WindowStateMinimized}, timeout)
+}
+
+// Maximize maximizes the window of the tab
+func (t *tab) Maximize(timeout time.Duration) error {
+	return t.setWindowBounds(browser.Bounds{WindowState: browser.WindowStateMaximized}, timeout)
+}
+
+// Fullscreen switches the window of the tab to fullscreen
+func (t *tab) Fullscreen(timeout time.Duration) error {
+	return t.setWindowBounds(browser.Bounds{WindowState: browser.WindowStateFullscreen}, timeout)
+}
+
+// RestoreWindow restores the window of the tab from being minimized, maximized or in fullscreen
+func (t *tab) RestoreWindow(timeout time.Duration) error {
+	return t.setWindowBounds(browser.Bounds{WindowState: browser.WindowStateNormal}, timeout)
+}
+
+func (t *tab) setWindowBounds(bounds browser.Bounds, timeout time.Duration) error {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return err
+	}
+
+	var window *browser.GetWindowForTargetReply
+	err := t.call(ctx, func(client *cdp.Client) (err error) {
+		window, err = client.Browser.GetWindowForTarget(ctx, browser.NewGetWindowForTargetArgs().SetTargetID(t.id))
+		return err
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to get window of tab", err.Error())
+		return err
+	}
+
+	// the position and size can only be changed while the window is in the normal state
+	if bounds.WindowState == "" {
+		restore := browser.NewSetWindowBoundsArgs(window.WindowID, browser.Bounds{WindowState: browser.WindowStateNormal})
+		err = t.call(ctx, func(client *cdp.Client) error {
+			return client.Browser.SetWindowBounds(ctx, restore)
+		})
+		if err != nil {
+			log.Println("go-chrome-framework error: unable to restore window", err.Error())
+			return err
+		}
+	}
+
+	err = t.call(ctx, func(client *cdp.Client) error {
+		return client.Browser.SetWindowBounds(ctx, browser.NewSetWindowBoundsArgs(window.WindowID, bounds))
+	})
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to set window bounds", err.Error())
+		return err
+	}
+
+	return nil
+}
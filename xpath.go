@@ -0,0 +1,135 @@
+package chrome
+
+import (
+	"context"
+	"fmt"
+	"github.com/mafredri/cdp/protocol/dom"
+	"github.com/mafredri/cdp/protocol/runtime"
+	"log"
+	"strconv"
+	"time"
+)
+
+// xpathScript returns the nodes matching the xpath expression in document order
+const xpathScript = `((expression) => {
+	const result = document.evaluate(expression, document, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+	const nodes = [];
+	for (let i = 0; i < result.snapshotLength; i++) {
+		nodes.push(result.snapshotItem(i));
+	}
+	return nodes;
+})(%v)`
+
+// xpathCountScript returns the number of nodes matching the xpath expression
+const xpathCountScript = `document.evaluate(%v, document, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null).snapshotLength`
+
+// xpathPollInterval is how often WaitForXPath evaluates the expression
+const xpathPollInterval = 100 * time.Millisecond
+
+// QueryXPath returns the ids of the nodes matching the xpath expression in document order, the ids can be used with the
+// DOM domain of GetClient. An empty slice is returned when no node matches
+func (t *tab) QueryXPath(expression string, timeout time.Duration) ([]dom.NodeID, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return nil, err
+	}
+
+	nodes, err := t.queryXPath(ctx, expression)
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to query xpath", err.Error())
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+// queryXPath evaluates the xpath expression in the page and resolves the matching nodes into node ids
+func (t *tab) queryXPath(ctx context.Context, expression string) ([]dom.NodeID, error) {
+	client, err := t.currentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// nodes can only be requested once the document has been requested
+	if _, err := client.DOM.GetDocument(ctx, nil); err != nil {
+		return nil, err
+	}
+
+	reply, err := client.Runtime.Evaluate(ctx, runtime.NewEvaluateArgs(fmt.Sprintf(xpathScript, jsString(expression))))
+	if err != nil {
+		return nil, err
+	}
+
+	if reply.ExceptionDetails != nil {
+		return nil, &JavaScriptError{Details: reply.ExceptionDetails}
+	}
+
+	if reply.Result.ObjectID == nil {
+		return nil, nil
+	}
+	defer client.Runtime.ReleaseObject(ctx, runtime.NewReleaseObjectArgs(*reply.Result.ObjectID))
+
+	properties, err := client.Runtime.GetProperties(ctx, runtime.NewGetPropertiesArgs(*reply.Result.ObjectID).SetOwnProperties(true))
+	if err != nil {
+		return nil, err
+	}
+
+	// the array indices are the only properties referring to nodes
+	nodes := make([]dom.NodeID, 0, len(properties.Result))
+	for _, property := range properties.Result {
+		if _, err := strconv.Atoi(property.Name); err != nil || property.Value == nil || property.Value.ObjectID == nil {
+			continue
+		}
+
+		node, err := client.DOM.RequestNode(ctx, dom.NewRequestNodeArgs(*property.Value.ObjectID))
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, node.NodeID)
+	}
+
+	return nodes, nil
+}
+
+// WaitForXPath waits until at least one node matches the xpath expression and returns the ids of the matching nodes
+func (t *tab) WaitForXPath(expression string, timeout time.Duration) ([]dom.NodeID, error) {
+	timeout = t.resolveTimeout(timeout)
+	ctx, cancel := context.WithTimeout(t.lifetime(), timeout)
+	defer cancel()
+
+	if err := t.ensureConnected(timeout); err != nil {
+		return nil, err
+	}
+
+	for {
+		var count int
+		if err := t.evaluate(ctx, fmt.Sprintf(xpathCountScript, jsString(expression)), &count); err != nil {
+			log.Println("go-chrome-framework error: unable to evaluate xpath", err.Error())
+			return nil, err
+		}
+
+		if count > 0 {
+			nodes, err := t.queryXPath(ctx, expression)
+			if err != nil {
+				log.Println("go-chrome-framework error: unable to query xpath", err.Error())
+				return nil, err
+			}
+
+			// the nodes may have been removed in between evaluating and querying
+			if len(nodes) > 0 {
+				return nodes, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("go-chrome-framework error: timed out waiting for xpath", ctx.Err().Error())
+			return nil, ctx.Err()
+		case <-time.After(xpathPollInterval):
+		}
+	}
+}
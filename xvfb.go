@@ -0,0 +1,103 @@
+package chrome
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// xvfbStartTimeout is how long Xvfb may take to report its display
+const xvfbStartTimeout = 10 * time.Second
+
+// xvfb is a virtual X display headful chrome is run on when the host has no display
+type xvfb struct {
+	command *exec.Cmd
+	display string
+}
+
+// startXvfb starts Xvfb on a display number it picks, which it reports on the pipe passed as -displayfd
+func startXvfb() (*xvfb, error) {
+	path, err := exec.LookPath("Xvfb")
+	if err != nil {
+		return nil, err
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer closeRes(r)
+
+	command := exec.Command(path, "-displayfd", "3", "-screen", "0", "1920x1080x24", "-nolisten", "tcp")
+	command.ExtraFiles = []*os.File{w}
+
+	err = command.Start()
+	closeRes(w)
+	if err != nil {
+		return nil, err
+	}
+
+	displays := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(r).ReadString('\n')
+		displays <- strings.TrimSpace(line)
+	}()
+
+	select {
+	case display := <-displays:
+		if display != "" {
+			return &xvfb{command: command, display: ":" + display}, nil
+		}
+	case <-time.After(xvfbStartTimeout):
+	}
+
+	_ = command.Process.Kill()
+	_ = command.Wait()
+
+	return nil, fmt.Errorf("go-chrome-framework: Xvfb did not report its display")
+}
+
+func (x *xvfb) stop() {
+	if err := x.command.Process.Kill(); err != nil {
+		log.Println("go-chrome-framework error: unable to stop Xvfb", err.Error())
+	}
+	_ = x.command.Wait()
+}
+
+// startVirtualDisplay starts Xvfb for headful chrome on a linux host without a display when enabled with
+// LaunchOpts.SetVirtualDisplay, returning the environment and arguments to run chrome with. Without Xvfb chrome renders
+// with the headless ozone platform instead
+func (c *chrome) startVirtualDisplay(opts *LaunchOpts, arguments []string) (map[string]string, []string) {
+	if !opts.virtualDisplay || opts.headless || runtime.GOOS != "linux" ||
+		os.Getenv("DISPLAY") != "" || opts.env["DISPLAY"] != "" {
+		return opts.env, arguments
+	}
+
+	display, err := startXvfb()
+	if err != nil {
+		log.Println("go-chrome-framework error: unable to start Xvfb, falling back to the headless ozone platform", err.Error())
+		return opts.env, append(arguments, "--ozone-platform=headless")
+	}
+	c.xvfb = display
+
+	env := make(map[string]string, len(opts.env)+1)
+	for name, value := range opts.env {
+		env[name] = value
+	}
+	env["DISPLAY"] = display.display
+
+	return env, arguments
+}
+
+// stopVirtualDisplay stops the Xvfb started for the browser, if any
+func (c *chrome) stopVirtualDisplay() {
+	if c.xvfb != nil {
+		c.xvfb.stop()
+		c.xvfb = nil
+	}
+}